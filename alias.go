@@ -10,9 +10,11 @@ import (
 	"github.com/go-hardware/ghw/pkg/baseboard"
 	"github.com/go-hardware/ghw/pkg/bios"
 	"github.com/go-hardware/ghw/pkg/block"
+	"github.com/go-hardware/ghw/pkg/cgroup"
 	"github.com/go-hardware/ghw/pkg/chassis"
 	ghwcontext "github.com/go-hardware/ghw/pkg/context"
 	"github.com/go-hardware/ghw/pkg/cpu"
+	"github.com/go-hardware/ghw/pkg/cpuset"
 	"github.com/go-hardware/ghw/pkg/gpu"
 	"github.com/go-hardware/ghw/pkg/memory"
 	"github.com/go-hardware/ghw/pkg/net"
@@ -29,13 +31,107 @@ var (
 	WithRootMountpoint       = ghwcontext.WithRootMountpoint
 	WithDisableWarnings      = ghwcontext.WithDisableWarnings
 	WithDisableExternalTools = ghwcontext.WithDisableExternalTools
+	WithExcludeVirtualDisks  = ghwcontext.WithExcludeVirtualDisks
+	WithDiskHealth           = ghwcontext.WithDiskHealth
+	WithCryptHeaders         = ghwcontext.WithCryptHeaders
+	WithCgroup               = ghwcontext.WithCgroup
+	WithCgroupPath           = ghwcontext.WithCgroupPath
 	WithOptions              = ghwcontext.WithOptions
 )
 
 type CPUInfo = cpu.Info
+type CPULogicalProcessor = cpu.LogicalProcessor
+type CPUFrequency = cpu.Frequency
+type CPUFrequencySummary = cpu.FrequencySummary
+type CPUCoreType = cpu.CoreType
+type CPUSet = cpuset.CPUSet
+type CPUCgroupLimits = cgroup.CPU
+type CPUIdle = cpu.Idle
+type CPUIdleState = cpu.IdleState
+type CPUPStateDriver = cpu.PStateDriver
+
+type CPUX86Identification = cpu.X86Identification
+type CPUARMIdentification = cpu.ARMIdentification
+type CPUVulnerability = cpu.Vulnerability
+type CPUVulnerabilityStatus = cpu.VulnerabilityStatus
+type CPUTemperature = cpu.Temperature
+type CPUThermal = cpu.Thermal
+type CPURAPLDomain = cpu.RAPLDomain
+type CPUPowerSample = cpu.PowerSample
+type CPUFeature = cpu.Feature
+
+const (
+	CPUCoreTypeUnknown     = cpu.CoreTypeUnknown
+	CPUCoreTypePerformance = cpu.CoreTypePerformance
+	CPUCoreTypeEfficiency  = cpu.CoreTypeEfficiency
+
+	CPUVulnerabilityStatusUnknown     = cpu.VulnerabilityStatusUnknown
+	CPUVulnerabilityStatusNotAffected = cpu.VulnerabilityStatusNotAffected
+	CPUVulnerabilityStatusMitigated   = cpu.VulnerabilityStatusMitigated
+	CPUVulnerabilityStatusVulnerable  = cpu.VulnerabilityStatusVulnerable
+
+	CPUFeatureUnknown         = cpu.FeatureUnknown
+	CPUFeatureAES             = cpu.FeatureAES
+	CPUFeatureCLMUL           = cpu.FeatureCLMUL
+	CPUFeatureSHA1            = cpu.FeatureSHA1
+	CPUFeatureSHA256          = cpu.FeatureSHA256
+	CPUFeatureSHA512          = cpu.FeatureSHA512
+	CPUFeatureSHA3            = cpu.FeatureSHA3
+	CPUFeatureCRC32           = cpu.FeatureCRC32
+	CPUFeatureRNG             = cpu.FeatureRNG
+	CPUFeatureBF16            = cpu.FeatureBF16
+	CPUFeatureFP16            = cpu.FeatureFP16
+	CPUFeatureDotProd         = cpu.FeatureDotProd
+	CPUFeatureI8MM            = cpu.FeatureI8MM
+	CPUFeatureAtomics         = cpu.FeatureAtomics
+	CPUFeatureSSE             = cpu.FeatureSSE
+	CPUFeatureSSE2            = cpu.FeatureSSE2
+	CPUFeatureSSE3            = cpu.FeatureSSE3
+	CPUFeatureSSSE3           = cpu.FeatureSSSE3
+	CPUFeatureSSE41           = cpu.FeatureSSE41
+	CPUFeatureSSE42           = cpu.FeatureSSE42
+	CPUFeaturePOPCNT          = cpu.FeaturePOPCNT
+	CPUFeatureCX16            = cpu.FeatureCX16
+	CPUFeatureLAHFSAHF        = cpu.FeatureLAHFSAHF
+	CPUFeatureLZCNT           = cpu.FeatureLZCNT
+	CPUFeatureMOVBE           = cpu.FeatureMOVBE
+	CPUFeatureXSAVE           = cpu.FeatureXSAVE
+	CPUFeatureAVX             = cpu.FeatureAVX
+	CPUFeatureAVX2            = cpu.FeatureAVX2
+	CPUFeatureFMA             = cpu.FeatureFMA
+	CPUFeatureF16C            = cpu.FeatureF16C
+	CPUFeatureBMI1            = cpu.FeatureBMI1
+	CPUFeatureBMI2            = cpu.FeatureBMI2
+	CPUFeatureVAES            = cpu.FeatureVAES
+	CPUFeatureVPCLMULQDQ      = cpu.FeatureVPCLMULQDQ
+	CPUFeatureGFNI            = cpu.FeatureGFNI
+	CPUFeatureAVXVNNI         = cpu.FeatureAVXVNNI
+	CPUFeatureAVX512F         = cpu.FeatureAVX512F
+	CPUFeatureAVX512CD        = cpu.FeatureAVX512CD
+	CPUFeatureAVX512BW        = cpu.FeatureAVX512BW
+	CPUFeatureAVX512DQ        = cpu.FeatureAVX512DQ
+	CPUFeatureAVX512VL        = cpu.FeatureAVX512VL
+	CPUFeatureAVX512IFMA      = cpu.FeatureAVX512IFMA
+	CPUFeatureAVX512VBMI      = cpu.FeatureAVX512VBMI
+	CPUFeatureAVX512VBMI2     = cpu.FeatureAVX512VBMI2
+	CPUFeatureAVX512VNNI      = cpu.FeatureAVX512VNNI
+	CPUFeatureAVX512BITALG    = cpu.FeatureAVX512BITALG
+	CPUFeatureAVX512VPOPCNTDQ = cpu.FeatureAVX512VPOPCNTDQ
+	CPUFeatureAVX512BF16      = cpu.FeatureAVX512BF16
+	CPUFeatureAVX512FP16      = cpu.FeatureAVX512FP16
+	CPUFeatureAMXTile         = cpu.FeatureAMXTile
+	CPUFeatureAMXInt8         = cpu.FeatureAMXInt8
+	CPUFeatureAMXBF16         = cpu.FeatureAMXBF16
+	CPUFeatureASIMD           = cpu.FeatureASIMD
+	CPUFeatureSVE             = cpu.FeatureSVE
+	CPUFeatureSVE2            = cpu.FeatureSVE2
+)
 
 var (
-	CPU = cpu.New
+	CPU            = cpu.New
+	NewCPUSet      = cpuset.New
+	ParseCPUSet    = cpuset.Parse
+	SampleCPUPower = cpu.SamplePower
 )
 
 type MemoryArea = memory.Area
@@ -43,6 +139,7 @@ type MemoryInfo = memory.Info
 type MemoryCache = memory.Cache
 type MemoryCacheType = memory.CacheType
 type MemoryModule = memory.Module
+type MemoryCgroupLimits = cgroup.Memory
 
 const (
 	MemoryCacheTypeUnified     = memory.CacheTypeUnified
@@ -70,6 +167,7 @@ const (
 	DriveTypeFDD     = block.DriveTypeFDD
 	DriveTypeODD     = block.DriveTypeODD
 	DriveTypeSSD     = block.DriveTypeSSD
+	DriveTypeVirtual = block.DriveTypeVirtual
 )
 
 type StorageController = block.StorageController
@@ -81,14 +179,65 @@ const (
 	StorageControllerNVMe    = block.StorageControllerNVMe
 	StorageControllerVirtIO  = block.StorageControllerVirtIO
 	StorageControllerMMC     = block.StorageControllerMMC
+	StorageControllerLoop    = block.StorageControllerLoop
+	StorageControllerSATA    = block.StorageControllerSATA
+	StorageControllerSAS     = block.StorageControllerSAS
+	StorageControllerUSB     = block.StorageControllerUSB
+	StorageControllerFC      = block.StorageControllerFC
+	StorageControllerISCSI   = block.StorageControllerISCSI
+	StorageControllerNVMeOF  = block.StorageControllerNVMeOF
+	StorageControllerXen     = block.StorageControllerXen
 )
 
 type NetworkInfo = net.Info
 type NIC = net.NIC
 type NICCapability = net.NICCapability
+type NICIPAddress = net.IPAddress
+type NICAddressAssignType = net.AddressAssignType
+type NICSRIOVInfo = net.SRIOVInfo
+type NICSRIOVFunction = net.SRIOVFunction
+type NICInterfaceType = net.InterfaceType
+type NICBondInfo = net.BondInfo
+type NICBridgeInfo = net.BridgeInfo
+type NICVLANInfo = net.VLANInfo
+type NICStatistics = net.Statistics
+type NICRates = net.Rates
+type NICChannels = net.Channels
+type NICIRQ = net.IRQ
+type NICWirelessInfo = net.WirelessInfo
+type NICWirelessBand = net.WirelessBand
+type NICWirelessFrequency = net.WirelessFrequency
+type RDMADevice = net.RDMADevice
+type RDMAPort = net.RDMAPort
+type RDMAGID = net.RDMAGID
+
+const (
+	NICAddressAssignTypeUnknown   = net.AddressAssignTypeUnknown
+	NICAddressAssignTypePermanent = net.AddressAssignTypePermanent
+	NICAddressAssignTypeRandom    = net.AddressAssignTypeRandom
+	NICAddressAssignTypeStolen    = net.AddressAssignTypeStolen
+	NICAddressAssignTypeSet       = net.AddressAssignTypeSet
+)
+
+const (
+	NICInterfaceTypeUnknown  = net.InterfaceTypeUnknown
+	NICInterfaceTypePhysical = net.InterfaceTypePhysical
+	NICInterfaceTypeBond     = net.InterfaceTypeBond
+	NICInterfaceTypeBridge   = net.InterfaceTypeBridge
+	NICInterfaceTypeVLAN     = net.InterfaceTypeVLAN
+	NICInterfaceTypeMACVLAN  = net.InterfaceTypeMACVLAN
+	NICInterfaceTypeVeth     = net.InterfaceTypeVeth
+	NICInterfaceTypeTun      = net.InterfaceTypeTun
+	NICInterfaceTypeTap      = net.InterfaceTypeTap
+	NICInterfaceTypeVXLAN    = net.InterfaceTypeVXLAN
+	NICInterfaceTypeTeam     = net.InterfaceTypeTeam
+	NICInterfaceTypeOVS      = net.InterfaceTypeOVS
+)
 
 var (
-	Network = net.New
+	Network           = net.New
+	ReadNICStatistics = net.ReadStatistics
+	SampleNICRates    = net.SampleRates
 )
 
 type BIOSInfo = bios.Info
@@ -126,6 +275,7 @@ const (
 type PCIInfo = pci.Info
 type PCIAddress = pciaddress.Address
 type PCIDevice = pci.Device
+type PCISRIOVInfo = pci.SRIOVInfo
 
 var (
 	PCI                  = pci.New
@@ -52,10 +52,31 @@ func showBlock(cmd *cobra.Command, args []string) error {
 
 		for _, disk := range block.Disks {
 			fmt.Printf(" %v\n", disk)
+			if disk.Health != nil {
+				fmt.Printf("  %v\n", disk.Health)
+			}
+			if disk.Loop != nil {
+				fmt.Printf("  %v\n", disk.Loop)
+			}
+			if disk.ZRAM != nil {
+				fmt.Printf("  %v\n", disk.ZRAM)
+			}
+			if disk.NetworkTarget != nil {
+				fmt.Printf("  %v\n", disk.NetworkTarget)
+			}
+			if disk.Encryption != nil {
+				fmt.Printf("  %v\n", disk.Encryption)
+			}
 			for _, part := range disk.Partitions {
 				fmt.Printf("  %v\n", part)
+				if part.Encryption != nil {
+					fmt.Printf("   %v\n", part.Encryption)
+				}
 			}
 		}
+		for _, swap := range block.Swaps {
+			fmt.Printf(" %v\n", swap)
+		}
 	case outputFormatJSON:
 		fmt.Printf("%s\n", block.JSONString(pretty))
 	case outputFormatYAML:
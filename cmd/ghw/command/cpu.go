@@ -51,12 +51,84 @@ func showCPU(cmd *cobra.Command, args []string) error {
 	switch outputFormat {
 	case outputFormatHuman:
 		fmt.Printf("%v\n", cpu)
+		if cpu.Online != nil {
+			fmt.Printf(" online=%s offline=%s possible=%s isolated=%s nohz_full=%s\n",
+				cpu.Online, cpu.Offline, cpu.Possible, cpu.Isolated, cpu.NohzFull)
+		}
+		if cg := cpu.Cgroup; cg != nil {
+			fmt.Printf(" cgroup v%d %s: cpus=%s quota=%dus period=%dus effective_cpus=%.2f\n",
+				cg.Version, cg.Path, cg.CPUs, cg.QuotaMicros, cg.PeriodMicros, cpu.EffectiveCPUs)
+		}
+		if idle := cpu.Idle; idle != nil {
+			fmt.Printf(" cpuidle: driver=%s governor=%s\n", idle.Driver, idle.Governor)
+		}
+		if pstate := cpu.PStateDriver; pstate != nil {
+			fmt.Printf(" %s: %s\n", pstate.Name, pstate.Status)
+		}
+		for _, vuln := range cpu.Vulnerabilities {
+			if vuln.Status == ghw.CPUVulnerabilityStatusVulnerable {
+				fmt.Printf(" vulnerable: %v\n", vuln)
+			}
+		}
 
 		for _, proc := range cpu.Processors {
 			fmt.Printf(" %v\n", proc)
+			if proc.X86 != nil {
+				fmt.Printf("  %s %v microcode=%s\n", proc.Model, proc.X86, proc.Microcode)
+			}
+			if proc.ARM != nil {
+				fmt.Printf("  %v\n", proc.ARM)
+			}
+			if proc.X86Level > 0 {
+				fmt.Printf("  microarchitecture level: x86-64-v%d\n", proc.X86Level)
+			}
+			if len(proc.Features) > 0 {
+				features := make([]string, 0, len(proc.Features))
+				for _, feature := range proc.Features {
+					features = append(features, feature.String())
+				}
+				fmt.Printf("  features: %s\n", strings.Join(features, " "))
+			}
 			for _, core := range proc.Cores {
 				fmt.Printf("  %v\n", core)
 			}
+			for _, cache := range proc.Caches {
+				fmt.Printf("  %v line=%dB ways=%d sets=%d\n",
+					cache, cache.LineSizeBytes, cache.WaysOfAssociativity, cache.NumberOfSets)
+			}
+			for _, lp := range proc.LogicalProcessors {
+				if len(lp.IdleStates) == 0 {
+					continue
+				}
+				states := make([]string, 0, len(lp.IdleStates))
+				for _, state := range lp.IdleStates {
+					if state.Disabled {
+						states = append(states, state.Name+"(disabled)")
+					} else {
+						states = append(states, state.Name)
+					}
+				}
+				fmt.Printf("  logical processor #%d idle states: %s\n", lp.ID, strings.Join(states, " "))
+			}
+			if freq := proc.Frequency; freq != nil {
+				fmt.Printf("  frequency: min=%dkHz base=%dkHz max=%dkHz boost=%t drivers=%s governors=%s\n",
+					freq.MinKHz, freq.BaseKHz, freq.MaxKHz, freq.BoostEnabled,
+					strings.Join(freq.Drivers, ","), strings.Join(freq.Governors, ","))
+			}
+			if th := proc.Thermal; th != nil {
+				sensors := make([]string, 0, len(th.Sensors))
+				for _, sensor := range th.Sensors {
+					sensors = append(sensors, sensor.String())
+				}
+				fmt.Printf("  thermal: throttle_count=%d sensors=[%s]\n", th.ThrottleCount, strings.Join(sensors, ", "))
+			}
+			for _, rapl := range proc.RAPL {
+				domains := []string{fmt.Sprintf("%s=%duJ", rapl.Name, rapl.EnergyMicrojoules)}
+				for _, sub := range rapl.Subdomains {
+					domains = append(domains, fmt.Sprintf("%s=%duJ", sub.Name, sub.EnergyMicrojoules))
+				}
+				fmt.Printf("  rapl: %s\n", strings.Join(domains, " "))
+			}
 			if len(proc.Capabilities) > 0 {
 				// pretty-print the (large) block of capability strings into rows
 				// of 6 capability strings
@@ -49,6 +49,10 @@ func showMemory(cmd *cobra.Command, args []string) error {
 	switch outputFormat {
 	case outputFormatHuman:
 		fmt.Printf("%v\n", mem)
+		if cg := mem.Cgroup; cg != nil {
+			fmt.Printf(" cgroup v%d %s: max=%d high=%d effective_usable=%d bytes\n",
+				cg.Version, cg.Path, cg.MaxBytes, cg.HighBytes, mem.EffectiveUsableBytes)
+		}
 	case outputFormatJSON:
 		fmt.Printf("%s\n", mem.JSONString(pretty))
 	case outputFormatYAML:
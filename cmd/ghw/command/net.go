@@ -10,6 +10,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/go-hardware/ghw"
 	"github.com/go-hardware/ghw/cmd/ghw/snapshot"
@@ -52,6 +53,53 @@ func showNetwork(cmd *cobra.Command, args []string) error {
 
 		for _, nic := range net.NICs {
 			fmt.Printf(" %v\n", nic)
+			if nic.OperState != "" {
+				fmt.Printf("  state=%s carrier=%t mtu=%d\n", nic.OperState, nic.HasCarrier, nic.MTU)
+			}
+			switch {
+			case nic.Bond != nil:
+				fmt.Printf("  bond: mode=%s slaves=%s\n", nic.Bond.Mode, strings.Join(nic.Bond.Slaves, ","))
+			case nic.Bridge != nil:
+				fmt.Printf("  bridge: ports=%s\n", strings.Join(nic.Bridge.Ports, ","))
+			case nic.VLAN != nil:
+				fmt.Printf("  vlan: id=%d parent=%s\n", nic.VLAN.ID, nic.VLAN.Parent)
+			}
+			if nic.Master != "" {
+				fmt.Printf("  master=%s\n", nic.Master)
+			}
+			if w := nic.Wireless; w != nil {
+				bands := make([]string, 0, len(w.Bands))
+				for _, band := range w.Bands {
+					bands = append(bands, band.Name)
+				}
+				fmt.Printf("  wireless: phy=%s bands=%s modes=%s regdom=%s\n", w.PhyName, strings.Join(bands, ","), strings.Join(w.InterfaceModes, ","), w.RegulatoryDomain)
+			}
+			if nic.RDMADevice != "" {
+				fmt.Printf("  rdma=%s\n", nic.RDMADevice)
+			}
+			if nic.SRIOV != nil {
+				if nic.SRIOV.IsVirtualFunction() {
+					fmt.Printf("  sriov: vf %d of %s\n", nic.SRIOV.VFIndex, nic.SRIOV.PhysicalFunction.PCIAddress)
+				} else {
+					fmt.Printf("  sriov: pf with %d/%d vfs\n", nic.SRIOV.NumVFs, nic.SRIOV.TotalVFs)
+				}
+			}
+			if stats := nic.Statistics; stats != nil {
+				fmt.Printf("  rx: bytes=%d packets=%d errors=%d dropped=%d\n", stats.RxBytes, stats.RxPackets, stats.RxErrors, stats.RxDropped)
+				fmt.Printf("  tx: bytes=%d packets=%d errors=%d dropped=%d\n", stats.TxBytes, stats.TxPackets, stats.TxErrors, stats.TxDropped)
+			}
+			if len(nic.IRQs) > 0 {
+				fmt.Printf("  queues: rx=%d tx=%d irqs:\n", nic.RxQueues, nic.TxQueues)
+				for _, irq := range nic.IRQs {
+					fmt.Printf("   - %d %s cpus=%v nodes=%v\n", irq.Number, irq.Name, irq.AffinityCPUs, irq.AffinityNodeIDs)
+				}
+			}
+			if len(nic.IPAddresses) > 0 {
+				fmt.Printf("  addresses:\n")
+				for _, addr := range nic.IPAddresses {
+					fmt.Printf("   - %v\n", addr)
+				}
+			}
 
 			enabledCaps := make([]int, 0)
 			for x, cap := range nic.Capabilities {
@@ -66,6 +114,12 @@ func showNetwork(cmd *cobra.Command, args []string) error {
 				}
 			}
 		}
+		for _, dev := range net.RDMADevices {
+			fmt.Printf(" %v\n", dev)
+			for _, port := range dev.Ports {
+				fmt.Printf("  port %d: %s %s %s rate=%q lid=%d netdev=%s\n", port.Number, port.LinkLayer, port.State, port.PhysicalState, port.Rate, port.LID, port.NetDevice)
+			}
+		}
 	case outputFormatJSON:
 		fmt.Printf("%s\n", net.JSONString(pretty))
 	case outputFormatYAML:
@@ -15,6 +15,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"syscall"
 )
 
 var (
@@ -24,10 +25,36 @@ var (
 	// they don't need to be discovered at runtime.
 	staticGlobs = []string{
 		"/proc/cpuinfo",
+		"/proc/interrupts",
+		"/proc/irq/*/effective_affinity_list",
+		"/proc/irq/*/smp_affinity_list",
 		"/proc/meminfo",
+		"/proc/net/vlan/config",
 		"/proc/self/mounts",
+		"/proc/swaps",
+		"/run/udev/data/b*",
+		"/sys/devices/cpu_atom/cpus",
+		"/sys/devices/cpu_core/cpus",
+		"/sys/devices/system/cpu/amd_pstate/*",
+		"/sys/devices/system/cpu/cpu*/acpi_cppc/nominal_freq",
 		"/sys/devices/system/cpu/cpu*/cache/index*/*",
+		"/sys/devices/system/cpu/cpu*/cpu_capacity",
+		"/sys/devices/system/cpu/cpu*/cpufreq",
+		"/sys/devices/system/cpu/cpu*/cpuidle/state*/*",
+		"/sys/devices/system/cpu/cpu*/online",
+		"/sys/devices/system/cpu/cpu*/thermal_throttle/*",
 		"/sys/devices/system/cpu/cpu*/topology/*",
+		"/sys/devices/system/cpu/cpufreq/boost",
+		"/sys/devices/system/cpu/cpufreq/policy*/*",
+		"/sys/devices/system/cpu/cpuidle/*",
+		"/sys/devices/system/cpu/intel_pstate/*",
+		"/sys/devices/system/cpu/isolated",
+		"/sys/devices/system/cpu/nohz_full",
+		"/sys/devices/system/cpu/offline",
+		"/sys/devices/system/cpu/online",
+		"/sys/devices/system/cpu/possible",
+		"/sys/devices/system/cpu/present",
+		"/sys/devices/system/cpu/vulnerabilities/*",
 		"/sys/devices/system/memory/block_size_bytes",
 		"/sys/devices/system/memory/memory*/online",
 		"/sys/devices/system/memory/memory*/state",
@@ -78,6 +105,12 @@ func New(
 	if err := snap.copyFileGlobs(gpuGlobs()); err != nil {
 		return err
 	}
+	if err := snap.copyFileGlobs(rdmaGlobs()); err != nil {
+		return err
+	}
+	if err := snap.copyFileGlobs(thermalGlobs()); err != nil {
+		return err
+	}
 	return snap.createSnapshot()
 }
 
@@ -232,7 +265,10 @@ func (s *snapshotter) copyFile(path string) error {
 			return err
 		}
 	} else {
-		if err := copyPseudoFile(path, destPath); err != nil && !errors.Is(err, os.ErrPermission) {
+		// some attributes cannot be read in the current state of the
+		// device, e.g. the carrier of a network interface which is down
+		err := copyPseudoFile(path, destPath)
+		if err != nil && !errors.Is(err, os.ErrPermission) && !errors.Is(err, syscall.EINVAL) {
 			return err
 		}
 	}
@@ -343,13 +379,114 @@ func netGlobs() []string {
 	// host-idenfifiable data.
 	ifaceEntries := []string{
 		"addr_assign_type",
+		"bonding/active_slave",
+		"bonding/lacp_rate",
+		"bonding/miimon",
+		"bonding/mode",
+		"bonding/slaves",
+		"bonding/xmit_hash_policy",
+		"bridge/stp_state",
+		"bridge/vlan_filtering",
+		"brif/*",
+		"carrier",
+		"dev_port",
+		"device",
+		"ifindex",
+		"iflink",
+		"lower_*",
+		"master",
+		"mtu",
+		"operstate",
+		"phy80211",
+		"queues/rx-*/rps_cpus",
+		"queues/tx-*/tx_timeout",
+		"statistics/*",
+		"tun_flags",
+		"tx_queue_len",
+		"type",
+		"uevent",
+		"upper_*",
+	}
+
+	// virtual interfaces are cloned as well, because bonds, bridges and VLAN
+	// interfaces tie together the interfaces backed by hardware.
+	fileSpecs := cloneContentByClass("net", ifaceEntries, filterNone, filterNone)
+	// the wireless devices backing the wireless interfaces
+	fileSpecs = append(fileSpecs, cloneContentByClass("ieee80211", []string{"index"}, filterNone, filterNone)...)
+
+	// the driver bound to the device backing the interface is a link in the
+	// device directory, which is not always a PCI device (e.g. virtio).
+	sysClassNet := filepath.Join("sys", "class", "net")
+	entries, err := os.ReadDir(sysClassNet)
+	if err != nil {
+		return fileSpecs
+	}
+	for _, entry := range entries {
+		ifaceDest, err := os.Readlink(filepath.Join(sysClassNet, entry.Name()))
+		if err != nil {
+			continue
+		}
+		ifaceDir := filepath.Clean(filepath.Join(sysClassNet, ifaceDest))
+		dest, err := os.Readlink(filepath.Join(ifaceDir, "device"))
+		if err != nil {
+			continue
+		}
+		devDir := filepath.Clean(filepath.Join(ifaceDir, dest))
+		fileSpecs = append(fileSpecs, filepath.Join(devDir, "driver"))
+		fileSpecs = append(fileSpecs, filepath.Join(devDir, "subsystem"))
+	}
+	return fileSpecs
+}
+
+// rdmaGlobs returns a slice of strings pertaining to the RDMA devices ghw
+// cares about, along with the link to their backing device.
+func rdmaGlobs() []string {
+	// intentionally avoid cloning the GUIDs and the GID tables, which are
+	// derived from the hardware addresses, to avoid leaking host-identifiable
+	// data.
+	devEntries := []string{
+		"board_id",
+		"device",
+		"fw_ver",
+		"hca_type",
+		"node_type",
+		"ports/*/link_layer",
+		"ports/*/lid",
+		"ports/*/phys_state",
+		"ports/*/rate",
+		"ports/*/sm_lid",
+		"ports/*/state",
 	}
+	return cloneContentByClass("infiniband", devEntries, filterNone, filterNone)
+}
 
-	filterLink := func(linkDest string) bool {
-		return !strings.Contains(linkDest, "devices/virtual/net")
+// thermalGlobs returns a slice of strings pertaining to the temperature
+// sensors and RAPL energy counters of the processors, along with their
+// backing devices.
+func thermalGlobs() []string {
+	hwmonEntries := []string{
+		"name",
+		"temp*_crit",
+		"temp*_input",
+		"temp*_label",
+		"temp*_max",
 	}
+	fileSpecs := cloneContentByClass("hwmon", hwmonEntries, filterNone, filterNone)
 
-	return cloneContentByClass("net", ifaceEntries, filterNone, filterLink)
+	filterZone := func(zoneName string) bool {
+		return strings.HasPrefix(zoneName, "thermal_zone")
+	}
+	fileSpecs = append(fileSpecs, cloneContentByClass("thermal", []string{"temp", "type"}, filterZone, filterNone)...)
+
+	filterRAPL := func(zoneName string) bool {
+		return strings.HasPrefix(zoneName, "intel-rapl:")
+	}
+	raplEntries := []string{
+		"energy_uj",
+		"max_energy_range_uj",
+		"name",
+	}
+	return append(fileSpecs, cloneContentByClass("powercap", raplEntries, filterRAPL, filterNone)...)
 }
 
 // gpuGlobs returns a slice of strings pertaining to the GPU devices ghw cares
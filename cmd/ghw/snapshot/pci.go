@@ -57,9 +57,15 @@ func scanPCIDeviceRoot(root string) (fileSpecs []string, pciRoots []string) {
 		"irq",
 		"local_cpulist",
 		"modalias",
+		"msi_irqs/*",
 		"numa_node",
+		"physfn",
 		"revision",
+		"sriov_numvfs",
+		"sriov_totalvfs",
+		"subsystem",
 		"vendor",
+		"virtfn*",
 	}
 	entries, err := os.ReadDir(root)
 	if err != nil {
@@ -11,10 +11,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"path"
 	"strconv"
 	"strings"
 
+	ghwcontext "github.com/go-hardware/ghw/pkg/context"
 	"github.com/go-hardware/ghw/pkg/marshal"
+	"github.com/go-hardware/ghw/pkg/pci"
+	"github.com/go-hardware/ghw/pkg/topology"
 	"github.com/go-hardware/ghw/pkg/unit"
 	"github.com/go-hardware/ghw/pkg/util"
 )
@@ -33,7 +37,8 @@ const (
 	DriveTypeODD
 	// DriveTypeSSD indicates a solid-state drive
 	DriveTypeSSD
-	// DriveTypeVirtual indicates a virtual drive i.e. loop devices
+	// DriveTypeVirtual indicates a virtual drive i.e. loop, zram or RAM disk
+	// devices
 	DriveTypeVirtual
 )
 
@@ -115,6 +120,25 @@ const (
 	StorageControllerMMC
 	// StorageControllerLoop indicates a loopback storage controller
 	StorageControllerLoop
+	// StorageControllerSATA indicates a Serial ATA (SATA) controller, or a
+	// parallel ATA controller driven by libata
+	StorageControllerSATA
+	// StorageControllerSAS indicates a Serial Attached SCSI (SAS) controller
+	StorageControllerSAS
+	// StorageControllerUSB indicates a disk attached through USB mass storage
+	// (usb-storage or UAS)
+	StorageControllerUSB
+	// StorageControllerFC indicates a Fibre Channel host bus adapter
+	StorageControllerFC
+	// StorageControllerISCSI indicates a disk attached over the network
+	// through an iSCSI initiator
+	StorageControllerISCSI
+	// StorageControllerNVMeOF indicates an NVMe namespace attached over a
+	// fabrics transport (RDMA, FC or TCP)
+	StorageControllerNVMeOF
+	// StorageControllerXen indicates a Xen paravirtualized block device
+	// (xen-blkfront)
+	StorageControllerXen
 )
 
 var (
@@ -126,6 +150,13 @@ var (
 		StorageControllerVirtIO:  "virtio",
 		StorageControllerMMC:     "MMC",
 		StorageControllerLoop:    "loop",
+		StorageControllerSATA:    "SATA",
+		StorageControllerSAS:     "SAS",
+		StorageControllerUSB:     "USB",
+		StorageControllerFC:      "FC",
+		StorageControllerISCSI:   "iSCSI",
+		StorageControllerNVMeOF:  "NVMe-oF",
+		StorageControllerXen:     "xen-blkfront",
 	}
 
 	// NOTE(fromani): the keys are all lowercase and do not match
@@ -134,13 +165,20 @@ var (
 	// StorageController::MarshalJSON.
 	// We use this table only in UnmarshalJSON, so it should be OK.
 	stringStorageController = map[string]StorageController{
-		"unknown": StorageControllerUnknown,
-		"ide":     StorageControllerIDE,
-		"scsi":    StorageControllerSCSI,
-		"nvme":    StorageControllerNVMe,
-		"virtio":  StorageControllerVirtIO,
-		"mmc":     StorageControllerMMC,
-		"loop":    StorageControllerLoop,
+		"unknown":      StorageControllerUnknown,
+		"ide":          StorageControllerIDE,
+		"scsi":         StorageControllerSCSI,
+		"nvme":         StorageControllerNVMe,
+		"virtio":       StorageControllerVirtIO,
+		"mmc":          StorageControllerMMC,
+		"loop":         StorageControllerLoop,
+		"sata":         StorageControllerSATA,
+		"sas":          StorageControllerSAS,
+		"usb":          StorageControllerUSB,
+		"fc":           StorageControllerFC,
+		"iscsi":        StorageControllerISCSI,
+		"nvme-of":      StorageControllerNVMeOF,
+		"xen-blkfront": StorageControllerXen,
 	}
 )
 
@@ -191,9 +229,10 @@ type Disk struct {
 	BusPath string `json:"bus_path"`
 	// NUMANodeID contains the numeric index (0-based) of the NUMA Node this
 	// disk is affined to, or -1 if the host system is non-NUMA.
-	// TODO(jaypipes): Convert this to a TopologyNode struct pointer and then
-	// add to serialized output as "numa_node,omitempty"
-	NUMANodeID int `json:"-"`
+	NUMANodeID int `json:"numa_node_id"`
+	// Node is a pointer to the `pkg/topology.Node` struct that the disk is
+	// affined to. Will be nil if the architecture is not NUMA.
+	Node *topology.Node `json:"node,omitempty"`
 	// Vendor is the manufacturer of the disk.
 	Vendor string `json:"vendor"`
 	// Model is the model number of the disk.
@@ -206,8 +245,51 @@ type Disk struct {
 	// Partitions contains an array of pointers to `Partition` structs, one for
 	// each partition on the disk.
 	Partitions []*Partition `json:"partitions"`
-	// TODO(jaypipes): Add PCI field for accessing PCI device information
-	// PCI *PCIDevice `json:"pci"`
+	// Health contains the SMART / drive health information for the disk, or
+	// nil if the drive health could not be determined.
+	Health *Health `json:"health,omitempty"`
+	// PCIAddress is the address of the PCI device (NVMe controller, SATA/SAS
+	// HBA, virtio device, USB host controller, etc.) the disk is attached
+	// through, or empty if the disk is not backed by a PCI device.
+	PCIAddress string `json:"pci_address,omitempty"`
+	// PCI is a pointer to a `pkg/pci.Device` struct describing the storage
+	// controller found at PCIAddress, or nil if it could not be determined.
+	PCI *pci.Device `json:"pci,omitempty"`
+	// Aliases contains the persistent device names for the disk, or nil if
+	// they could not be determined.
+	Aliases *DeviceAliases `json:"aliases,omitempty"`
+	// Loop describes the file backing the disk if it is a loop device
+	Loop *LoopDevice `json:"loop,omitempty"`
+	// ZRAM describes the compressed RAM device if the disk is a zram device
+	ZRAM *ZRAMDevice `json:"zram,omitempty"`
+	// NetworkTarget describes the remote storage backing the disk if it is a
+	// network block device (NBD, Ceph RBD or iSCSI)
+	NetworkTarget *NetworkTarget `json:"network_target,omitempty"`
+	// Encryption describes the encrypted volume if the disk is an unlocked
+	// dm-crypt mapping or holds an encrypted volume itself, or nil otherwise
+	Encryption *Encryption `json:"encryption,omitempty"`
+}
+
+// DeviceAliases contains the persistent names of a disk or partition. Unlike
+// the kernel name of a block device (e.g. `sda`), these names do not change
+// across reboots or when devices are added or removed. On Linux, these are
+// the symbolic links udev maintains under `/dev/disk`.
+type DeviceAliases struct {
+	// ByID contains the names derived from the hardware serial number or
+	// WWN of the device, e.g. `/dev/disk/by-id/wwn-0x5002538e40a0b1c2`
+	ByID []string `json:"by_id,omitempty"`
+	// ByPath contains the names derived from the bus path to the device,
+	// e.g. `/dev/disk/by-path/pci-0000:00:17.0-ata-1`
+	ByPath []string `json:"by_path,omitempty"`
+	// ByUUID contains the names derived from the UUID of the filesystem
+	// contained on the device
+	ByUUID []string `json:"by_uuid,omitempty"`
+	// ByPartUUID contains the names derived from the partition table UUID of
+	// the partition
+	ByPartUUID []string `json:"by_partuuid,omitempty"`
+	// ByLabel contains the names derived from the label of the filesystem
+	// contained on the device
+	ByLabel []string `json:"by_label,omitempty"`
 }
 
 // Partition describes a logical division of a Disk.
@@ -234,6 +316,12 @@ type Partition struct {
 	// FilesystemLabel is the label of the filesystem contained on the
 	// partition. On Linux, this is derived from the `ID_FS_NAME` udev entry.
 	FilesystemLabel string `json:"filesystem_label"`
+	// Aliases contains the persistent device names for the partition, or nil
+	// if they could not be determined.
+	Aliases *DeviceAliases `json:"aliases,omitempty"`
+	// Encryption describes the encrypted volume the partition holds, or nil
+	// if the partition is not encrypted
+	Encryption *Encryption `json:"encryption,omitempty"`
 }
 
 // Info describes all disk drives and partitions in the host system.
@@ -247,6 +335,65 @@ type Info struct {
 	// Partitions contains an array of pointers to `Partition` structs, one for
 	// each partition on any disk drive on the host system.
 	Partitions []*Partition `json:"-"`
+	// Swaps contains an array of pointers to `Swap` structs, one for each
+	// active swap device or file on the host system.
+	Swaps []*Swap `json:"swaps,omitempty"`
+}
+
+// DiskBySerial returns the Disk having the supplied serial number, or nil if
+// no disk on the host system has that serial number.
+func (i *Info) DiskBySerial(serial string) *Disk {
+	if serial == "" || serial == util.UNKNOWN {
+		return nil
+	}
+	for _, d := range i.Disks {
+		if d.SerialNumber == serial {
+			return d
+		}
+	}
+	return nil
+}
+
+// PartitionByUUID returns the Partition having the supplied partition UUID or
+// containing a filesystem with the supplied UUID, or nil if no such partition
+// exists on the host system. The comparison is case-insensitive.
+func (i *Info) PartitionByUUID(uuid string) *Partition {
+	if uuid == "" || uuid == util.UNKNOWN {
+		return nil
+	}
+	for _, d := range i.Disks {
+		for _, p := range d.Partitions {
+			if strings.EqualFold(p.UUID, uuid) {
+				return p
+			}
+			if p.Aliases == nil {
+				continue
+			}
+			for _, alias := range p.Aliases.ByUUID {
+				if strings.EqualFold(path.Base(alias), uuid) {
+					return p
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// PartitionByMountPoint returns the Partition mounted at the supplied path, or
+// nil if no partition is mounted there.
+func (i *Info) PartitionByMountPoint(mountPoint string) *Partition {
+	if mountPoint == "" {
+		return nil
+	}
+	mountPoint = path.Clean(mountPoint)
+	for _, d := range i.Disks {
+		for _, p := range d.Partitions {
+			if p.MountPoint != "" && path.Clean(p.MountPoint) == mountPoint {
+				return p
+			}
+		}
+	}
+	return nil
 }
 
 // New returns a pointer to an Info struct that describes the block storage
@@ -256,9 +403,40 @@ func New(ctx context.Context) (*Info, error) {
 	if err := info.load(ctx); err != nil {
 		return nil, err
 	}
+	opts := ghwcontext.OptionsFromContext(ctx)
+	if opts.ExcludeVirtualDisks != nil && *opts.ExcludeVirtualDisks {
+		info.excludeVirtualDisks()
+	}
 	return info, nil
 }
 
+// excludeVirtualDisks removes the virtual disks, and their partitions, from
+// the Info and subtracts their size from the total storage size.
+func (i *Info) excludeVirtualDisks() {
+	disks := make([]*Disk, 0, len(i.Disks))
+	for _, d := range i.Disks {
+		if d.DriveType != DriveTypeVirtual {
+			disks = append(disks, d)
+			continue
+		}
+		if i.TotalSizeBytes >= d.SizeBytes {
+			i.TotalSizeBytes -= d.SizeBytes
+		}
+	}
+	i.Disks = disks
+	if i.Partitions == nil {
+		return
+	}
+	parts := make([]*Partition, 0, len(i.Partitions))
+	for _, p := range i.Partitions {
+		if p.Disk != nil && p.Disk.DriveType == DriveTypeVirtual {
+			continue
+		}
+		parts = append(parts, p)
+	}
+	i.Partitions = parts
+}
+
 // String returns a short string indicating important information about the
 // block storage on the host system.
 func (i *Info) String() string {
@@ -311,6 +489,10 @@ func (d *Disk) String() string {
 	if d.IsRemovable {
 		removable = " removable=true"
 	}
+	controller := ""
+	if d.PCIAddress != "" {
+		controller = " controller=" + d.PCIAddress
+	}
 	return fmt.Sprintf(
 		"%s %s (%s) %s [@%s%s]%s",
 		d.Name,
@@ -325,6 +507,7 @@ func (d *Disk) String() string {
 			serial,
 			wwn,
 			removable,
+			controller,
 		),
 	)
 }
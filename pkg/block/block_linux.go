@@ -16,6 +16,9 @@ import (
 
 	ghwcontext "github.com/go-hardware/ghw/pkg/context"
 	ghwpath "github.com/go-hardware/ghw/pkg/path"
+	"github.com/go-hardware/ghw/pkg/pci"
+	pciaddr "github.com/go-hardware/ghw/pkg/pci/address"
+	"github.com/go-hardware/ghw/pkg/topology"
 	"github.com/go-hardware/ghw/pkg/util"
 )
 
@@ -26,6 +29,10 @@ const (
 func (i *Info) load(ctx context.Context) error {
 	paths := ghwpath.New(ctx)
 	i.Disks = disks(ctx, paths)
+	diskFillNUMANodes(ctx, i.Disks)
+	diskFillPCIDevice(ctx, i.Disks)
+	diskFillEncryption(ctx, paths, i.Disks)
+	i.Swaps = swaps(ctx, paths)
 	var tsb uint64
 	for _, d := range i.Disks {
 		tsb += d.SizeBytes
@@ -64,21 +71,95 @@ func diskSizeBytes(paths *ghwpath.Paths, disk string) uint64 {
 	return size * sectorSize
 }
 
+// diskNUMANodeID returns the NUMA node the disk is affined to, or -1 if the
+// host system is non-NUMA or the disk is not backed by a physical device.
+//
+// /sys/block/$DEVICE is a symbolic link into the /sys/devices tree. The
+// numa_node pseudo-file lives in the directory of the PCI device (or other
+// bus device) the disk is attached through, so we walk up the device path
+// until we find it.
 func diskNUMANodeID(paths *ghwpath.Paths, disk string) int {
 	link, err := os.Readlink(filepath.Join(paths.SysBlock, disk))
 	if err != nil {
 		return -1
 	}
-	for partial := link; strings.HasPrefix(partial, "../devices/"); partial = filepath.Base(partial) {
-		if nodeContents, err := os.ReadFile(filepath.Join(paths.SysBlock, partial, "numa_node")); err != nil {
-			if nodeInt, err := strconv.Atoi(string(nodeContents)); err != nil {
-				return nodeInt
-			}
+	sysDevices := filepath.Join(filepath.Dir(paths.SysBlock), "devices")
+	for dir := filepath.Join(paths.SysBlock, link); strings.HasPrefix(dir, sysDevices+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		nodeContents, err := os.ReadFile(filepath.Join(dir, "numa_node"))
+		if err != nil {
+			continue
+		}
+		nodeInt, err := strconv.Atoi(strings.TrimSpace(string(nodeContents)))
+		if err != nil {
+			return -1
 		}
+		return nodeInt
 	}
 	return -1
 }
 
+// diskPCIAddress returns the address of the PCI device closest to the disk in
+// the /sys/devices tree, which is the storage controller the disk is attached
+// through, or "" if the disk is not backed by a PCI device.
+func diskPCIAddress(paths *ghwpath.Paths, disk string) string {
+	link, err := os.Readlink(filepath.Join(paths.SysBlock, disk))
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(filepath.ToSlash(link), "/")
+	for x := len(parts) - 1; x >= 0; x-- {
+		if pciAddr := pciaddr.FromString(parts[x]); pciAddr != nil {
+			return pciAddr.String()
+		}
+	}
+	return ""
+}
+
+// diskFillPCIDevice loops through each Disk struct and attempts to fill the PCI
+// attribute with PCI device information
+func diskFillPCIDevice(ctx context.Context, disks []*Disk) {
+	var pciInfo *pci.Info
+	for _, disk := range disks {
+		if disk.PCIAddress == "" {
+			continue
+		}
+		if pciInfo == nil {
+			info, err := pci.New(ctx)
+			if err != nil {
+				ghwcontext.Warn(ctx, "failed to get PCI information for disks: %s\n", err)
+				return
+			}
+			pciInfo = info
+		}
+		disk.PCI = pciInfo.GetDevice(ctx, disk.PCIAddress)
+	}
+}
+
+// diskFillNUMANodes loops through each Disk struct and sets the Disk.Node
+// field to the topology node matching the Disk.NUMANodeID. If the host system
+// is not a NUMA system, the Node field will be left nil.
+func diskFillNUMANodes(ctx context.Context, disks []*Disk) {
+	var topo *topology.Info
+	for _, disk := range disks {
+		if disk.NUMANodeID < 0 {
+			continue
+		}
+		if topo == nil {
+			info, err := topology.New(ctx)
+			if err != nil {
+				ghwcontext.Warn(ctx, "failed to get topology information for disks: %s\n", err)
+				return
+			}
+			topo = info
+		}
+		for _, node := range topo.Nodes {
+			if disk.NUMANodeID == node.ID {
+				disk.Node = node
+			}
+		}
+	}
+}
+
 func diskVendor(paths *ghwpath.Paths, disk string) string {
 	// In Linux, the vendor for a disk device is found in the
 	// /sys/block/$DEVICE/device/vendor file in sysfs
@@ -111,22 +192,82 @@ func udevInfoPartition(paths *ghwpath.Paths, disk string, partition string) (map
 }
 
 func udevInfo(paths *ghwpath.Paths, devNo string) (map[string]string, error) {
+	info, _, err := udevData(paths, devNo)
+	return info, err
+}
+
+// udevData reads the udev runtime database entry for the block device with
+// the supplied major:minor numbers, returning the device's properties ("E:"
+// lines) and the symbolic links udev created for the device ("S:" lines).
+func udevData(paths *ghwpath.Paths, devNo string) (map[string]string, []string, error) {
 	// Look up block device in udev runtime database
 	udevID := "b" + strings.TrimSpace(devNo)
 	udevBytes, err := os.ReadFile(filepath.Join(paths.RunUdevData, udevID))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	udevInfo := make(map[string]string)
+	udevLinks := make([]string, 0)
 	for _, udevLine := range strings.Split(string(udevBytes), "\n") {
 		if strings.HasPrefix(udevLine, "E:") {
 			if s := strings.SplitN(udevLine[2:], "=", 2); len(s) == 2 {
 				udevInfo[s[0]] = s[1]
 			}
+		} else if strings.HasPrefix(udevLine, "S:") {
+			udevLinks = append(udevLinks, udevLine[2:])
 		}
 	}
-	return udevInfo, nil
+	return udevInfo, udevLinks, nil
+}
+
+// deviceAliases returns the persistent /dev/disk/by-* names udev created for
+// the block device with the supplied major:minor numbers, or nil if the device
+// has no entry in the udev runtime database.
+func deviceAliases(paths *ghwpath.Paths, devNo string) *DeviceAliases {
+	_, links, err := udevData(paths, devNo)
+	if err != nil {
+		return nil
+	}
+	aliases := &DeviceAliases{}
+	for _, link := range links {
+		// The udev database records links relative to /dev, e.g.
+		// "disk/by-id/ata-Samsung_SSD_860_EVO_500GB_S3Z1NB0K123456A"
+		dir, name := filepath.Split(link)
+		if name == "" {
+			continue
+		}
+		alias := filepath.Join("/dev", link)
+		switch filepath.Clean(dir) {
+		case "disk/by-id":
+			aliases.ByID = append(aliases.ByID, alias)
+		case "disk/by-path":
+			aliases.ByPath = append(aliases.ByPath, alias)
+		case "disk/by-uuid":
+			aliases.ByUUID = append(aliases.ByUUID, alias)
+		case "disk/by-partuuid":
+			aliases.ByPartUUID = append(aliases.ByPartUUID, alias)
+		case "disk/by-label":
+			aliases.ByLabel = append(aliases.ByLabel, alias)
+		}
+	}
+	return aliases
+}
+
+func diskAliases(paths *ghwpath.Paths, disk string) *DeviceAliases {
+	devNo, err := os.ReadFile(filepath.Join(paths.SysBlock, disk, "dev"))
+	if err != nil {
+		return nil
+	}
+	return deviceAliases(paths, string(devNo))
+}
+
+func partitionAliases(paths *ghwpath.Paths, disk string, partition string) *DeviceAliases {
+	devNo, err := os.ReadFile(filepath.Join(paths.SysBlock, disk, partition, "dev"))
+	if err != nil {
+		return nil
+	}
+	return deviceAliases(paths, string(devNo))
 }
 
 func diskModel(paths *ghwpath.Paths, disk string) string {
@@ -144,7 +285,7 @@ func diskModel(paths *ghwpath.Paths, disk string) string {
 func diskSerialNumber(paths *ghwpath.Paths, disk string) string {
 	info, err := udevInfoDisk(paths, disk)
 	if err != nil {
-		return util.UNKNOWN
+		return diskSysfsSerialNumber(paths, disk)
 	}
 
 	// First try to use the serial from sg3_utils
@@ -166,6 +307,22 @@ func diskSerialNumber(paths *ghwpath.Paths, disk string) string {
 	if serial, ok := info["ID_SERIAL"]; ok {
 		return serial
 	}
+	return diskSysfsSerialNumber(paths, disk)
+}
+
+// diskSysfsSerialNumber returns the serial number the disk driver exposes in
+// sysfs. virtio-blk disks have no udev serial properties unless the udev
+// rules import them from /sys/block/$DEVICE/serial, and NVMe controllers
+// expose the serial number in /sys/block/$DEVICE/device/serial.
+func diskSysfsSerialNumber(paths *ghwpath.Paths, disk string) string {
+	for _, path := range []string{
+		filepath.Join(paths.SysBlock, disk, "serial"),
+		filepath.Join(paths.SysBlock, disk, "device", "serial"),
+	} {
+		if serial := util.ReadTrimmedFile(path); serial != "" {
+			return serial
+		}
+	}
 	return util.UNKNOWN
 }
 
@@ -233,6 +390,7 @@ func diskPartitions(ctx context.Context, paths *ghwpath.Paths, disk string) []*P
 			UUID:            du,
 			Label:           label,
 			FilesystemLabel: fsLabel,
+			Aliases:         partitionAliases(paths, disk, fname),
 		}
 		out = append(out, p)
 	}
@@ -312,12 +470,7 @@ func disks(ctx context.Context, paths *ghwpath.Paths) []*Disk {
 	for _, file := range files {
 		dname := file.Name()
 
-		driveType, storageController := diskTypes(dname)
-		// TODO(jaypipes): Move this into diskTypes() once abstracting
-		// diskIsRotational for ease of unit testing
-		if !diskIsRotational(ctx, paths, dname) {
-			driveType = DriveTypeSSD
-		}
+		driveType, storageController := diskDriveTypeAndController(paths, dname)
 		size := diskSizeBytes(paths, dname)
 		pbs := diskPhysicalBlockSizeBytes(paths, dname)
 		busPath := diskBusPath(paths, dname)
@@ -332,6 +485,10 @@ func disks(ctx context.Context, paths *ghwpath.Paths) []*Disk {
 			// We don't care about unused loop devices...
 			continue
 		}
+		if strings.HasPrefix(dname, "nbd") && size == 0 {
+			// ...nor about unconnected network block devices
+			continue
+		}
 		d := &Disk{
 			Name:                   dname,
 			SizeBytes:              size,
@@ -341,12 +498,19 @@ func disks(ctx context.Context, paths *ghwpath.Paths) []*Disk {
 			StorageController:      storageController,
 			BusPath:                busPath,
 			NUMANodeID:             node,
+			PCIAddress:             diskPCIAddress(paths, dname),
+			Aliases:                diskAliases(paths, dname),
 			Vendor:                 vendor,
 			Model:                  model,
 			SerialNumber:           serialNo,
 			WWN:                    wwn,
 		}
 
+		d.Health = diskHealth(ctx, paths, dname, driveType, storageController)
+		d.Loop = diskLoopDevice(paths, dname)
+		d.ZRAM = diskZRAMDevice(paths, dname)
+		d.NetworkTarget = diskNetworkTarget(paths, dname)
+
 		parts := diskPartitions(ctx, paths, dname)
 		// Map this Disk object into the Partition...
 		for _, part := range parts {
@@ -389,22 +553,192 @@ func diskTypes(dname string) (
 		storageController = StorageControllerSCSI
 	} else if strings.HasPrefix(dname, "xvd") {
 		driveType = DriveTypeHDD
-		storageController = StorageControllerSCSI
+		storageController = StorageControllerXen
 	} else if strings.HasPrefix(dname, "mmc") {
 		driveType = DriveTypeSSD
 		storageController = StorageControllerMMC
 	} else if strings.HasPrefix(dname, "loop") {
 		driveType = DriveTypeVirtual
 		storageController = StorageControllerLoop
+	} else if strings.HasPrefix(dname, "zram") || strings.HasPrefix(dname, "ram") {
+		driveType = DriveTypeVirtual
 	}
 
 	return driveType, storageController
 }
 
-func diskIsRotational(ctx context.Context, paths *ghwpath.Paths, devName string) bool {
-	path := filepath.Join(paths.SysBlock, devName, "queue", "rotational")
-	contents := util.SafeIntFromFile(ctx, path)
-	return contents == 1
+// diskDriveTypeAndController returns the drive type and storage controller of
+// a disk. The guess made from the disk's name by diskTypes() is refined using
+// the transport the disk is attached through, the disk's udev properties and
+// whether the kernel reports the disk's queue as rotational.
+func diskDriveTypeAndController(paths *ghwpath.Paths, disk string) (
+	DriveType,
+	StorageController,
+) {
+	driveType, storageController := diskTypes(disk)
+	// NOTE: a missing udev database entry is not an error here, we just
+	// have less information to go on.
+	info, _ := udevInfoDisk(paths, disk)
+
+	switch storageController {
+	case StorageControllerSCSI:
+		// sd and sr devices are driven by the SCSI disk/cdrom drivers
+		// regardless of the physical transport, so find out what the SCSI
+		// host actually is.
+		if sc := diskSCSITransport(paths, disk, info); sc != StorageControllerUnknown {
+			storageController = sc
+		}
+	case StorageControllerNVMe:
+		if diskIsNVMeOverFabrics(paths, disk) {
+			storageController = StorageControllerNVMeOF
+		}
+	}
+
+	// Floppy, optical, loop and NVMe/MMC drives are unambiguously typed by
+	// their name. Everything else needs a closer look.
+	if driveType != DriveTypeHDD && driveType != DriveTypeUnknown {
+		return driveType, storageController
+	}
+
+	// The ATA IDENTIFY data, when present, is the most reliable source: it
+	// reports a rotation rate of zero for non-rotating media. USB bridges in
+	// particular often report a rotational queue for flash media.
+	if rpm, ok := info["ID_ATA_ROTATION_RATE_RPM"]; ok {
+		if n, err := strconv.Atoi(rpm); err == nil {
+			if n == 0 {
+				return DriveTypeSSD, storageController
+			}
+			return DriveTypeHDD, storageController
+		}
+	}
+	if info["ID_DRIVE_THUMB"] == "1" || info["ID_DRIVE_FLASH_SD"] == "1" {
+		return DriveTypeSSD, storageController
+	}
+	if storageController == StorageControllerUSB && diskIsRemovable(paths, disk) {
+		// USB mass storage with removable media and no ATA identity behind
+		// it is a flash stick or a card reader
+		return DriveTypeSSD, storageController
+	}
+	if diskRotational(paths, disk) == 0 {
+		driveType = DriveTypeSSD
+	}
+	return driveType, storageController
+}
+
+// diskRotational returns 1 if the kernel reports the disk's request queue as
+// rotational, 0 if it reports it as non-rotational and -1 if the information
+// is not available.
+func diskRotational(paths *ghwpath.Paths, disk string) int {
+	path := filepath.Join(paths.SysBlock, disk, "queue", "rotational")
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return -1
+	}
+	rotational, err := strconv.Atoi(strings.TrimSpace(string(contents)))
+	if err != nil {
+		return -1
+	}
+	return rotational
+}
+
+// diskSCSITransport returns the storage controller a SCSI disk is attached
+// through, or StorageControllerUnknown if it could not be determined.
+//
+// The sysfs device path of the disk is examined first, since the kernel
+// inserts an intermediate device for each transport class between the host
+// adapter and the SCSI target, e.g.:
+//
+//	/sys/devices/pci0000:00/0000:00:17.0/ata1/host0/target0:0:0/0:0:0:0/block/sda
+//	/sys/devices/pci0000:00/0000:00:14.0/usb2/2-1/2-1:1.0/host6/target6:0:0/6:0:0:0/block/sdb
+//	/sys/devices/pci0000:00/0000:00:01.0/0000:01:00.0/host0/rport-0:0-0/target0:0:0/0:0:0:0/block/sdc
+//	/sys/devices/pci0000:00/0000:00:03.0/0000:03:00.0/host0/port-0:0/end_device-0:0/target0:0:0/0:0:0:0/block/sdd
+//	/sys/devices/platform/host3/session1/target3:0:0/3:0:0:1/block/sde
+//
+// If the device path is inconclusive, the udev ID_BUS, ID_USB_* and ID_PATH
+// properties are used instead.
+func diskSCSITransport(paths *ghwpath.Paths, disk string, udevInfo map[string]string) StorageController {
+	if devPath, err := os.Readlink(filepath.Join(paths.SysBlock, disk)); err == nil {
+		for _, part := range strings.Split(filepath.ToSlash(devPath), "/") {
+			switch {
+			case strings.HasPrefix(part, "usb"):
+				return StorageControllerUSB
+			case strings.HasPrefix(part, "session"):
+				return StorageControllerISCSI
+			case strings.HasPrefix(part, "rport-"):
+				return StorageControllerFC
+			case strings.HasPrefix(part, "end_device-"):
+				return StorageControllerSAS
+			case strings.HasPrefix(part, "ata") && isNumeric(part[3:]):
+				return StorageControllerSATA
+			}
+		}
+	}
+
+	if _, ok := udevInfo["ID_USB_DRIVER"]; ok {
+		return StorageControllerUSB
+	}
+	switch udevInfo["ID_BUS"] {
+	case "usb":
+		return StorageControllerUSB
+	case "ata":
+		return StorageControllerSATA
+	}
+	idPath := udevInfo["ID_PATH"]
+	switch {
+	case strings.Contains(idPath, "-usb-"):
+		return StorageControllerUSB
+	case strings.Contains(idPath, "-iscsi-"):
+		return StorageControllerISCSI
+	case strings.Contains(idPath, "-fc-"):
+		return StorageControllerFC
+	case strings.Contains(idPath, "-sas-"):
+		return StorageControllerSAS
+	case strings.Contains(idPath, "-ata-"):
+		return StorageControllerSATA
+	}
+	return StorageControllerUnknown
+}
+
+// diskIsNVMeOverFabrics returns true if the NVMe namespace is reached through
+// a fabrics transport (RDMA, FC, TCP or loop) instead of PCIe.
+func diskIsNVMeOverFabrics(paths *ghwpath.Paths, disk string) bool {
+	// For a namespace attached to a single controller, the device link
+	// points at the controller, which reports its transport
+	transportPaths := []string{
+		filepath.Join(paths.SysBlock, disk, "device", "transport"),
+	}
+	// For multipath namespaces, the block device lives under the NVMe
+	// subsystem, which links to each of its controllers
+	if devPath, err := os.Readlink(filepath.Join(paths.SysBlock, disk)); err == nil {
+		if strings.Contains(devPath, "/nvme-fabrics/") {
+			return true
+		}
+		subsysPath := filepath.Dir(filepath.Join(paths.SysBlock, devPath))
+		if matches, err := filepath.Glob(filepath.Join(subsysPath, "nvme*", "transport")); err == nil {
+			transportPaths = append(transportPaths, matches...)
+		}
+	}
+	for _, path := range transportPaths {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		transport := strings.TrimSpace(string(contents))
+		return transport != "pcie"
+	}
+	return false
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
 }
 
 // partitionSizeBytes returns the size in bytes of the partition given a disk
@@ -462,6 +796,16 @@ func partitionInfo(paths *ghwpath.Paths, part string) (string, string, bool) {
 	return "", "", true
 }
 
+// unescapeMountField decodes the space, tab, newline and backslash characters
+// the kernel encodes as octal escapes in the fields of /proc/self/mounts and
+// /proc/swaps
+func unescapeMountField(field string) string {
+	r := strings.NewReplacer(
+		"\\011", "\t", "\\012", "\n", "\\040", " ", "\\\\", "\\",
+	)
+	return r.Replace(field)
+}
+
 type mountEntry struct {
 	Partition      string
 	Mountpoint     string
@@ -490,11 +834,7 @@ func parseMountEntry(line string) *mountEntry {
 	//   '\040' is used to encode a space character, '\011' to encode a tab
 	//   character, '\012' to encode a newline character, and '\\' to encode a
 	//   backslash."
-	mp := fields[1]
-	r := strings.NewReplacer(
-		"\\011", "\t", "\\012", "\n", "\\040", " ", "\\\\", "\\",
-	)
-	mp = r.Replace(mp)
+	mp := unescapeMountField(fields[1])
 
 	res := &mountEntry{
 		Partition:      fields[0],
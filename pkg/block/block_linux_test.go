@@ -136,7 +136,7 @@ func TestDiskTypes(t *testing.T) {
 			line: "xvda1",
 			expected: entry{
 				driveType:         DriveTypeHDD,
-				storageController: StorageControllerSCSI,
+				storageController: StorageControllerXen,
 			},
 		},
 		{
@@ -174,6 +174,13 @@ func TestDiskTypes(t *testing.T) {
 				storageController: StorageControllerLoop,
 			},
 		},
+		{
+			line: "zram0",
+			expected: entry{
+				driveType:         DriveTypeVirtual,
+				storageController: StorageControllerUnknown,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -193,6 +200,192 @@ func TestDiskTypes(t *testing.T) {
 	}
 }
 
+func TestDiskDriveTypeAndController(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_BLOCK"); ok {
+		t.Skip("Skipping block tests.")
+	}
+	baseDir, _ := os.MkdirTemp("", "test")
+	defer os.RemoveAll(baseDir)
+	ctx := ghwcontext.New(ghwcontext.WithRootMountpoint(baseDir))
+	paths := ghwpath.New(ctx)
+
+	_ = os.MkdirAll(paths.SysBlock, 0755)
+	_ = os.MkdirAll(paths.RunUdevData, 0755)
+
+	tests := []struct {
+		disk              string
+		devPath           string
+		rotational        string
+		removable         string
+		udev              string
+		driveType         DriveType
+		storageController StorageController
+	}{
+		{
+			disk:              "sda",
+			devPath:           "pci0000:00/0000:00:17.0/ata1/host0/target0:0:0/0:0:0:0/block/sda",
+			rotational:        "1",
+			udev:              "E:ID_BUS=ata\nE:ID_ATA_ROTATION_RATE_RPM=7200\n",
+			driveType:         DriveTypeHDD,
+			storageController: StorageControllerSATA,
+		},
+		{
+			// A SATA SSD behind a USB bridge reporting a rotational queue
+			disk:              "sdb",
+			devPath:           "pci0000:00/0000:00:14.0/usb2/2-1/2-1:1.0/host6/target6:0:0/6:0:0:0/block/sdb",
+			rotational:        "1",
+			udev:              "E:ID_BUS=ata\nE:ID_USB_DRIVER=uas\nE:ID_ATA_ROTATION_RATE_RPM=0\n",
+			driveType:         DriveTypeSSD,
+			storageController: StorageControllerUSB,
+		},
+		{
+			// A USB flash stick
+			disk:              "sdc",
+			devPath:           "pci0000:00/0000:00:14.0/usb1/1-2/1-2:1.0/host7/target7:0:0/7:0:0:0/block/sdc",
+			rotational:        "1",
+			removable:         "1",
+			udev:              "E:ID_BUS=usb\nE:ID_USB_DRIVER=usb-storage\n",
+			driveType:         DriveTypeSSD,
+			storageController: StorageControllerUSB,
+		},
+		{
+			disk:              "sdd",
+			devPath:           "pci0000:00/0000:00:03.0/0000:03:00.0/host0/port-0:0/end_device-0:0/target0:0:0/0:0:0:0/block/sdd",
+			rotational:        "0",
+			driveType:         DriveTypeSSD,
+			storageController: StorageControllerSAS,
+		},
+		{
+			disk:              "sde",
+			devPath:           "pci0000:00/0000:00:01.0/0000:01:00.0/host1/rport-1:0-0/target1:0:0/1:0:0:0/block/sde",
+			rotational:        "1",
+			driveType:         DriveTypeHDD,
+			storageController: StorageControllerFC,
+		},
+		{
+			disk:              "sdf",
+			devPath:           "platform/host3/session1/target3:0:0/3:0:0:1/block/sdf",
+			rotational:        "1",
+			driveType:         DriveTypeHDD,
+			storageController: StorageControllerISCSI,
+		},
+		{
+			// Transport only known from udev
+			disk:              "sdg",
+			rotational:        "1",
+			udev:              "E:ID_PATH=pci-0000:00:1f.2-ata-2\n",
+			driveType:         DriveTypeHDD,
+			storageController: StorageControllerSATA,
+		},
+		{
+			// virtio-scsi stays plain SCSI
+			disk:              "sdh",
+			devPath:           "pci0000:00/0000:00:04.0/virtio2/host0/target0:0:0/0:0:0:0/block/sdh",
+			rotational:        "1",
+			driveType:         DriveTypeHDD,
+			storageController: StorageControllerSCSI,
+		},
+		{
+			disk:              "nvme0n1",
+			devPath:           "virtual/nvme-fabrics/ctl/nvme0/nvme0n1",
+			driveType:         DriveTypeSSD,
+			storageController: StorageControllerNVMeOF,
+		},
+		{
+			disk:              "nvme1n1",
+			devPath:           "pci0000:00/0000:00:1d.0/0000:3d:00.0/nvme/nvme1/nvme1n1",
+			driveType:         DriveTypeSSD,
+			storageController: StorageControllerNVMe,
+		},
+		{
+			disk:              "xvda",
+			devPath:           "vbd-51712/block/xvda",
+			rotational:        "0",
+			driveType:         DriveTypeSSD,
+			storageController: StorageControllerXen,
+		},
+	}
+
+	devicesDir := filepath.Join(filepath.Dir(paths.SysBlock), "devices")
+	for x, test := range tests {
+		diskDir := filepath.Join(paths.SysBlock, test.disk)
+		if test.devPath != "" {
+			_ = os.MkdirAll(filepath.Join(devicesDir, test.devPath), 0755)
+			_ = os.Symlink(filepath.Join("..", "devices", test.devPath), diskDir)
+		} else {
+			_ = os.Mkdir(diskDir, 0755)
+		}
+		if test.rotational != "" {
+			_ = os.Mkdir(filepath.Join(diskDir, "queue"), 0755)
+			_ = os.WriteFile(filepath.Join(diskDir, "queue", "rotational"), []byte(test.rotational+"\n"), 0644)
+		}
+		if test.removable != "" {
+			_ = os.WriteFile(filepath.Join(diskDir, "removable"), []byte(test.removable+"\n"), 0644)
+		}
+		if test.udev != "" {
+			devNo := fmt.Sprintf("8:%d", x*16)
+			_ = os.WriteFile(filepath.Join(diskDir, "dev"), []byte(devNo+"\n"), 0644)
+			_ = os.WriteFile(filepath.Join(paths.RunUdevData, "b"+devNo), []byte(test.udev), 0644)
+		}
+
+		driveType, storageController := diskDriveTypeAndController(paths, test.disk)
+		if driveType != test.driveType {
+			t.Fatalf("For %s, expected drive type %s, but got %s", test.disk, test.driveType, driveType)
+		}
+		if storageController != test.storageController {
+			t.Fatalf("For %s, expected storage controller %s, but got %s", test.disk, test.storageController, storageController)
+		}
+	}
+
+	// An NVMe controller reports its transport directly
+	nvmeDev := filepath.Join(devicesDir, "virtual", "nvme-subsystem", "nvme-subsys2")
+	_ = os.MkdirAll(filepath.Join(nvmeDev, "nvme2n1"), 0755)
+	_ = os.MkdirAll(filepath.Join(nvmeDev, "nvme2"), 0755)
+	_ = os.WriteFile(filepath.Join(nvmeDev, "nvme2", "transport"), []byte("tcp\n"), 0644)
+	_ = os.Symlink(filepath.Join("..", "devices", "virtual", "nvme-subsystem", "nvme-subsys2", "nvme2n1"), filepath.Join(paths.SysBlock, "nvme2n1"))
+	if _, sc := diskDriveTypeAndController(paths, "nvme2n1"); sc != StorageControllerNVMeOF {
+		t.Fatalf("For nvme2n1, expected storage controller %s, but got %s", StorageControllerNVMeOF, sc)
+	}
+}
+
+func TestDiskNUMANodeIDAndPCIAddress(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_BLOCK"); ok {
+		t.Skip("Skipping block tests.")
+	}
+	baseDir, _ := os.MkdirTemp("", "test")
+	defer os.RemoveAll(baseDir)
+	ctx := ghwcontext.New(ghwcontext.WithRootMountpoint(baseDir))
+	paths := ghwpath.New(ctx)
+
+	_ = os.MkdirAll(paths.SysBlock, 0755)
+	devicesDir := filepath.Join(filepath.Dir(paths.SysBlock), "devices")
+
+	// An NVMe namespace behind a PCIe bridge, affined to NUMA node 1
+	nvmePath := filepath.Join("pci0000:3a", "0000:3a:00.0", "0000:3b:00.0", "nvme", "nvme0", "nvme0n1")
+	_ = os.MkdirAll(filepath.Join(devicesDir, nvmePath), 0755)
+	_ = os.WriteFile(filepath.Join(devicesDir, "pci0000:3a", "0000:3a:00.0", "numa_node"), []byte("0\n"), 0644)
+	_ = os.WriteFile(filepath.Join(devicesDir, "pci0000:3a", "0000:3a:00.0", "0000:3b:00.0", "numa_node"), []byte("1\n"), 0644)
+	_ = os.Symlink(filepath.Join("..", "devices", nvmePath), filepath.Join(paths.SysBlock, "nvme0n1"))
+
+	// A loop device has no backing bus device
+	loopPath := filepath.Join("virtual", "block", "loop0")
+	_ = os.MkdirAll(filepath.Join(devicesDir, loopPath), 0755)
+	_ = os.Symlink(filepath.Join("..", "devices", loopPath), filepath.Join(paths.SysBlock, "loop0"))
+
+	if node := diskNUMANodeID(paths, "nvme0n1"); node != 1 {
+		t.Fatalf("Expected NUMA node 1 for nvme0n1, but got %d", node)
+	}
+	if addr := diskPCIAddress(paths, "nvme0n1"); addr != "0000:3b:00.0" {
+		t.Fatalf("Expected PCI address 0000:3b:00.0 for nvme0n1, but got %q", addr)
+	}
+	if node := diskNUMANodeID(paths, "loop0"); node != -1 {
+		t.Fatalf("Expected NUMA node -1 for loop0, but got %d", node)
+	}
+	if addr := diskPCIAddress(paths, "loop0"); addr != "" {
+		t.Fatalf("Expected no PCI address for loop0, but got %q", addr)
+	}
+}
+
 func TestDiskPartLabel(t *testing.T) {
 	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_BLOCK"); ok {
 		t.Skip("Skipping block tests.")
@@ -313,6 +506,71 @@ func TestDiskPartUUID(t *testing.T) {
 	}
 }
 
+func TestDeviceAliases(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_BLOCK"); ok {
+		t.Skip("Skipping block tests.")
+	}
+	baseDir, _ := os.MkdirTemp("", "test")
+	defer os.RemoveAll(baseDir)
+	ctx := ghwcontext.New(ghwcontext.WithRootMountpoint(baseDir))
+	paths := ghwpath.New(ctx)
+
+	_ = os.MkdirAll(paths.SysBlock, 0755)
+	_ = os.MkdirAll(paths.RunUdevData, 0755)
+
+	_ = os.Mkdir(filepath.Join(paths.SysBlock, "sda"), 0755)
+	_ = os.Mkdir(filepath.Join(paths.SysBlock, "sda", "sda1"), 0755)
+	_ = os.WriteFile(filepath.Join(paths.SysBlock, "sda", "dev"), []byte("8:0\n"), 0644)
+	_ = os.WriteFile(filepath.Join(paths.SysBlock, "sda", "sda1", "dev"), []byte("8:1\n"), 0644)
+	_ = os.WriteFile(filepath.Join(paths.RunUdevData, "b8:0"), []byte(
+		"S:disk/by-id/ata-Samsung_SSD_860_EVO_500GB_S3Z1NB0K123456A\n"+
+			"S:disk/by-id/wwn-0x5002538e40a0b1c2\n"+
+			"S:disk/by-path/pci-0000:00:17.0-ata-1\n"+
+			"S:disk/by-diskseq/1\n"+
+			"E:ID_SERIAL_SHORT=S3Z1NB0K123456A\n",
+	), 0644)
+	_ = os.WriteFile(filepath.Join(paths.RunUdevData, "b8:1"), []byte(
+		"S:disk/by-partuuid/8c2c2b7f-7a28-4c8e-9a50-26c2b8e2d5a1\n"+
+			"S:disk/by-uuid/0d6e4b1e-5d5c-47b4-9fd1-6a1b4c0f9c33\n"+
+			"S:disk/by-label/boot\n"+
+			"E:ID_FS_LABEL=boot\n",
+	), 0644)
+
+	aliases := diskAliases(paths, "sda")
+	if aliases == nil {
+		t.Fatalf("Expected aliases for sda, but got nil")
+	}
+	expectedByID := []string{
+		"/dev/disk/by-id/ata-Samsung_SSD_860_EVO_500GB_S3Z1NB0K123456A",
+		"/dev/disk/by-id/wwn-0x5002538e40a0b1c2",
+	}
+	if !reflect.DeepEqual(aliases.ByID, expectedByID) {
+		t.Fatalf("Expected by-id aliases %v, but got %v", expectedByID, aliases.ByID)
+	}
+	if !reflect.DeepEqual(aliases.ByPath, []string{"/dev/disk/by-path/pci-0000:00:17.0-ata-1"}) {
+		t.Fatalf("Unexpected by-path aliases %v", aliases.ByPath)
+	}
+
+	aliases = partitionAliases(paths, "sda", "sda1")
+	if aliases == nil {
+		t.Fatalf("Expected aliases for sda1, but got nil")
+	}
+	if !reflect.DeepEqual(aliases.ByPartUUID, []string{"/dev/disk/by-partuuid/8c2c2b7f-7a28-4c8e-9a50-26c2b8e2d5a1"}) {
+		t.Fatalf("Unexpected by-partuuid aliases %v", aliases.ByPartUUID)
+	}
+	if !reflect.DeepEqual(aliases.ByUUID, []string{"/dev/disk/by-uuid/0d6e4b1e-5d5c-47b4-9fd1-6a1b4c0f9c33"}) {
+		t.Fatalf("Unexpected by-uuid aliases %v", aliases.ByUUID)
+	}
+	if !reflect.DeepEqual(aliases.ByLabel, []string{"/dev/disk/by-label/boot"}) {
+		t.Fatalf("Unexpected by-label aliases %v", aliases.ByLabel)
+	}
+
+	// Check nil aliases if the device has no udev data
+	if aliases = partitionAliases(paths, "sda", "sda2"); aliases != nil {
+		t.Fatalf("Expected nil aliases for sda2, but got %v", aliases)
+	}
+}
+
 // TestLoopDevicesWithOption tests to see if we find loop devices when the option is activated
 func TestLoopDevicesWithOption(t *testing.T) {
 	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_BLOCK"); ok {
@@ -365,3 +623,129 @@ func TestLoopDevicesWithOption(t *testing.T) {
 		t.Fatalf("got partition %s but expected %s", foundDisk.Partitions[0], loopPartitionName)
 	}
 }
+
+func TestVirtualDevices(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_BLOCK"); ok {
+		t.Skip("Skipping block tests.")
+	}
+	baseDir, _ := os.MkdirTemp("", "test")
+	defer os.RemoveAll(baseDir)
+	ctx := ghwcontext.New(ghwcontext.WithRootMountpoint(baseDir))
+	paths := ghwpath.New(ctx)
+
+	_ = os.MkdirAll(paths.SysBlock, 0755)
+
+	// A loop device backing a snap
+	loopDir := filepath.Join(paths.SysBlock, "loop3", "loop")
+	_ = os.MkdirAll(loopDir, 0755)
+	_ = os.WriteFile(filepath.Join(loopDir, "backing_file"), []byte("/var/lib/snapd/snaps/core22_1122.snap\n"), 0644)
+	_ = os.WriteFile(filepath.Join(loopDir, "offset"), []byte("1048576\n"), 0644)
+	_ = os.WriteFile(filepath.Join(loopDir, "sizelimit"), []byte("0\n"), 0644)
+	_ = os.WriteFile(filepath.Join(loopDir, "autoclear"), []byte("1\n"), 0644)
+	_ = os.WriteFile(filepath.Join(loopDir, "dio"), []byte("0\n"), 0644)
+
+	loop := diskLoopDevice(paths, "loop3")
+	if loop == nil {
+		t.Fatalf("Expected loop device information for loop3, but got nil")
+	}
+	if loop.BackingFile != "/var/lib/snapd/snaps/core22_1122.snap" || loop.OffsetBytes != 1048576 {
+		t.Fatalf("Unexpected loop device information: %s", loop)
+	}
+	if !loop.IsAutoClear || loop.IsDirectIO || loop.IsPartScan {
+		t.Fatalf("Unexpected loop device flags: %+v", loop)
+	}
+	if loop = diskLoopDevice(paths, "loop4"); loop != nil {
+		t.Fatalf("Expected nil for a detached loop device, but got %s", loop)
+	}
+
+	// A zram swap device
+	zramDir := filepath.Join(paths.SysBlock, "zram0")
+	_ = os.MkdirAll(zramDir, 0755)
+	_ = os.WriteFile(filepath.Join(zramDir, "comp_algorithm"), []byte("lzo [lzo-rle] lz4 zstd\n"), 0644)
+	_ = os.WriteFile(filepath.Join(zramDir, "mm_stat"), []byte("  4194304   1048576   1310720        0   1310720       12        0        0        0\n"), 0644)
+
+	zram := diskZRAMDevice(paths, "zram0")
+	if zram == nil {
+		t.Fatalf("Expected zram device information for zram0, but got nil")
+	}
+	if zram.Algorithm != "lzo-rle" {
+		t.Fatalf("Expected algorithm lzo-rle, but got %s", zram.Algorithm)
+	}
+	if zram.OriginalDataSizeBytes != 4194304 || zram.CompressedDataSizeBytes != 1048576 || zram.MemoryUsedBytes != 1310720 {
+		t.Fatalf("Unexpected zram device information: %+v", zram)
+	}
+
+	// A Ceph RBD image snapshot
+	rbdDir := filepath.Join(paths.SysBusRBDDevices, "0")
+	_ = os.MkdirAll(rbdDir, 0755)
+	_ = os.WriteFile(filepath.Join(rbdDir, "pool"), []byte("volumes\n"), 0644)
+	_ = os.WriteFile(filepath.Join(rbdDir, "name"), []byte("vm-100-disk-0\n"), 0644)
+	_ = os.WriteFile(filepath.Join(rbdDir, "current_snap"), []byte("backup\n"), 0644)
+
+	target := diskNetworkTarget(paths, "rbd0")
+	if target == nil || target.Protocol != "rbd" || target.Target != "volumes/vm-100-disk-0@backup" {
+		t.Fatalf("Unexpected network target for rbd0: %v", target)
+	}
+
+	// An iSCSI LUN
+	devicesDir := filepath.Join(filepath.Dir(paths.SysBlock), "devices")
+	sessionPath := filepath.Join("platform", "host3", "session1")
+	lunPath := filepath.Join(sessionPath, "target3:0:0", "3:0:0:1", "block", "sdb")
+	_ = os.MkdirAll(filepath.Join(devicesDir, lunPath), 0755)
+	_ = os.MkdirAll(filepath.Join(devicesDir, sessionPath, "connection3:0"), 0755)
+	_ = os.Symlink(filepath.Join("..", "devices", lunPath), filepath.Join(paths.SysBlock, "sdb"))
+	_ = os.MkdirAll(filepath.Join(paths.SysClassISCSISession, "session1"), 0755)
+	_ = os.Symlink(filepath.Join(devicesDir, sessionPath), filepath.Join(paths.SysClassISCSISession, "session1", "device"))
+	_ = os.WriteFile(filepath.Join(paths.SysClassISCSISession, "session1", "targetname"), []byte("iqn.2003-01.org.linux-iscsi.storage:sn.0123456789ab\n"), 0644)
+	connDir := filepath.Join(paths.SysClassISCSIConn, "connection3:0")
+	_ = os.MkdirAll(connDir, 0755)
+	_ = os.WriteFile(filepath.Join(connDir, "persistent_address"), []byte("192.0.2.10\n"), 0644)
+	_ = os.WriteFile(filepath.Join(connDir, "persistent_port"), []byte("3260\n"), 0644)
+
+	target = diskNetworkTarget(paths, "sdb")
+	if target == nil {
+		t.Fatalf("Expected network target for sdb, but got nil")
+	}
+	if target.Protocol != "iscsi" || target.Target != "iqn.2003-01.org.linux-iscsi.storage:sn.0123456789ab" || target.Address != "192.0.2.10:3260" {
+		t.Fatalf("Unexpected network target for sdb: %s", target)
+	}
+}
+
+func TestExcludeVirtualDisks(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_BLOCK"); ok {
+		t.Skip("Skipping block tests.")
+	}
+	baseDir, _ := os.MkdirTemp("", "test")
+	defer os.RemoveAll(baseDir)
+	ctx := ghwcontext.New(
+		ghwcontext.WithRootMountpoint(baseDir),
+		ghwcontext.WithDisableWarnings(),
+		ghwcontext.WithDisableExternalTools(),
+	)
+	paths := ghwpath.New(ctx)
+
+	_ = os.MkdirAll(paths.SysBlock, 0755)
+	for _, disk := range []string{"vda", "loop0"} {
+		_ = os.Mkdir(filepath.Join(paths.SysBlock, disk), 0755)
+		_ = os.WriteFile(filepath.Join(paths.SysBlock, disk, "size"), []byte("2048\n"), 0644)
+	}
+
+	info, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error creating block.Info, but got %v", err)
+	}
+	if len(info.Disks) != 2 || info.TotalSizeBytes != 2*2048*sectorSize {
+		t.Fatalf("Expected 2 disks totalling %d bytes, but got %d disks totalling %d bytes", 2*2048*sectorSize, len(info.Disks), info.TotalSizeBytes)
+	}
+
+	info, err = New(ghwcontext.WithExcludeVirtualDisks()(ctx))
+	if err != nil {
+		t.Fatalf("Expected no error creating block.Info, but got %v", err)
+	}
+	if len(info.Disks) != 1 || info.Disks[0].Name != "vda" {
+		t.Fatalf("Expected only vda, but got %v", info.Disks)
+	}
+	if info.TotalSizeBytes != 2048*sectorSize {
+		t.Fatalf("Expected %d total bytes, but got %d", 2048*sectorSize, info.TotalSizeBytes)
+	}
+}
@@ -140,6 +140,56 @@ func TestBlockUnmarshal(t *testing.T) {
 	}
 }
 
+func TestInfoLookups(t *testing.T) {
+	boot := &block.Partition{
+		Name:       "sda1",
+		MountPoint: "/boot",
+		UUID:       "8c2c2b7f-7a28-4c8e-9a50-26c2b8e2d5a1",
+	}
+	root := &block.Partition{
+		Name:       "sda2",
+		MountPoint: "/",
+		UUID:       "f4a1a1f6-3c6f-4c2b-a07b-2d9b7e3c1e0f",
+		Aliases: &block.DeviceAliases{
+			ByUUID: []string{"/dev/disk/by-uuid/0d6e4b1e-5d5c-47b4-9fd1-6a1b4c0f9c33"},
+		},
+	}
+	sda := &block.Disk{
+		Name:         "sda",
+		SerialNumber: "S3Z1NB0K123456A",
+		Partitions:   []*block.Partition{boot, root},
+	}
+	sdb := &block.Disk{
+		Name:         "sdb",
+		SerialNumber: "unknown",
+	}
+	info := &block.Info{
+		Disks: []*block.Disk{sda, sdb},
+	}
+
+	if d := info.DiskBySerial("S3Z1NB0K123456A"); d != sda {
+		t.Fatalf("Expected to find sda by serial, but got %v", d)
+	}
+	if d := info.DiskBySerial("unknown"); d != nil {
+		t.Fatalf("Expected no disk for an unknown serial, but got %v", d)
+	}
+	if p := info.PartitionByUUID("8C2C2B7F-7A28-4C8E-9A50-26C2B8E2D5A1"); p != boot {
+		t.Fatalf("Expected to find sda1 by partition UUID, but got %v", p)
+	}
+	if p := info.PartitionByUUID("0d6e4b1e-5d5c-47b4-9fd1-6a1b4c0f9c33"); p != root {
+		t.Fatalf("Expected to find sda2 by filesystem UUID, but got %v", p)
+	}
+	if p := info.PartitionByUUID("00000000-0000-0000-0000-000000000000"); p != nil {
+		t.Fatalf("Expected no partition for a missing UUID, but got %v", p)
+	}
+	if p := info.PartitionByMountPoint("/boot/"); p != boot {
+		t.Fatalf("Expected to find sda1 mounted at /boot, but got %v", p)
+	}
+	if p := info.PartitionByMountPoint("/home"); p != nil {
+		t.Fatalf("Expected no partition mounted at /home, but got %v", p)
+	}
+}
+
 func findDiskByName(disks []*block.Disk, name string) *block.Disk {
 	for _, disk := range disks {
 		if disk.Name == name {
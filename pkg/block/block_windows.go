@@ -268,6 +268,8 @@ func toStorageController(interfaceType string) StorageController {
 		storageController = StorageControllerSCSI
 	case "IDE":
 		storageController = StorageControllerIDE
+	case "USB":
+		storageController = StorageControllerUSB
 	default:
 		storageController = StorageControllerUnknown
 	}
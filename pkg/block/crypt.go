@@ -0,0 +1,110 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"fmt"
+
+	"github.com/go-hardware/ghw/pkg/util"
+)
+
+const (
+	// EncryptionFormatLUKS1 is the Format of a LUKS version 1 volume
+	EncryptionFormatLUKS1 = "luks1"
+	// EncryptionFormatLUKS2 is the Format of a LUKS version 2 volume
+	EncryptionFormatLUKS2 = "luks2"
+	// EncryptionFormatPlain is the Format of a plain dm-crypt mapping, which
+	// has no on-disk header
+	EncryptionFormatPlain = "plain"
+)
+
+// Encryption describes a dm-crypt encrypted volume. The same Encryption
+// struct is referenced by the partition (or disk) holding the encrypted data
+// and, when the volume is unlocked, by the device-mapper disk exposing the
+// decrypted data.
+type Encryption struct {
+	// Format is the format of the encrypted volume: "luks1", "luks2" or
+	// "plain"
+	Format string `json:"format"`
+	// Cipher is the cipher specification of the volume, e.g.
+	// "aes-xts-plain64", or empty if it could not be read
+	Cipher string `json:"cipher,omitempty"`
+	// UUID is the UUID stored in the LUKS header, or empty for plain dm-crypt
+	// volumes
+	UUID string `json:"uuid,omitempty"`
+	// BackingDevice is the name of the partition or disk holding the
+	// encrypted data, e.g. "sda2"
+	BackingDevice string `json:"backing_device"`
+	// MappedDevice is the kernel name of the device-mapper disk exposing the
+	// decrypted data, e.g. "dm-0", or empty if the volume is not unlocked
+	MappedDevice string `json:"mapped_device,omitempty"`
+	// MapperName is the device-mapper name of the unlocked volume, i.e. the
+	// name of its /dev/mapper entry, or empty if the volume is not unlocked
+	MapperName string `json:"mapper_name,omitempty"`
+}
+
+// IsOpen returns true if the encrypted volume is unlocked.
+func (e *Encryption) IsOpen() bool {
+	return e.MappedDevice != ""
+}
+
+// String returns a short string with information about the encrypted volume.
+func (e *Encryption) String() string {
+	cipher := ""
+	if e.Cipher != "" {
+		cipher = " cipher=" + e.Cipher
+	}
+	mapped := ""
+	if e.IsOpen() {
+		mapped = fmt.Sprintf(" mapped=%s(%s)", e.MappedDevice, e.MapperName)
+	}
+	return fmt.Sprintf(
+		"encrypted %s backing=%s%s",
+		e.Format,
+		e.BackingDevice,
+		util.ConcatStrings(
+			cipher,
+			mapped,
+		),
+	)
+}
+
+// Swap describes an active swap area.
+type Swap struct {
+	// Path is the path of the swap device or file, e.g. "/dev/sda3" or
+	// "/swapfile"
+	Path string `json:"path"`
+	// Type is "partition" for swap devices and "file" for swap files
+	Type string `json:"type"`
+	// SizeBytes is the size of the swap area
+	SizeBytes uint64 `json:"size_bytes"`
+	// UsedBytes is the amount of the swap area in use
+	UsedBytes uint64 `json:"used_bytes"`
+	// Priority is the swap priority. Swap areas with a higher priority are
+	// used first.
+	Priority int `json:"priority"`
+	// IsEncrypted indicates the swap area is stored on a dm-crypt volume,
+	// either directly or through further device-mapper layers such as LVM
+	IsEncrypted bool `json:"encrypted"`
+}
+
+// String returns a short string with information about the swap area.
+func (s *Swap) String() string {
+	encrypted := ""
+	if s.IsEncrypted {
+		encrypted = " encrypted=true"
+	}
+	return fmt.Sprintf(
+		"swap %s (%s) size=%d used=%d priority=%d%s",
+		s.Path,
+		s.Type,
+		s.SizeBytes,
+		s.UsedBytes,
+		s.Priority,
+		encrypted,
+	)
+}
@@ -0,0 +1,393 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	ghwcontext "github.com/go-hardware/ghw/pkg/context"
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+	"github.com/go-hardware/ghw/pkg/util"
+)
+
+// The LUKS on-disk format is described in
+// https://gitlab.com/cryptsetup/cryptsetup/-/wikis/LUKS-standard/on-disk-format.pdf
+// and https://gitlab.com/cryptsetup/LUKS2-docs/-/blob/main/luks2_doc_wip.pdf
+const (
+	luksMagic = "LUKS\xba\xbe"
+	// offsets into the LUKS1 header
+	luks1CipherNameOffset = 8
+	luks1CipherModeOffset = 40
+	luks1FieldSize        = 32
+	// offset of the UUID in both the LUKS1 header and the LUKS2 binary header
+	luksUUIDOffset = 168
+	luksUUIDSize   = 40
+	// offset of the header size in the LUKS2 binary header
+	luks2HeaderSizeOffset = 8
+	// the LUKS2 binary header is followed by the JSON metadata area
+	luks2BinaryHeaderSize = 4096
+	// the largest LUKS2 header size allowed by the specification
+	luks2MaxHeaderSize = 4 * 1024 * 1024
+)
+
+// luks2Metadata is the part of the LUKS2 JSON metadata area we care about
+type luks2Metadata struct {
+	Segments map[string]struct {
+		Type       string `json:"type"`
+		Encryption string `json:"encryption"`
+	} `json:"segments"`
+}
+
+// parseLUKSHeader parses the supplied LUKS header, which must contain at
+// least the LUKS1 header or, for LUKS2, the binary header and JSON metadata
+// area, into an Encryption struct.
+func parseLUKSHeader(data []byte) (*Encryption, error) {
+	if len(data) < luksUUIDOffset+luksUUIDSize {
+		return nil, fmt.Errorf("LUKS header too short: %d bytes", len(data))
+	}
+	if string(data[:len(luksMagic)]) != luksMagic {
+		return nil, errors.New("no LUKS magic found")
+	}
+	enc := &Encryption{
+		UUID: cString(data[luksUUIDOffset : luksUUIDOffset+luksUUIDSize]),
+	}
+	version := binary.BigEndian.Uint16(data[len(luksMagic):])
+	switch version {
+	case 1:
+		enc.Format = EncryptionFormatLUKS1
+		cipherName := cString(data[luks1CipherNameOffset : luks1CipherNameOffset+luks1FieldSize])
+		cipherMode := cString(data[luks1CipherModeOffset : luks1CipherModeOffset+luks1FieldSize])
+		if cipherName != "" && cipherMode != "" {
+			enc.Cipher = cipherName + "-" + cipherMode
+		}
+	case 2:
+		enc.Format = EncryptionFormatLUKS2
+		if len(data) <= luks2BinaryHeaderSize {
+			// no JSON metadata area, so we can't tell the cipher
+			return enc, nil
+		}
+		var meta luks2Metadata
+		if err := json.Unmarshal(bytes.TrimRight(data[luks2BinaryHeaderSize:], "\x00"), &meta); err != nil {
+			return nil, fmt.Errorf("failed to parse LUKS2 metadata: %s", err)
+		}
+		// The first segment is "0". Reencryption in progress may add
+		// further segments but they share the volume's cipher.
+		if seg, ok := meta.Segments["0"]; ok && seg.Type == "crypt" {
+			enc.Cipher = seg.Encryption
+		}
+	default:
+		return nil, fmt.Errorf("unknown LUKS version %d", version)
+	}
+	return enc, nil
+}
+
+// cString returns the NUL-terminated string at the start of the supplied bytes
+func cString(b []byte) string {
+	if idx := bytes.IndexByte(b, 0); idx >= 0 {
+		b = b[:idx]
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// readLUKSHeader reads and parses the LUKS header of the block device with
+// the supplied name. Reading the device usually requires root privileges.
+func readLUKSHeader(paths *ghwpath.Paths, devName string) (*Encryption, error) {
+	f, err := os.Open(filepath.Join(paths.Dev, devName))
+	if err != nil {
+		return nil, err
+	}
+	defer util.SafeClose(f)
+
+	data := make([]byte, luks2BinaryHeaderSize)
+	if _, err = io.ReadFull(f, data); err != nil {
+		return nil, err
+	}
+	if string(data[:len(luksMagic)]) == luksMagic && binary.BigEndian.Uint16(data[len(luksMagic):]) == 2 {
+		hdrSize := binary.BigEndian.Uint64(data[luks2HeaderSizeOffset:])
+		if hdrSize > luks2BinaryHeaderSize && hdrSize <= luks2MaxHeaderSize {
+			full := make([]byte, hdrSize)
+			copy(full, data)
+			if _, err = io.ReadFull(f, full[luks2BinaryHeaderSize:]); err != nil {
+				return nil, err
+			}
+			data = full
+		}
+	}
+	return parseLUKSHeader(data)
+}
+
+// luksEncryption returns an Encryption struct for the LUKS volume on the
+// supplied partition (or whole disk), or nil if the device does not contain a
+// LUKS volume. The udev database tells us the format and UUID of the volume
+// without needing privileges or touching the device. The header is only read
+// for the cipher if LUKS headers were requested with the EnableCryptHeaders
+// option.
+func luksEncryption(ctx context.Context, paths *ghwpath.Paths, devName string, udevInfo map[string]string) *Encryption {
+	if udevInfo["ID_FS_TYPE"] != "crypto_LUKS" {
+		return nil
+	}
+	enc := &Encryption{
+		BackingDevice: devName,
+		UUID:          udevInfo["ID_FS_UUID"],
+	}
+	switch udevInfo["ID_FS_VERSION"] {
+	case "1":
+		enc.Format = EncryptionFormatLUKS1
+	case "2":
+		enc.Format = EncryptionFormatLUKS2
+	}
+	opts := ghwcontext.OptionsFromContext(ctx)
+	if opts.EnableCryptHeaders == nil || !*opts.EnableCryptHeaders {
+		return enc
+	}
+	hdr, err := readLUKSHeader(paths, devName)
+	if err != nil {
+		if !errors.Is(err, os.ErrPermission) {
+			ghwcontext.Warn(ctx, "failed to read LUKS header of %s: %s\n", devName, err)
+		}
+		return enc
+	}
+	enc.Format = hdr.Format
+	enc.Cipher = hdr.Cipher
+	if enc.UUID == "" {
+		enc.UUID = hdr.UUID
+	}
+	return enc
+}
+
+// dmCryptEncryption returns an Encryption struct describing the dm-crypt
+// mapping exposed by the supplied device-mapper disk, or nil if the disk is
+// not a dm-crypt mapping.
+func dmCryptEncryption(paths *ghwpath.Paths, disk string) *Encryption {
+	dmDir := filepath.Join(paths.SysBlock, disk, "dm")
+	// cryptsetup sets the device-mapper UUID to
+	// CRYPT-<FORMAT>-<LUKS UUID without dashes>-<name> for LUKS volumes and
+	// CRYPT-PLAIN-<name> for plain dm-crypt mappings
+	uuid := util.ReadTrimmedFile(filepath.Join(dmDir, "uuid"))
+	if !strings.HasPrefix(uuid, "CRYPT-") {
+		return nil
+	}
+	enc := &Encryption{
+		MappedDevice: disk,
+		MapperName:   util.ReadTrimmedFile(filepath.Join(dmDir, "name")),
+	}
+	format := strings.SplitN(strings.TrimPrefix(uuid, "CRYPT-"), "-", 2)[0]
+	switch strings.ToUpper(format) {
+	case "LUKS1":
+		enc.Format = EncryptionFormatLUKS1
+	case "LUKS2":
+		enc.Format = EncryptionFormatLUKS2
+	case "PLAIN":
+		enc.Format = EncryptionFormatPlain
+	default:
+		enc.Format = strings.ToLower(format)
+	}
+	if slaves, err := os.ReadDir(filepath.Join(paths.SysBlock, disk, "slaves")); err == nil && len(slaves) > 0 {
+		enc.BackingDevice = slaves[0].Name()
+	}
+	return enc
+}
+
+// diskFillEncryption sets the Encryption field of the dm-crypt disks and of
+// the partitions and disks holding encrypted volumes, linking each unlocked
+// volume to its device-mapper disk.
+func diskFillEncryption(ctx context.Context, paths *ghwpath.Paths, disks []*Disk) {
+	// First gather the active dm-crypt mappings, keyed by backing device
+	mappings := map[string]*Encryption{}
+	for _, d := range disks {
+		if !strings.HasPrefix(d.Name, "dm-") {
+			continue
+		}
+		if enc := dmCryptEncryption(paths, d.Name); enc != nil {
+			d.Encryption = enc
+			if enc.BackingDevice != "" {
+				mappings[enc.BackingDevice] = enc
+			}
+		}
+	}
+
+	fill := func(devName string, udevInfo map[string]string) *Encryption {
+		enc := luksEncryption(ctx, paths, devName, udevInfo)
+		mapping, mapped := mappings[devName]
+		switch {
+		case enc == nil && !mapped:
+			return nil
+		case enc == nil:
+			// plain dm-crypt, which has no header
+			return mapping
+		case mapped:
+			// merge what we read from the header into the mapping so that
+			// both sides refer to the same Encryption
+			mapping.Format = enc.Format
+			mapping.UUID = enc.UUID
+			if enc.Cipher != "" {
+				mapping.Cipher = enc.Cipher
+			}
+			return mapping
+		}
+		return enc
+	}
+	for _, d := range disks {
+		if d.Encryption == nil {
+			udevInfo, _ := udevInfoDisk(paths, d.Name)
+			d.Encryption = fill(d.Name, udevInfo)
+		}
+		for _, p := range d.Partitions {
+			udevInfo, _ := udevInfoPartition(paths, d.Name, p.Name)
+			p.Encryption = fill(p.Name, udevInfo)
+		}
+	}
+}
+
+// blockDeviceIsEncrypted returns true if the block device with the supplied
+// name is a dm-crypt mapping or is stacked on top of one, e.g. an LVM logical
+// volume on a LUKS volume.
+func blockDeviceIsEncrypted(paths *ghwpath.Paths, devName string, depth int) bool {
+	if depth > 8 {
+		// device-mapper stacks are never this deep, bail out of any loop
+		return false
+	}
+	if strings.HasPrefix(util.ReadTrimmedFile(filepath.Join(paths.SysBlock, devName, "dm", "uuid")), "CRYPT-") {
+		return true
+	}
+	slaves, err := os.ReadDir(filepath.Join(paths.SysBlock, devName, "slaves"))
+	if err != nil {
+		return false
+	}
+	for _, slave := range slaves {
+		if blockDeviceIsEncrypted(paths, slave.Name(), depth+1) {
+			return true
+		}
+	}
+	return false
+}
+
+// blockDeviceName returns the kernel name of the block device at the supplied
+// /dev path, resolving /dev/mapper names to their dm-N disk.
+func blockDeviceName(paths *ghwpath.Paths, devPath string) string {
+	if !strings.HasPrefix(devPath, "/dev/mapper/") {
+		return filepath.Base(devPath)
+	}
+	mapperName := strings.TrimPrefix(devPath, "/dev/mapper/")
+	dmDirs, err := filepath.Glob(filepath.Join(paths.SysBlock, "dm-*"))
+	if err != nil {
+		return ""
+	}
+	for _, dmDir := range dmDirs {
+		if util.ReadTrimmedFile(filepath.Join(dmDir, "dm", "name")) == mapperName {
+			return filepath.Base(dmDir)
+		}
+	}
+	return ""
+}
+
+// swaps returns the active swap areas listed in /proc/swaps
+func swaps(ctx context.Context, paths *ghwpath.Paths) []*Swap {
+	f, err := os.Open(paths.ProcSwaps)
+	if err != nil {
+		ghwcontext.Warn(ctx, "failed to read swap areas: %s\n", err)
+		return nil
+	}
+	defer util.SafeClose(f)
+
+	out := make([]*Swap, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		swap := parseSwapsEntry(scanner.Text())
+		if swap == nil {
+			continue
+		}
+		devPath := swap.Path
+		if swap.Type == "file" {
+			// A swap file is encrypted if the filesystem holding it is
+			devPath = mountDeviceForPath(paths, swap.Path)
+		}
+		if devPath != "" {
+			if devName := blockDeviceName(paths, devPath); devName != "" {
+				swap.IsEncrypted = blockDeviceIsEncrypted(paths, devName, 0)
+			}
+		}
+		out = append(out, swap)
+	}
+	return out
+}
+
+// parseSwapsEntry parses a line of /proc/swaps, which look like this:
+//
+//	Filename				Type		Size		Used		Priority
+//	/dev/dm-1                               partition	8388604		0		-2
+//
+// Sizes are in KiB. Returns nil for the header line and malformed lines.
+func parseSwapsEntry(line string) *Swap {
+	fields := strings.Fields(line)
+	if len(fields) < 5 || !strings.HasPrefix(fields[0], "/") {
+		return nil
+	}
+	size, err := strconv.ParseUint(fields[2], 10, 64)
+	if err != nil {
+		return nil
+	}
+	used, err := strconv.ParseUint(fields[3], 10, 64)
+	if err != nil {
+		return nil
+	}
+	prio, err := strconv.Atoi(fields[4])
+	if err != nil {
+		return nil
+	}
+	return &Swap{
+		Path:      unescapeMountField(fields[0]),
+		Type:      fields[1],
+		SizeBytes: size * 1024,
+		UsedBytes: used * 1024,
+		Priority:  prio,
+	}
+}
+
+// mountDeviceForPath returns the device of the mount holding the supplied
+// path, or "" if it could not be determined
+func mountDeviceForPath(paths *ghwpath.Paths, path string) string {
+	f, err := os.Open(paths.ProcMounts)
+	if err != nil {
+		return ""
+	}
+	defer util.SafeClose(f)
+
+	device := ""
+	longest := -1
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		entry := parseMountEntry(line)
+		if entry == nil {
+			continue
+		}
+		mp := entry.Mountpoint
+		if mp != "/" && path != mp && !strings.HasPrefix(path, mp+"/") {
+			continue
+		}
+		// later mounts over the same mountpoint shadow earlier ones
+		if len(mp) >= longest {
+			longest = len(mp)
+			device = entry.Partition
+		}
+	}
+	return device
+}
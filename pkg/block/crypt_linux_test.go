@@ -0,0 +1,243 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+//go:build linux
+// +build linux
+
+package block
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ghwcontext "github.com/go-hardware/ghw/pkg/context"
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+)
+
+const testLUKSUUID = "0f3e5d2a-8c1b-4b7e-9a6d-2c4f1e0b3a59"
+
+func makeLUKS1Header() []byte {
+	hdr := make([]byte, 592)
+	copy(hdr, luksMagic)
+	binary.BigEndian.PutUint16(hdr[6:], 1)
+	copy(hdr[luks1CipherNameOffset:], "aes")
+	copy(hdr[luks1CipherModeOffset:], "cbc-essiv:sha256")
+	copy(hdr[luksUUIDOffset:], testLUKSUUID)
+	return hdr
+}
+
+func makeLUKS2Header() []byte {
+	hdr := make([]byte, 16384)
+	copy(hdr, luksMagic)
+	binary.BigEndian.PutUint16(hdr[6:], 2)
+	binary.BigEndian.PutUint64(hdr[luks2HeaderSizeOffset:], uint64(len(hdr)))
+	copy(hdr[luksUUIDOffset:], testLUKSUUID)
+	copy(hdr[luks2BinaryHeaderSize:], `{"keyslots":{},"tokens":{},"segments":{"0":{"type":"crypt","offset":"16777216","size":"dynamic","iv_tweak":"0","encryption":"aes-xts-plain64","sector_size":512}},"digests":{},"config":{"json_size":"12288","keyslots_size":"16744448"}}`)
+	return hdr
+}
+
+func TestParseLUKSHeader(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_BLOCK"); ok {
+		t.Skip("Skipping block tests.")
+	}
+
+	tests := []struct {
+		name   string
+		hdr    []byte
+		format string
+		cipher string
+	}{
+		{
+			name:   "LUKS1",
+			hdr:    makeLUKS1Header(),
+			format: EncryptionFormatLUKS1,
+			cipher: "aes-cbc-essiv:sha256",
+		},
+		{
+			name:   "LUKS2",
+			hdr:    makeLUKS2Header(),
+			format: EncryptionFormatLUKS2,
+			cipher: "aes-xts-plain64",
+		},
+	}
+	for _, test := range tests {
+		enc, err := parseLUKSHeader(test.hdr)
+		if err != nil {
+			t.Fatalf("For %s, expected nil err, but got %v", test.name, err)
+		}
+		if enc.Format != test.format {
+			t.Fatalf("For %s, expected format %s, but got %s", test.name, test.format, enc.Format)
+		}
+		if enc.Cipher != test.cipher {
+			t.Fatalf("For %s, expected cipher %s, but got %s", test.name, test.cipher, enc.Cipher)
+		}
+		if enc.UUID != testLUKSUUID {
+			t.Fatalf("For %s, expected UUID %s, but got %s", test.name, testLUKSUUID, enc.UUID)
+		}
+	}
+
+	if _, err := parseLUKSHeader(make([]byte, 4096)); err == nil {
+		t.Fatalf("Expected an error parsing a header without LUKS magic, but got nil")
+	}
+}
+
+func TestDiskFillEncryption(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_BLOCK"); ok {
+		t.Skip("Skipping block tests.")
+	}
+	baseDir, _ := os.MkdirTemp("", "test")
+	defer os.RemoveAll(baseDir)
+	ctx := ghwcontext.New(
+		ghwcontext.WithRootMountpoint(baseDir),
+		ghwcontext.WithCryptHeaders(),
+	)
+	paths := ghwpath.New(ctx)
+
+	_ = os.MkdirAll(paths.RunUdevData, 0755)
+	_ = os.MkdirAll(paths.Dev, 0755)
+
+	// sda2 holds a LUKS2 volume unlocked as dm-0, sda3 holds a plain dm-crypt
+	// volume unlocked as dm-1 and sdb holds a locked LUKS1 volume
+	_ = os.MkdirAll(filepath.Join(paths.SysBlock, "sda", "sda2"), 0755)
+	_ = os.MkdirAll(filepath.Join(paths.SysBlock, "sda", "sda3"), 0755)
+	_ = os.WriteFile(filepath.Join(paths.SysBlock, "sda", "sda2", "dev"), []byte("8:2\n"), 0644)
+	_ = os.WriteFile(filepath.Join(paths.RunUdevData, "b8:2"), []byte("E:ID_FS_TYPE=crypto_LUKS\nE:ID_FS_VERSION=2\nE:ID_FS_UUID="+testLUKSUUID+"\n"), 0644)
+	_ = os.WriteFile(filepath.Join(paths.Dev, "sda2"), makeLUKS2Header(), 0644)
+
+	_ = os.MkdirAll(filepath.Join(paths.SysBlock, "sdb"), 0755)
+	_ = os.WriteFile(filepath.Join(paths.SysBlock, "sdb", "dev"), []byte("8:16\n"), 0644)
+	_ = os.WriteFile(filepath.Join(paths.RunUdevData, "b8:16"), []byte("E:ID_FS_TYPE=crypto_LUKS\nE:ID_FS_VERSION=1\n"), 0644)
+
+	for dm, uuid := range map[string]string{
+		"dm-0": "CRYPT-LUKS2-0f3e5d2a8c1b4b7e9a6d2c4f1e0b3a59-cryptroot",
+		"dm-1": "CRYPT-PLAIN-cryptswap",
+	} {
+		_ = os.MkdirAll(filepath.Join(paths.SysBlock, dm, "dm"), 0755)
+		_ = os.MkdirAll(filepath.Join(paths.SysBlock, dm, "slaves"), 0755)
+		_ = os.WriteFile(filepath.Join(paths.SysBlock, dm, "dm", "uuid"), []byte(uuid+"\n"), 0644)
+	}
+	_ = os.WriteFile(filepath.Join(paths.SysBlock, "dm-0", "dm", "name"), []byte("cryptroot\n"), 0644)
+	_ = os.WriteFile(filepath.Join(paths.SysBlock, "dm-1", "dm", "name"), []byte("cryptswap\n"), 0644)
+	_ = os.Mkdir(filepath.Join(paths.SysBlock, "dm-0", "slaves", "sda2"), 0755)
+	_ = os.Mkdir(filepath.Join(paths.SysBlock, "dm-1", "slaves", "sda3"), 0755)
+
+	sda2 := &Partition{Name: "sda2"}
+	sda3 := &Partition{Name: "sda3"}
+	disks := []*Disk{
+		{Name: "sda", Partitions: []*Partition{sda2, sda3}},
+		{Name: "sdb"},
+		{Name: "dm-0"},
+		{Name: "dm-1"},
+	}
+	diskFillEncryption(ctx, paths, disks)
+
+	if disks[0].Encryption != nil {
+		t.Fatalf("Expected no encryption for sda, but got %s", disks[0].Encryption)
+	}
+	enc := sda2.Encryption
+	if enc == nil {
+		t.Fatalf("Expected encryption for sda2, but got nil")
+	}
+	if enc != disks[2].Encryption {
+		t.Fatalf("Expected sda2 and dm-0 to share the same encryption information")
+	}
+	if enc.Format != EncryptionFormatLUKS2 || enc.Cipher != "aes-xts-plain64" || enc.UUID != testLUKSUUID {
+		t.Fatalf("Unexpected encryption for sda2: %+v", enc)
+	}
+	if enc.BackingDevice != "sda2" || enc.MappedDevice != "dm-0" || enc.MapperName != "cryptroot" || !enc.IsOpen() {
+		t.Fatalf("Unexpected mapping for sda2: %+v", enc)
+	}
+
+	enc = sda3.Encryption
+	if enc == nil || enc != disks[3].Encryption || enc.Format != EncryptionFormatPlain || enc.MapperName != "cryptswap" {
+		t.Fatalf("Unexpected encryption for sda3: %+v", enc)
+	}
+
+	// The header of sdb could not be read, so we rely on udev alone
+	enc = disks[1].Encryption
+	if enc == nil || enc.Format != EncryptionFormatLUKS1 || enc.Cipher != "" || enc.IsOpen() {
+		t.Fatalf("Unexpected encryption for sdb: %+v", enc)
+	}
+
+	// Unless asked to, the LUKS header is left alone
+	sda2 = &Partition{Name: "sda2"}
+	disks = []*Disk{{Name: "sda", Partitions: []*Partition{sda2}}}
+	diskFillEncryption(ghwcontext.New(ghwcontext.WithRootMountpoint(baseDir)), paths, disks)
+	enc = sda2.Encryption
+	if enc == nil || enc.Format != EncryptionFormatLUKS2 || enc.UUID != testLUKSUUID || enc.Cipher != "" {
+		t.Fatalf("Unexpected encryption for sda2 without reading its header: %+v", enc)
+	}
+}
+
+func TestSwaps(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_BLOCK"); ok {
+		t.Skip("Skipping block tests.")
+	}
+	baseDir, _ := os.MkdirTemp("", "test")
+	defer os.RemoveAll(baseDir)
+	ctx := ghwcontext.New(ghwcontext.WithRootMountpoint(baseDir))
+	paths := ghwpath.New(ctx)
+
+	_ = os.MkdirAll(filepath.Dir(paths.ProcMounts), 0755)
+	_ = os.WriteFile(paths.ProcMounts, []byte(
+		"/dev/mapper/vg0-root / ext4 rw,relatime 0 0\n"+
+			"/dev/sda1 /boot ext4 rw,relatime 0 0\n"+
+			"/dev/sdb1 /data ext4 rw,relatime 0 0\n",
+	), 0644)
+	_ = os.WriteFile(paths.ProcSwaps, []byte(
+		"Filename\t\t\t\tType\t\tSize\t\tUsed\t\tPriority\n"+
+			"/dev/dm-1                               partition\t8388604\t\t1024\t\t-2\n"+
+			"/swapfile                               file\t\t2097148\t\t0\t\t10\n"+
+			"/data/swap\\040file                      file\t\t1048572\t\t0\t\t-3\n"+
+			"/dev/zram0                              partition\t4194300\t\t0\t\t100\n",
+	), 0644)
+
+	// dm-1 is a plain dm-crypt swap, dm-2 is an LVM volume on the LUKS
+	// volume dm-0
+	for dm, uuid := range map[string]string{
+		"dm-0": "CRYPT-LUKS2-0f3e5d2a8c1b4b7e9a6d2c4f1e0b3a59-cryptlvm",
+		"dm-1": "CRYPT-PLAIN-cryptswap",
+		"dm-2": "LVM-Kq3Ue5bfXnGgGJ1HeuK4Rb8JZ4d0VvRr",
+	} {
+		_ = os.MkdirAll(filepath.Join(paths.SysBlock, dm, "dm"), 0755)
+		_ = os.MkdirAll(filepath.Join(paths.SysBlock, dm, "slaves"), 0755)
+		_ = os.WriteFile(filepath.Join(paths.SysBlock, dm, "dm", "uuid"), []byte(uuid+"\n"), 0644)
+	}
+	_ = os.WriteFile(filepath.Join(paths.SysBlock, "dm-2", "dm", "name"), []byte("vg0-root\n"), 0644)
+	_ = os.Mkdir(filepath.Join(paths.SysBlock, "dm-2", "slaves", "dm-0"), 0755)
+
+	swaps := swaps(ctx, paths)
+	if len(swaps) != 4 {
+		t.Fatalf("Expected 4 swap areas, but got %d", len(swaps))
+	}
+	tests := []struct {
+		path      string
+		swapType  string
+		sizeBytes uint64
+		usedBytes uint64
+		priority  int
+		encrypted bool
+	}{
+		{"/dev/dm-1", "partition", 8388604 * 1024, 1024 * 1024, -2, true},
+		{"/swapfile", "file", 2097148 * 1024, 0, 10, true},
+		{"/data/swap file", "file", 1048572 * 1024, 0, -3, false},
+		{"/dev/zram0", "partition", 4194300 * 1024, 0, 100, false},
+	}
+	for x, test := range tests {
+		swap := swaps[x]
+		if swap.Path != test.path || swap.Type != test.swapType {
+			t.Fatalf("Expected swap %s (%s), but got %s", test.path, test.swapType, swap)
+		}
+		if swap.SizeBytes != test.sizeBytes || swap.UsedBytes != test.usedBytes || swap.Priority != test.priority {
+			t.Fatalf("Unexpected size, usage or priority for %s: %s", test.path, swap)
+		}
+		if swap.IsEncrypted != test.encrypted {
+			t.Fatalf("For %s, expected encrypted %v, but got %v", test.path, test.encrypted, swap.IsEncrypted)
+		}
+	}
+}
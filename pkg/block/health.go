@@ -0,0 +1,233 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-hardware/ghw/pkg/util"
+)
+
+// HealthStatus describes the overall self-assessed health of a drive
+type HealthStatus int
+
+const (
+	// HealthStatusUnknown means we could not determine the health of the drive
+	HealthStatusUnknown HealthStatus = iota
+	// HealthStatusPassed indicates the drive passed its self-assessment
+	HealthStatusPassed
+	// HealthStatusFailed indicates the drive failed its self-assessment, or
+	// that one of its SMART attributes is at or below its failure threshold
+	HealthStatusFailed
+)
+
+var (
+	healthStatusString = map[HealthStatus]string{
+		HealthStatusUnknown: "Unknown",
+		HealthStatusPassed:  "Passed",
+		HealthStatusFailed:  "Failed",
+	}
+
+	// NOTE: the keys are all lowercase and do not match
+	// the keys in the opposite table `healthStatusString`.
+	// This is done because of the choice we made in
+	// HealthStatus::MarshalJSON.
+	// We use this table only in UnmarshalJSON, so it should be OK.
+	stringHealthStatus = map[string]HealthStatus{
+		"unknown": HealthStatusUnknown,
+		"passed":  HealthStatusPassed,
+		"failed":  HealthStatusFailed,
+	}
+)
+
+func (hs HealthStatus) String() string {
+	return healthStatusString[hs]
+}
+
+// NOTE: since serialized output is as "official" as we're going to
+// get, let's lowercase the string output when serializing, in order to
+// "normalize" the expected serialized output
+func (hs HealthStatus) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strings.ToLower(hs.String()))), nil
+}
+
+func (hs *HealthStatus) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	key := strings.ToLower(s)
+	val, ok := stringHealthStatus[key]
+	if !ok {
+		return fmt.Errorf("unknown health status: %q", key)
+	}
+	*hs = val
+	return nil
+}
+
+// SMARTAttribute describes a single entry in the SMART attribute table of an
+// ATA drive.
+type SMARTAttribute struct {
+	// ID is the numeric identifier of the attribute, e.g. 5 for the
+	// reallocated sector count
+	ID uint8 `json:"id"`
+	// Name is the conventional name of the attribute, e.g.
+	// "Reallocated_Sector_Ct"
+	Name string `json:"name"`
+	// IsPreFailure is true if the attribute falling to or below its
+	// threshold indicates imminent drive failure, false if it only indicates
+	// age or usage.
+	IsPreFailure bool `json:"pre_failure"`
+	// Value is the current normalized value of the attribute
+	Value uint8 `json:"value"`
+	// Worst is the worst normalized value ever recorded for the attribute
+	Worst uint8 `json:"worst"`
+	// Threshold is the normalized value at or below which the attribute is
+	// considered failing. A threshold of zero means the attribute can never
+	// fail.
+	Threshold uint8 `json:"threshold"`
+	// RawValue is the vendor-specific raw value of the attribute
+	RawValue uint64 `json:"raw_value"`
+}
+
+// IsFailing returns true if the attribute's normalized value has fallen to or
+// below its failure threshold.
+func (a *SMARTAttribute) IsFailing() bool {
+	return a.Threshold > 0 && a.Value <= a.Threshold
+}
+
+// String returns a short string with information about the SMART attribute.
+func (a *SMARTAttribute) String() string {
+	return fmt.Sprintf(
+		"%d %s value=%d worst=%d threshold=%d raw=%d",
+		a.ID,
+		a.Name,
+		a.Value,
+		a.Worst,
+		a.Threshold,
+		a.RawValue,
+	)
+}
+
+// NVMeHealthLog contains the contents of the NVMe SMART / Health Information
+// log page (log identifier 02h).
+type NVMeHealthLog struct {
+	// CriticalWarning is the bitmask of critical warnings reported by the
+	// controller. Zero means no warnings.
+	CriticalWarning uint8 `json:"critical_warning"`
+	// TemperatureCelsius is the composite temperature of the controller
+	TemperatureCelsius int `json:"temperature_celsius"`
+	// AvailableSpare is the normalized percentage of remaining spare capacity
+	AvailableSpare uint8 `json:"available_spare"`
+	// AvailableSpareThreshold is the AvailableSpare value below which the
+	// controller raises a critical warning
+	AvailableSpareThreshold uint8 `json:"available_spare_threshold"`
+	// PercentageUsed is the vendor-specific estimate of the percentage of the
+	// drive's life used. It may exceed 100.
+	PercentageUsed uint8 `json:"percentage_used"`
+	// DataUnitsRead is the number of 512-byte data units, in thousands, read
+	// by the host
+	DataUnitsRead uint64 `json:"data_units_read"`
+	// DataUnitsWritten is the number of 512-byte data units, in thousands,
+	// written by the host
+	DataUnitsWritten uint64 `json:"data_units_written"`
+	// HostReadCommands is the number of read commands completed
+	HostReadCommands uint64 `json:"host_read_commands"`
+	// HostWriteCommands is the number of write commands completed
+	HostWriteCommands uint64 `json:"host_write_commands"`
+	// ControllerBusyMinutes is the amount of time the controller has been
+	// busy with I/O commands, in minutes
+	ControllerBusyMinutes uint64 `json:"controller_busy_minutes"`
+	// PowerCycles is the number of power cycles
+	PowerCycles uint64 `json:"power_cycles"`
+	// PowerOnHours is the number of power-on hours
+	PowerOnHours uint64 `json:"power_on_hours"`
+	// UnsafeShutdowns is the number of unsafe shutdowns
+	UnsafeShutdowns uint64 `json:"unsafe_shutdowns"`
+	// MediaErrors is the number of unrecovered data integrity errors
+	MediaErrors uint64 `json:"media_errors"`
+	// ErrorLogEntries is the number of error information log entries over
+	// the life of the controller
+	ErrorLogEntries uint64 `json:"error_log_entries"`
+}
+
+// Health describes the health of a disk drive as reported by its SMART
+// (ATA/SCSI) or SMART/Health Information (NVMe) data. Numeric fields are set
+// to -1 when the drive does not report the corresponding value.
+type Health struct {
+	// Status is the overall pass/fail self-assessment of the drive
+	Status HealthStatus `json:"status"`
+	// TemperatureCelsius is the current drive temperature
+	TemperatureCelsius int `json:"temperature_celsius"`
+	// PercentageUsed is the estimated percentage of the drive's endurance
+	// that has been consumed
+	PercentageUsed int `json:"percentage_used"`
+	// PowerOnHours is the number of hours the drive has been powered on
+	PowerOnHours int64 `json:"power_on_hours"`
+	// MediaErrors is the number of unrecovered media/data integrity errors
+	MediaErrors int64 `json:"media_errors"`
+	// ReallocatedSectors is the number of sectors (or, for SCSI drives,
+	// grown defects) the drive has remapped
+	ReallocatedSectors int64 `json:"reallocated_sectors"`
+	// Source indicates where the health information was read from, either
+	// "ioctl" or "smartctl"
+	Source string `json:"source"`
+	// Attributes contains the SMART attribute table for ATA drives
+	Attributes []*SMARTAttribute `json:"attributes,omitempty"`
+	// NVMe contains the SMART / Health Information log for NVMe drives
+	NVMe *NVMeHealthLog `json:"nvme,omitempty"`
+}
+
+// AttributeByID returns the SMARTAttribute having the supplied ID, or nil if
+// the drive does not report that attribute.
+func (h *Health) AttributeByID(id uint8) *SMARTAttribute {
+	for _, a := range h.Attributes {
+		if a.ID == id {
+			return a
+		}
+	}
+	return nil
+}
+
+// String returns a short string indicating important information about the
+// drive health.
+func (h *Health) String() string {
+	temp := ""
+	if h.TemperatureCelsius >= 0 {
+		temp = fmt.Sprintf(" temperature=%dC", h.TemperatureCelsius)
+	}
+	used := ""
+	if h.PercentageUsed >= 0 {
+		used = fmt.Sprintf(" used=%d%%", h.PercentageUsed)
+	}
+	poh := ""
+	if h.PowerOnHours >= 0 {
+		poh = fmt.Sprintf(" power_on_hours=%d", h.PowerOnHours)
+	}
+	realloc := ""
+	if h.ReallocatedSectors >= 0 {
+		realloc = fmt.Sprintf(" reallocated=%d", h.ReallocatedSectors)
+	}
+	mediaErrs := ""
+	if h.MediaErrors >= 0 {
+		mediaErrs = fmt.Sprintf(" media_errors=%d", h.MediaErrors)
+	}
+	return fmt.Sprintf(
+		"health %s%s",
+		h.Status.String(),
+		util.ConcatStrings(
+			temp,
+			used,
+			poh,
+			realloc,
+			mediaErrs,
+		),
+	)
+}
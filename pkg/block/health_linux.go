@@ -0,0 +1,784 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	ghwcontext "github.com/go-hardware/ghw/pkg/context"
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+)
+
+const (
+	warnSmartctlNotInstalled = `smartctl not installed. Cannot grab drive health for %s: %s
+`
+)
+
+const (
+	healthSourceIoctl    = "ioctl"
+	healthSourceSmartctl = "smartctl"
+)
+
+const (
+	// NVME_IOCTL_ADMIN_CMD, i.e. _IOWR('N', 0x41, struct nvme_admin_cmd)
+	nvmeIoctlAdminCmd      = 0xC0484E41
+	nvmeAdminGetLogPage    = 0x02
+	nvmeLogSMARTHealth     = 0x02
+	nvmeNSIDAll            = 0xFFFFFFFF
+	nvmeSMARTHealthLogSize = 512
+
+	// SG_IO, see include/scsi/sg.h
+	sgIO           = 0x2285
+	sgDxferNone    = -1
+	sgDxferFromDev = -3
+	sgTimeoutMS    = 5000
+	sgDriverSense  = 0x08
+
+	// SCSI status returned along with sense data
+	scsiCheckCondition = 0x02
+
+	// ATA PASS-THROUGH (16), see the SCSI / ATA Translation (SAT) standard
+	ataPassThrough16       = 0x85
+	ataCheckPowerMode      = 0xE5
+	ataSMART               = 0xB0
+	ataSMARTReadData       = 0xD0
+	ataSMARTReadThresholds = 0xD1
+	ataSMARTDataSize       = 512
+	ataSMARTNumAttributes  = 30
+	ataSMARTAttributeSize  = 12
+)
+
+// Well-known ATA SMART attribute IDs
+const (
+	ataAttrReallocatedSectors = 5
+	ataAttrPowerOnHours       = 9
+	ataAttrReportedUncorrect  = 187
+	ataAttrAirflowTemperature = 190
+	ataAttrTemperatureCelsius = 194
+)
+
+const (
+	ataAttrUnknownAttributeStr = "Unknown_Attribute"
+)
+
+// ATA power modes reported by the CHECK POWER MODE command. Every mode below
+// ataPowerModeIdle means the drive is in one of its standby states, with its
+// media spun down.
+const (
+	ataPowerModeIdle = 0x80
+)
+
+// smartctl sets this bit of its exit status when the device could not be
+// opened or is in a low-power mode, see the smartctl(8) man page
+const smartctlExitDeviceUnavailable = 1 << 1
+
+// errDiskUnavailable is returned when the health of a drive may not be
+// queried right now, e.g. because it is spun down, and is not worth a warning
+var errDiskUnavailable = errors.New("disk unavailable")
+
+// ataAttributeNames contains the conventional (smartmontools) names of the
+// most common ATA SMART attributes. Attribute meanings are vendor-specific, so
+// this is only a best-effort labelling.
+var ataAttributeNames = map[uint8]string{
+	1:   "Raw_Read_Error_Rate",
+	3:   "Spin_Up_Time",
+	4:   "Start_Stop_Count",
+	5:   "Reallocated_Sector_Ct",
+	7:   "Seek_Error_Rate",
+	9:   "Power_On_Hours",
+	10:  "Spin_Retry_Count",
+	12:  "Power_Cycle_Count",
+	177: "Wear_Leveling_Count",
+	183: "Runtime_Bad_Block",
+	184: "End-to-End_Error",
+	187: "Reported_Uncorrect",
+	188: "Command_Timeout",
+	190: "Airflow_Temperature_Cel",
+	192: "Power-Off_Retract_Count",
+	193: "Load_Cycle_Count",
+	194: "Temperature_Celsius",
+	196: "Reallocated_Event_Count",
+	197: "Current_Pending_Sector",
+	198: "Offline_Uncorrectable",
+	199: "UDMA_CRC_Error_Count",
+	231: "SSD_Life_Left",
+	233: "Media_Wearout_Indicator",
+	241: "Total_LBAs_Written",
+	242: "Total_LBAs_Read",
+}
+
+// diskHealth returns the health of the disk with the supplied name if drive
+// health was requested with the EnableDiskHealth option. We first try to
+// query the drive natively using ioctl(2) and, if that is not possible, fall
+// back to the smartctl program (unless the use of external tools is
+// disabled). Returns nil if no health information could be found, if the
+// drive is spun down or if we lack the privileges to query it.
+func diskHealth(
+	ctx context.Context,
+	paths *ghwpath.Paths,
+	disk string,
+	dt DriveType,
+	sc StorageController,
+) *Health {
+	opts := ghwcontext.OptionsFromContext(ctx)
+	if opts.EnableDiskHealth == nil || !*opts.EnableDiskHealth {
+		return nil
+	}
+	// NOTE: a missing udev database entry is not an error here, USB disks
+	// are then simply left to smartctl.
+	info, _ := udevInfoDisk(paths, disk)
+	query, useSmartctl := healthQuery(dt, sc, info)
+	if query == nil && !useSmartctl {
+		return nil
+	}
+	devPath := filepath.Join(paths.Dev, disk)
+	if _, err := os.Stat(devPath); err != nil {
+		return nil
+	}
+	err := fmt.Errorf("%s disks cannot be queried natively", sc)
+	if query != nil {
+		var health *Health
+		health, err = query(devPath)
+		if err == nil {
+			return health
+		}
+		// smartctl would fail to open the device just the same, and an
+		// unprivileged caller simply does not get to see drive health
+		if errors.Is(err, os.ErrPermission) || errors.Is(err, errDiskUnavailable) {
+			return nil
+		}
+	}
+
+	if !useSmartctl || opts.DisableExternalTools == nil || *opts.DisableExternalTools {
+		return nil
+	}
+	if !smartctlInstalled() {
+		ghwcontext.Warn(ctx, warnSmartctlNotInstalled, disk, err)
+		return nil
+	}
+	health, err := smartctlHealth(ctx, devPath)
+	if err != nil {
+		if !errors.Is(err, errDiskUnavailable) {
+			ghwcontext.Warn(ctx, "could not grab drive health for %s: %s\n", disk, err)
+		}
+		return nil
+	}
+	return health
+}
+
+// healthQuery returns the function natively querying the health of a disk
+// with the supplied drive type, storage controller and udev properties, or
+// nil if the disk cannot be queried natively. useSmartctl is true if smartctl
+// should be asked for the health of the disk when there is no native query
+// or it failed.
+//
+// ATA PASS-THROUGH is only sent to ATA drives: those on SATA and IDE
+// controllers, and those behind a USB bridge doing SCSI / ATA Translation,
+// which udev identifies as ATA. Flash sticks and card readers may stall or
+// reset when sent ATA commands, so other USB disks, like SAS and parallel
+// SCSI drives, are left to smartctl. Optical drives, FC and iSCSI LUNs and
+// virtual disks have no drive health worth asking for.
+func healthQuery(dt DriveType, sc StorageController, info map[string]string) (
+	query func(string) (*Health, error),
+	useSmartctl bool,
+) {
+	if dt == DriveTypeODD || dt == DriveTypeVirtual {
+		return nil, false
+	}
+	switch sc {
+	case StorageControllerNVMe, StorageControllerNVMeOF:
+		return nvmeHealthIoctl, true
+	case StorageControllerIDE, StorageControllerSATA:
+		return ataHealthIoctl, true
+	case StorageControllerUSB:
+		if info["ID_ATA"] == "1" || info["ID_ATA_SATA"] == "1" {
+			return ataHealthIoctl, true
+		}
+		return nil, true
+	case StorageControllerSAS, StorageControllerSCSI:
+		return nil, true
+	}
+	return nil, false
+}
+
+// nvmePassthruCmd mirrors `struct nvme_passthru_cmd` from
+// include/uapi/linux/nvme_ioctl.h
+type nvmePassthruCmd struct {
+	opcode  uint8
+	_       uint8  // flags
+	_       uint16 // rsvd1
+	nsid    uint32
+	_       uint32 // cdw2
+	_       uint32 // cdw3
+	_       uint64 // metadata
+	addr    uint64
+	_       uint32 // metadata_len
+	dataLen uint32
+	cdw10   uint32
+	_       [5]uint32 // cdw11-cdw15
+	_       uint32    // timeout_ms
+	_       uint32    // result
+}
+
+// sgIOHdr mirrors `struct sg_io_hdr` from include/scsi/sg.h
+type sgIOHdr struct {
+	interfaceID    int32
+	dxferDirection int32
+	cmdLen         uint8
+	mxSbLen        uint8
+	_              uint16 // iovec_count
+	dxferLen       uint32
+	dxferp         unsafe.Pointer
+	cmdp           unsafe.Pointer
+	sbp            unsafe.Pointer
+	timeout        uint32
+	_              uint32         // flags
+	_              int32          // pack_id
+	_              unsafe.Pointer // usr_ptr
+	status         uint8
+	_              uint8 // masked_status
+	_              uint8 // msg_status
+	_              uint8 // sb_len_wr
+	hostStatus     uint16
+	driverStatus   uint16
+	_              int32  // resid
+	_              uint32 // duration
+	_              uint32 // info
+}
+
+func ioctl(f *os.File, req uintptr, arg unsafe.Pointer) error {
+	_, _, errno := syscall.Syscall(
+		syscall.SYS_IOCTL, f.Fd(), req, uintptr(arg),
+	)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// nvmeHealthIoctl reads the SMART / Health Information log page from the
+// NVMe device at the supplied path using the NVMe admin command passthrough
+// ioctl.
+func nvmeHealthIoctl(devPath string) (*Health, error) {
+	f, err := os.OpenFile(devPath, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, nvmeSMARTHealthLogSize)
+	numDwords := uint32(nvmeSMARTHealthLogSize/4) - 1
+	cmd := nvmePassthruCmd{
+		opcode:  nvmeAdminGetLogPage,
+		nsid:    nvmeNSIDAll,
+		addr:    uint64(uintptr(unsafe.Pointer(&buf[0]))),
+		dataLen: nvmeSMARTHealthLogSize,
+		cdw10:   nvmeLogSMARTHealth | (numDwords << 16),
+	}
+	err = ioctl(f, nvmeIoctlAdminCmd, unsafe.Pointer(&cmd))
+	runtime.KeepAlive(buf)
+	if err != nil {
+		return nil, err
+	}
+	log, err := parseNVMeHealthLog(buf)
+	if err != nil {
+		return nil, err
+	}
+	return healthFromNVMeHealthLog(log, healthSourceIoctl), nil
+}
+
+// ataHealthIoctl reads the SMART attribute values and thresholds from the
+// ATA device at the supplied path by issuing SMART commands wrapped in the
+// SCSI ATA PASS-THROUGH (16) command through the SG_IO ioctl. Drives in
+// standby are left alone, since reading their SMART data would spin them up.
+func ataHealthIoctl(devPath string) (*Health, error) {
+	f, err := os.OpenFile(devPath, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	mode, err := ataPowerMode(f)
+	if err != nil {
+		return nil, err
+	}
+	if mode < ataPowerModeIdle {
+		return nil, errDiskUnavailable
+	}
+	data, err := ataSMARTCommand(f, ataSMARTReadData)
+	if err != nil {
+		return nil, err
+	}
+	thresholds, err := ataSMARTCommand(f, ataSMARTReadThresholds)
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := parseATASMARTData(data, thresholds)
+	if err != nil {
+		return nil, err
+	}
+	return healthFromATASMARTAttributes(attrs, healthSourceIoctl), nil
+}
+
+// ataPowerMode issues the ATA CHECK POWER MODE command, which does not spin
+// up the drive, and returns the power mode the drive reports.
+func ataPowerMode(f *os.File) (uint8, error) {
+	cdb := []byte{
+		ataPassThrough16,
+		// Non-data protocol
+		3 << 1,
+		// CK_COND=return the ATA registers in the sense data
+		0x20,
+		0, 0,
+		0, 0,
+		0, 0,
+		0, 0,
+		0, 0,
+		0,
+		ataCheckPowerMode,
+		0,
+	}
+	sense, err := sgATAPassThrough(f, cdb, nil)
+	if err != nil {
+		return 0, err
+	}
+	mode, ok := ataSenseSectorCount(sense)
+	if !ok {
+		return 0, fmt.Errorf("ATA CHECK POWER MODE returned no ATA registers")
+	}
+	return mode, nil
+}
+
+// ataSMARTCommand issues the ATA SMART command with the supplied feature
+// (sub-command) and returns the 512-byte data block it returned.
+func ataSMARTCommand(f *os.File, feature uint8) ([]byte, error) {
+	buf := make([]byte, ataSMARTDataSize)
+	cdb := []byte{
+		ataPassThrough16,
+		// PIO Data-In protocol
+		4 << 1,
+		// T_DIR=from device, BYT_BLOK=blocks, T_LENGTH=sector count field
+		0x0e,
+		0, feature,
+		0, 1,
+		0, 0,
+		// LBA mid/high must contain the SMART signature
+		0, 0x4f,
+		0, 0xc2,
+		0,
+		ataSMART,
+		0,
+	}
+	if _, err := sgATAPassThrough(f, cdb, buf); err != nil {
+		return nil, fmt.Errorf("ATA SMART command 0x%x failed: %w", feature, err)
+	}
+	return buf, nil
+}
+
+// sgATAPassThrough sends the supplied ATA PASS-THROUGH command descriptor
+// block through the SG_IO ioctl, reading any data into buf, and returns the
+// sense data. The CHECK CONDITION status that comes with the ATA registers
+// requested by CK_COND is not considered an error.
+func sgATAPassThrough(f *os.File, cdb []byte, buf []byte) ([]byte, error) {
+	sense := make([]byte, 32)
+	hdr := sgIOHdr{
+		interfaceID:    'S',
+		dxferDirection: sgDxferNone,
+		cmdLen:         uint8(len(cdb)),
+		mxSbLen:        uint8(len(sense)),
+		cmdp:           unsafe.Pointer(&cdb[0]),
+		sbp:            unsafe.Pointer(&sense[0]),
+		timeout:        sgTimeoutMS,
+	}
+	if len(buf) > 0 {
+		hdr.dxferDirection = sgDxferFromDev
+		hdr.dxferLen = uint32(len(buf))
+		hdr.dxferp = unsafe.Pointer(&buf[0])
+	}
+	err := ioctl(f, sgIO, unsafe.Pointer(&hdr))
+	runtime.KeepAlive(buf)
+	runtime.KeepAlive(cdb)
+	runtime.KeepAlive(sense)
+	if err != nil {
+		return nil, err
+	}
+	ckCond := cdb[2]&0x20 != 0
+	status, driverStatus := hdr.status, hdr.driverStatus
+	if ckCond && status == scsiCheckCondition {
+		status = 0
+		driverStatus &^= sgDriverSense
+	}
+	if status != 0 || hdr.hostStatus != 0 || driverStatus != 0 {
+		return nil, fmt.Errorf(
+			"status=0x%x host=0x%x driver=0x%x",
+			hdr.status, hdr.hostStatus, hdr.driverStatus,
+		)
+	}
+	return sense, nil
+}
+
+// ataSenseSectorCount returns the ATA sector count register from the supplied
+// sense data of an ATA PASS-THROUGH command issued with CK_COND set. Both the
+// descriptor format, with its ATA Status Return descriptor, and the fixed
+// format are understood.
+func ataSenseSectorCount(sense []byte) (uint8, bool) {
+	if len(sense) < 8 {
+		return 0, false
+	}
+	switch sense[0] & 0x7f {
+	case 0x72, 0x73:
+		// descriptors follow the 8-byte header
+		descs := sense[8:]
+		if n := 8 + int(sense[7]); n < len(sense) {
+			descs = sense[8:n]
+		}
+		for len(descs) >= 2 {
+			length := 2 + int(descs[1])
+			if length > len(descs) {
+				break
+			}
+			if descs[0] == 0x09 && length >= 14 {
+				return descs[5], true
+			}
+			descs = descs[length:]
+		}
+	case 0x70, 0x71:
+		// the INFORMATION field holds the error, status, device and sector
+		// count registers
+		if len(sense) >= 7 {
+			return sense[6], true
+		}
+	}
+	return 0, false
+}
+
+// parseNVMeHealthLog parses the raw 512-byte NVMe SMART / Health Information
+// log page. All multi-byte fields are little-endian and the 128-bit counters
+// are truncated to their lower 64 bits.
+func parseNVMeHealthLog(data []byte) (*NVMeHealthLog, error) {
+	if len(data) < nvmeSMARTHealthLogSize {
+		return nil, fmt.Errorf(
+			"NVMe SMART / Health log too short: %d bytes", len(data),
+		)
+	}
+	le := binary.LittleEndian
+	// the composite temperature is reported in Kelvin
+	tempK := int(le.Uint16(data[1:3]))
+	tempC := -1
+	if tempK > 0 {
+		tempC = tempK - 273
+	}
+	return &NVMeHealthLog{
+		CriticalWarning:         data[0],
+		TemperatureCelsius:      tempC,
+		AvailableSpare:          data[3],
+		AvailableSpareThreshold: data[4],
+		PercentageUsed:          data[5],
+		DataUnitsRead:           le.Uint64(data[32:40]),
+		DataUnitsWritten:        le.Uint64(data[48:56]),
+		HostReadCommands:        le.Uint64(data[64:72]),
+		HostWriteCommands:       le.Uint64(data[80:88]),
+		ControllerBusyMinutes:   le.Uint64(data[96:104]),
+		PowerCycles:             le.Uint64(data[112:120]),
+		PowerOnHours:            le.Uint64(data[128:136]),
+		UnsafeShutdowns:         le.Uint64(data[144:152]),
+		MediaErrors:             le.Uint64(data[160:168]),
+		ErrorLogEntries:         le.Uint64(data[176:184]),
+	}, nil
+}
+
+func healthFromNVMeHealthLog(log *NVMeHealthLog, source string) *Health {
+	h := newHealth(source)
+	h.NVMe = log
+	h.Status = HealthStatusPassed
+	if log.CriticalWarning != 0 {
+		h.Status = HealthStatusFailed
+	}
+	h.TemperatureCelsius = log.TemperatureCelsius
+	h.PercentageUsed = int(log.PercentageUsed)
+	h.PowerOnHours = int64(log.PowerOnHours)
+	h.MediaErrors = int64(log.MediaErrors)
+	return h
+}
+
+// parseATASMARTData parses the 512-byte data blocks returned by the ATA SMART
+// READ DATA and SMART READ THRESHOLDS commands into a slice of SMART
+// attributes.
+func parseATASMARTData(data []byte, thresholds []byte) ([]*SMARTAttribute, error) {
+	if len(data) < ataSMARTDataSize {
+		return nil, fmt.Errorf("ATA SMART data too short: %d bytes", len(data))
+	}
+	if !ataSMARTChecksumValid(data) {
+		return nil, fmt.Errorf("ATA SMART data has an invalid checksum")
+	}
+	threshByID := map[uint8]uint8{}
+	if len(thresholds) >= ataSMARTDataSize && ataSMARTChecksumValid(thresholds) {
+		for x := 0; x < ataSMARTNumAttributes; x++ {
+			entry := thresholds[2+x*ataSMARTAttributeSize:]
+			if entry[0] != 0 {
+				threshByID[entry[0]] = entry[1]
+			}
+		}
+	}
+	attrs := []*SMARTAttribute{}
+	for x := 0; x < ataSMARTNumAttributes; x++ {
+		// Each attribute entry is laid out like so:
+		//
+		// byte 0     -- attribute ID (0 means unused entry)
+		// bytes 1-2  -- status flags (bit 0 is the pre-failure flag)
+		// byte 3     -- current normalized value
+		// byte 4     -- worst normalized value
+		// bytes 5-10 -- raw value (little-endian)
+		// byte 11    -- reserved
+		entry := data[2+x*ataSMARTAttributeSize : 2+(x+1)*ataSMARTAttributeSize]
+		id := entry[0]
+		if id == 0 {
+			continue
+		}
+		var raw uint64
+		for b := 10; b >= 5; b-- {
+			raw = raw<<8 | uint64(entry[b])
+		}
+		name, ok := ataAttributeNames[id]
+		if !ok {
+			name = ataAttrUnknownAttributeStr
+		}
+		attrs = append(attrs, &SMARTAttribute{
+			ID:           id,
+			Name:         name,
+			IsPreFailure: entry[1]&0x01 != 0,
+			Value:        entry[3],
+			Worst:        entry[4],
+			Threshold:    threshByID[id],
+			RawValue:     raw,
+		})
+	}
+	return attrs, nil
+}
+
+// ataSMARTChecksumValid returns true if the two's complement checksum in the
+// last byte of the supplied SMART data structure is valid.
+func ataSMARTChecksumValid(data []byte) bool {
+	var sum uint8
+	for _, b := range data[:ataSMARTDataSize] {
+		sum += b
+	}
+	return sum == 0
+}
+
+func healthFromATASMARTAttributes(attrs []*SMARTAttribute, source string) *Health {
+	h := newHealth(source)
+	h.Attributes = attrs
+	h.Status = HealthStatusPassed
+	for _, a := range attrs {
+		if a.IsPreFailure && a.IsFailing() {
+			h.Status = HealthStatusFailed
+		}
+	}
+	if a := h.AttributeByID(ataAttrTemperatureCelsius); a != nil {
+		h.TemperatureCelsius = int(a.RawValue & 0xff)
+	} else if a := h.AttributeByID(ataAttrAirflowTemperature); a != nil {
+		h.TemperatureCelsius = int(a.RawValue & 0xff)
+	}
+	if a := h.AttributeByID(ataAttrPowerOnHours); a != nil {
+		// some vendors store extra information (e.g. minutes) in the upper
+		// bytes of the raw value
+		h.PowerOnHours = int64(a.RawValue & 0xffffffff)
+	}
+	if a := h.AttributeByID(ataAttrReallocatedSectors); a != nil {
+		h.ReallocatedSectors = int64(a.RawValue & 0xffffffff)
+	}
+	if a := h.AttributeByID(ataAttrReportedUncorrect); a != nil {
+		h.MediaErrors = int64(a.RawValue & 0xffffffff)
+	}
+	return h
+}
+
+func newHealth(source string) *Health {
+	return &Health{
+		Status:             HealthStatusUnknown,
+		TemperatureCelsius: -1,
+		PercentageUsed:     -1,
+		PowerOnHours:       -1,
+		MediaErrors:        -1,
+		ReallocatedSectors: -1,
+		Source:             source,
+	}
+}
+
+func smartctlInstalled() bool {
+	_, err := exec.LookPath("smartctl")
+	return err == nil
+}
+
+func smartctlHealth(ctx context.Context, devPath string) (*Health, error) {
+	path, _ := exec.LookPath("smartctl")
+	// NOTE: smartctl's exit status is a bitmask that is non-zero
+	// for many conditions (e.g. a failing drive) where it still produces
+	// usable output, so we only care about the error if we got no output.
+	// With `-n standby`, smartctl leaves spun down drives alone and reports
+	// them as unavailable.
+	out, err := exec.CommandContext(
+		ctx, path, "--json", "--all", "-n", "standby", devPath,
+	).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode()&smartctlExitDeviceUnavailable != 0 {
+		return nil, errDiskUnavailable
+	}
+	if len(out) == 0 {
+		if err == nil {
+			err = fmt.Errorf("no output from smartctl")
+		}
+		return nil, err
+	}
+	return parseSmartctlJSON(out)
+}
+
+type smartctlErrorCounter struct {
+	TotalUncorrectedErrors int64 `json:"total_uncorrected_errors"`
+}
+
+// smartctlOutput contains the subset of the `smartctl --json --all` output
+// that we care about.
+type smartctlOutput struct {
+	SmartStatus *struct {
+		Passed bool `json:"passed"`
+	} `json:"smart_status"`
+	Temperature *struct {
+		Current int `json:"current"`
+	} `json:"temperature"`
+	PowerOnTime *struct {
+		Hours int64 `json:"hours"`
+	} `json:"power_on_time"`
+	ATASmartAttributes *struct {
+		Table []struct {
+			ID     uint8  `json:"id"`
+			Name   string `json:"name"`
+			Value  uint8  `json:"value"`
+			Worst  uint8  `json:"worst"`
+			Thresh uint8  `json:"thresh"`
+			Flags  struct {
+				Prefailure bool `json:"prefailure"`
+			} `json:"flags"`
+			Raw struct {
+				Value uint64 `json:"value"`
+			} `json:"raw"`
+		} `json:"table"`
+	} `json:"ata_smart_attributes"`
+	NVMeSmartHealthLog *struct {
+		CriticalWarning         uint8  `json:"critical_warning"`
+		Temperature             int    `json:"temperature"`
+		AvailableSpare          uint8  `json:"available_spare"`
+		AvailableSpareThreshold uint8  `json:"available_spare_threshold"`
+		PercentageUsed          uint8  `json:"percentage_used"`
+		DataUnitsRead           uint64 `json:"data_units_read"`
+		DataUnitsWritten        uint64 `json:"data_units_written"`
+		HostReads               uint64 `json:"host_reads"`
+		HostWrites              uint64 `json:"host_writes"`
+		ControllerBusyTime      uint64 `json:"controller_busy_time"`
+		PowerCycles             uint64 `json:"power_cycles"`
+		PowerOnHours            uint64 `json:"power_on_hours"`
+		UnsafeShutdowns         uint64 `json:"unsafe_shutdowns"`
+		MediaErrors             uint64 `json:"media_errors"`
+		NumErrLogEntries        uint64 `json:"num_err_log_entries"`
+	} `json:"nvme_smart_health_information_log"`
+	SCSIGrownDefectList *int64 `json:"scsi_grown_defect_list"`
+	SCSIErrorCounterLog *struct {
+		Read   *smartctlErrorCounter `json:"read"`
+		Write  *smartctlErrorCounter `json:"write"`
+		Verify *smartctlErrorCounter `json:"verify"`
+	} `json:"scsi_error_counter_log"`
+	SCSIPercentageUsed *int `json:"scsi_percentage_used_endurance_indicator"`
+}
+
+// parseSmartctlJSON parses the output of `smartctl --json --all` for ATA,
+// NVMe or SCSI drives.
+func parseSmartctlJSON(data []byte) (*Health, error) {
+	var out smartctlOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+
+	var h *Health
+	switch {
+	case out.NVMeSmartHealthLog != nil:
+		l := out.NVMeSmartHealthLog
+		h = healthFromNVMeHealthLog(&NVMeHealthLog{
+			CriticalWarning:         l.CriticalWarning,
+			TemperatureCelsius:      l.Temperature,
+			AvailableSpare:          l.AvailableSpare,
+			AvailableSpareThreshold: l.AvailableSpareThreshold,
+			PercentageUsed:          l.PercentageUsed,
+			DataUnitsRead:           l.DataUnitsRead,
+			DataUnitsWritten:        l.DataUnitsWritten,
+			HostReadCommands:        l.HostReads,
+			HostWriteCommands:       l.HostWrites,
+			ControllerBusyMinutes:   l.ControllerBusyTime,
+			PowerCycles:             l.PowerCycles,
+			PowerOnHours:            l.PowerOnHours,
+			UnsafeShutdowns:         l.UnsafeShutdowns,
+			MediaErrors:             l.MediaErrors,
+			ErrorLogEntries:         l.NumErrLogEntries,
+		}, healthSourceSmartctl)
+	case out.ATASmartAttributes != nil:
+		attrs := make([]*SMARTAttribute, 0, len(out.ATASmartAttributes.Table))
+		for _, a := range out.ATASmartAttributes.Table {
+			attrs = append(attrs, &SMARTAttribute{
+				ID:           a.ID,
+				Name:         a.Name,
+				IsPreFailure: a.Flags.Prefailure,
+				Value:        a.Value,
+				Worst:        a.Worst,
+				Threshold:    a.Thresh,
+				RawValue:     a.Raw.Value,
+			})
+		}
+		h = healthFromATASMARTAttributes(attrs, healthSourceSmartctl)
+	case out.SmartStatus != nil || out.SCSIGrownDefectList != nil:
+		h = newHealth(healthSourceSmartctl)
+		if out.SCSIGrownDefectList != nil {
+			h.ReallocatedSectors = *out.SCSIGrownDefectList
+		}
+		if ecl := out.SCSIErrorCounterLog; ecl != nil {
+			h.MediaErrors = 0
+			for _, c := range []*smartctlErrorCounter{ecl.Read, ecl.Write, ecl.Verify} {
+				if c != nil {
+					h.MediaErrors += c.TotalUncorrectedErrors
+				}
+			}
+		}
+		if out.SCSIPercentageUsed != nil {
+			h.PercentageUsed = *out.SCSIPercentageUsed
+		}
+	default:
+		return nil, fmt.Errorf("no SMART data found in smartctl output")
+	}
+
+	// smartctl's own assessment is authoritative when present
+	if out.SmartStatus != nil {
+		h.Status = HealthStatusFailed
+		if out.SmartStatus.Passed {
+			h.Status = HealthStatusPassed
+		}
+	}
+	if out.Temperature != nil {
+		h.TemperatureCelsius = out.Temperature.Current
+	}
+	if out.PowerOnTime != nil {
+		h.PowerOnHours = out.PowerOnTime.Hours
+	}
+	return h, nil
+}
@@ -0,0 +1,377 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+//go:build linux
+// +build linux
+
+package block
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	ghwcontext "github.com/go-hardware/ghw/pkg/context"
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+	"github.com/go-hardware/ghw/testdata"
+)
+
+func readHealthSample(t *testing.T, name string) []byte {
+	testdataPath, err := testdata.SamplesDirectory()
+	if err != nil {
+		t.Fatalf("Expected nil err when detecting the samples directory, but got %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(testdataPath, name))
+	if err != nil {
+		t.Fatalf("Expected nil err when reading the sample data, but got %v", err)
+	}
+	return data
+}
+
+func TestNVMePassthruCmdSize(t *testing.T) {
+	// NVME_IOCTL_ADMIN_CMD encodes the size of struct nvme_passthru_cmd
+	if sz := unsafe.Sizeof(nvmePassthruCmd{}); sz != 72 {
+		t.Fatalf("Expected nvmePassthruCmd to be 72 bytes, but got %d", sz)
+	}
+}
+
+func TestATASenseSectorCount(t *testing.T) {
+	tests := []struct {
+		name  string
+		sense []byte
+		count uint8
+		ok    bool
+	}{
+		{
+			name: "descriptor format, standby",
+			sense: []byte{
+				0x72, 0x01, 0x00, 0x1d, 0, 0, 0, 14,
+				0x09, 0x0c, 0x00, 0x00, 0x00, 0x00, 0, 0, 0, 0, 0, 0, 0x40, 0x50,
+			},
+			count: 0x00,
+			ok:    true,
+		},
+		{
+			name: "descriptor format, active",
+			sense: []byte{
+				0x72, 0x01, 0x00, 0x1d, 0, 0, 0, 14,
+				0x09, 0x0c, 0x00, 0x00, 0x00, 0xff, 0, 0, 0, 0, 0, 0, 0x40, 0x50,
+			},
+			count: 0xff,
+			ok:    true,
+		},
+		{
+			name:  "fixed format, idle",
+			sense: []byte{0x70, 0x00, 0x01, 0x00, 0x50, 0x40, 0x80, 10},
+			count: 0x80,
+			ok:    true,
+		},
+		{
+			name: "descriptor format without ATA Status Return descriptor",
+			sense: []byte{
+				0x72, 0x05, 0x24, 0x00, 0, 0, 0, 4,
+				0x00, 0x02, 0x00, 0x00,
+			},
+		},
+		{
+			name:  "no sense data",
+			sense: make([]byte, 32),
+		},
+	}
+	for _, test := range tests {
+		count, ok := ataSenseSectorCount(test.sense)
+		if ok != test.ok {
+			t.Fatalf("For %s, expected ok to be %v, but got %v", test.name, test.ok, ok)
+		}
+		if count != test.count {
+			t.Fatalf("For %s, expected sector count 0x%x, but got 0x%x", test.name, test.count, count)
+		}
+	}
+}
+
+func TestDiskHealthDisabled(t *testing.T) {
+	baseDir := t.TempDir()
+	ctx := ghwcontext.New(ghwcontext.WithRootMountpoint(baseDir))
+	opts := ghwcontext.OptionsFromContext(ctx)
+	if *opts.EnableDiskHealth {
+		t.Skip("Skipping test, drive health was enabled from the environment.")
+	}
+	paths := ghwpath.New(ctx)
+	_ = os.MkdirAll(paths.Dev, 0755)
+	_ = os.WriteFile(filepath.Join(paths.Dev, "nvme0n1"), nil, 0644)
+	if h := diskHealth(ctx, paths, "nvme0n1", DriveTypeSSD, StorageControllerNVMe); h != nil {
+		t.Fatalf("Expected no drive health unless requested, but got %+v", h)
+	}
+}
+
+func TestParseNVMeHealthLog(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_BLOCK"); ok {
+		t.Skip("Skipping block tests.")
+	}
+
+	log, err := parseNVMeHealthLog(readHealthSample(t, "nvme-smart-health-log.bin"))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	h := healthFromNVMeHealthLog(log, healthSourceIoctl)
+
+	if h.Status != HealthStatusPassed {
+		t.Fatalf("Expected status %s, but got %s", HealthStatusPassed, h.Status)
+	}
+	if h.TemperatureCelsius != 35 {
+		t.Fatalf("Expected temperature 35C, but got %d", h.TemperatureCelsius)
+	}
+	if h.PercentageUsed != 3 {
+		t.Fatalf("Expected percentage used 3, but got %d", h.PercentageUsed)
+	}
+	if h.PowerOnHours != 8760 {
+		t.Fatalf("Expected 8760 power on hours, but got %d", h.PowerOnHours)
+	}
+	if h.MediaErrors != 0 {
+		t.Fatalf("Expected 0 media errors, but got %d", h.MediaErrors)
+	}
+	if h.ReallocatedSectors != -1 {
+		t.Fatalf("Expected unknown reallocated sectors, but got %d", h.ReallocatedSectors)
+	}
+	if log.DataUnitsWritten != 23456789 || log.UnsafeShutdowns != 17 || log.ErrorLogEntries != 42 {
+		t.Fatalf("Unexpected NVMe health log contents: %+v", log)
+	}
+
+	if _, err = parseNVMeHealthLog([]byte{0x00}); err == nil {
+		t.Fatalf("Expected an error parsing a truncated log, but got nil")
+	}
+}
+
+func TestParseATASMARTData(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_BLOCK"); ok {
+		t.Skip("Skipping block tests.")
+	}
+
+	data := readHealthSample(t, "ata-smart-data.bin")
+	thresholds := readHealthSample(t, "ata-smart-thresholds.bin")
+	attrs, err := parseATASMARTData(data, thresholds)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if len(attrs) != 8 {
+		t.Fatalf("Expected 8 attributes, but got %d", len(attrs))
+	}
+	h := healthFromATASMARTAttributes(attrs, healthSourceIoctl)
+
+	realloc := h.AttributeByID(ataAttrReallocatedSectors)
+	if realloc == nil {
+		t.Fatalf("Expected to find the reallocated sector count attribute")
+	}
+	if realloc.Name != "Reallocated_Sector_Ct" || !realloc.IsPreFailure || realloc.Threshold != 10 {
+		t.Fatalf("Unexpected reallocated sector count attribute: %s", realloc)
+	}
+	if h.Status != HealthStatusPassed {
+		t.Fatalf("Expected status %s, but got %s", HealthStatusPassed, h.Status)
+	}
+	if h.ReallocatedSectors != 8 {
+		t.Fatalf("Expected 8 reallocated sectors, but got %d", h.ReallocatedSectors)
+	}
+	// The upper bytes of the raw value of attribute 9 contain vendor data
+	if h.PowerOnHours != 13140 {
+		t.Fatalf("Expected 13140 power on hours, but got %d", h.PowerOnHours)
+	}
+	// The upper bytes of the raw value of attribute 194 contain the min/max
+	if h.TemperatureCelsius != 35 {
+		t.Fatalf("Expected temperature 35C, but got %d", h.TemperatureCelsius)
+	}
+	if h.MediaErrors != 2 {
+		t.Fatalf("Expected 2 media errors, but got %d", h.MediaErrors)
+	}
+	if h.PercentageUsed != -1 {
+		t.Fatalf("Expected unknown percentage used, but got %d", h.PercentageUsed)
+	}
+
+	// A pre-failure attribute at its threshold fails the drive
+	realloc.Value = realloc.Threshold
+	h = healthFromATASMARTAttributes(attrs, healthSourceIoctl)
+	if h.Status != HealthStatusFailed {
+		t.Fatalf("Expected status %s, but got %s", HealthStatusFailed, h.Status)
+	}
+
+	corrupt := make([]byte, len(data))
+	copy(corrupt, data)
+	corrupt[2] ^= 0xff
+	if _, err = parseATASMARTData(corrupt, thresholds); err == nil {
+		t.Fatalf("Expected an error parsing data with a bad checksum, but got nil")
+	}
+}
+
+func TestParseSmartctlJSON(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_BLOCK"); ok {
+		t.Skip("Skipping block tests.")
+	}
+
+	tests := []struct {
+		sample      string
+		status      HealthStatus
+		temperature int
+		used        int
+		powerOn     int64
+		mediaErrors int64
+		realloc     int64
+	}{
+		{
+			sample:      "smartctl-sata.json",
+			status:      HealthStatusPassed,
+			temperature: 31,
+			used:        -1,
+			powerOn:     21042,
+			mediaErrors: 0,
+			realloc:     2,
+		},
+		{
+			sample:      "smartctl-nvme.json",
+			status:      HealthStatusPassed,
+			temperature: 38,
+			used:        7,
+			powerOn:     6120,
+			mediaErrors: 0,
+			realloc:     -1,
+		},
+		{
+			sample:      "smartctl-sas.json",
+			status:      HealthStatusFailed,
+			temperature: 44,
+			used:        -1,
+			powerOn:     39120,
+			mediaErrors: 4,
+			realloc:     24,
+		},
+	}
+
+	for _, test := range tests {
+		h, err := parseSmartctlJSON(readHealthSample(t, test.sample))
+		if err != nil {
+			t.Fatalf("For %s, expected nil err, but got %v", test.sample, err)
+		}
+		if h.Source != healthSourceSmartctl {
+			t.Fatalf("For %s, expected source %q, but got %q", test.sample, healthSourceSmartctl, h.Source)
+		}
+		if h.Status != test.status {
+			t.Fatalf("For %s, expected status %s, but got %s", test.sample, test.status, h.Status)
+		}
+		if h.TemperatureCelsius != test.temperature {
+			t.Fatalf("For %s, expected temperature %d, but got %d", test.sample, test.temperature, h.TemperatureCelsius)
+		}
+		if h.PercentageUsed != test.used {
+			t.Fatalf("For %s, expected percentage used %d, but got %d", test.sample, test.used, h.PercentageUsed)
+		}
+		if h.PowerOnHours != test.powerOn {
+			t.Fatalf("For %s, expected %d power on hours, but got %d", test.sample, test.powerOn, h.PowerOnHours)
+		}
+		if h.MediaErrors != test.mediaErrors {
+			t.Fatalf("For %s, expected %d media errors, but got %d", test.sample, test.mediaErrors, h.MediaErrors)
+		}
+		if h.ReallocatedSectors != test.realloc {
+			t.Fatalf("For %s, expected %d reallocated sectors, but got %d", test.sample, test.realloc, h.ReallocatedSectors)
+		}
+	}
+
+	if _, err := parseSmartctlJSON([]byte(`{"smartctl": {"exit_status": 2}}`)); err == nil {
+		t.Fatalf("Expected an error parsing smartctl output without SMART data, but got nil")
+	}
+}
+
+func TestHealthQuery(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_BLOCK"); ok {
+		t.Skip("Skipping block tests.")
+	}
+	baseDir := t.TempDir()
+	ctx := ghwcontext.New(ghwcontext.WithRootMountpoint(baseDir))
+	paths := ghwpath.New(ctx)
+	_ = os.MkdirAll(paths.SysBlock, 0755)
+	_ = os.MkdirAll(paths.RunUdevData, 0755)
+
+	ataQuery := reflect.ValueOf(ataHealthIoctl).Pointer()
+	nvmeQuery := reflect.ValueOf(nvmeHealthIoctl).Pointer()
+	tests := []struct {
+		disk              string
+		devPath           string
+		storageController StorageController
+		udev              map[string]string
+		query             uintptr
+		useSmartctl       bool
+	}{
+		{
+			disk:              "sda",
+			devPath:           "pci0000:00/0000:00:17.0/ata1/host0/target0:0:0/0:0:0:0/block/sda",
+			storageController: StorageControllerSATA,
+			query:             ataQuery,
+			useSmartctl:       true,
+		},
+		{
+			disk:              "sdb",
+			devPath:           "pci0000:00/0000:00:14.0/usb2/2-1/2-1:1.0/host6/target6:0:0/6:0:0:0/block/sdb",
+			storageController: StorageControllerUSB,
+			udev:              map[string]string{"ID_BUS": "ata", "ID_ATA": "1", "ID_ATA_SATA": "1"},
+			query:             ataQuery,
+			useSmartctl:       true,
+		},
+		{
+			// a flash stick without a SCSI / ATA Translation bridge
+			disk:              "sde",
+			devPath:           "pci0000:00/0000:00:14.0/usb2/2-2/2-2:1.0/host7/target7:0:0/7:0:0:0/block/sde",
+			storageController: StorageControllerUSB,
+			udev:              map[string]string{"ID_BUS": "usb", "ID_DRIVE_THUMB": "1"},
+			useSmartctl:       true,
+		},
+		{
+			disk:              "sdc",
+			devPath:           "pci0000:00/0000:00:01.0/0000:01:00.0/host1/port-1:0/end_device-1:0/target1:0:0/1:0:0:0/block/sdc",
+			storageController: StorageControllerSAS,
+			useSmartctl:       true,
+		},
+		{
+			disk:              "sdd",
+			devPath:           "platform/host3/session1/target3:0:0/3:0:0:1/block/sdd",
+			storageController: StorageControllerISCSI,
+		},
+		{
+			disk:              "sr0",
+			devPath:           "pci0000:00/0000:00:17.0/ata2/host1/target1:0:0/1:0:0:0/block/sr0",
+			storageController: StorageControllerSATA,
+		},
+		{
+			disk:              "nvme0n1",
+			devPath:           "pci0000:00/0000:00:1d.0/0000:3d:00.0/nvme/nvme0/nvme0n1",
+			storageController: StorageControllerNVMe,
+			query:             nvmeQuery,
+			useSmartctl:       true,
+		},
+		{
+			disk:              "loop0",
+			devPath:           "virtual/block/loop0",
+			storageController: StorageControllerLoop,
+		},
+	}
+	for _, test := range tests {
+		_ = os.Symlink(filepath.Join("..", "devices", test.devPath), filepath.Join(paths.SysBlock, test.disk))
+		dt, sc := diskDriveTypeAndController(paths, test.disk)
+		if sc != test.storageController {
+			t.Fatalf("Expected %s storage controller for %s, but got %s", test.storageController, test.disk, sc)
+		}
+		query, useSmartctl := healthQuery(dt, sc, test.udev)
+		if useSmartctl != test.useSmartctl {
+			t.Fatalf("Expected smartctl use for %s to be %v, but got %v", test.disk, test.useSmartctl, useSmartctl)
+		}
+		if test.query == 0 {
+			if query != nil {
+				t.Fatalf("Expected no health query for %s", test.disk)
+			}
+			continue
+		}
+		if query == nil || reflect.ValueOf(query).Pointer() != test.query {
+			t.Fatalf("Expected %s to be queried with the %s health ioctl", test.disk, sc)
+		}
+	}
+}
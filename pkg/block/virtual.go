@@ -0,0 +1,109 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"fmt"
+
+	"github.com/go-hardware/ghw/pkg/util"
+)
+
+// LoopDevice describes the file backing a loop device
+type LoopDevice struct {
+	// BackingFile is the path of the file the loop device maps, as seen by
+	// the kernel when the device was set up
+	BackingFile string `json:"backing_file"`
+	// OffsetBytes is the offset, in bytes, into the backing file at which
+	// the loop device starts
+	OffsetBytes uint64 `json:"offset_bytes"`
+	// SizeLimitBytes is the maximum size, in bytes, of the loop device, or 0
+	// if the device extends to the end of the backing file
+	SizeLimitBytes uint64 `json:"size_limit_bytes"`
+	// IsAutoClear indicates the loop device is detached automatically when
+	// it is last closed
+	IsAutoClear bool `json:"autoclear"`
+	// IsPartScan indicates the kernel scans the loop device for partitions
+	IsPartScan bool `json:"partscan"`
+	// IsDirectIO indicates the backing file is accessed with direct I/O
+	IsDirectIO bool `json:"direct_io"`
+}
+
+// String returns a short string with information about the loop device.
+func (l *LoopDevice) String() string {
+	offset := ""
+	if l.OffsetBytes > 0 {
+		offset = fmt.Sprintf(" offset=%d", l.OffsetBytes)
+	}
+	sizeLimit := ""
+	if l.SizeLimitBytes > 0 {
+		sizeLimit = fmt.Sprintf(" sizelimit=%d", l.SizeLimitBytes)
+	}
+	return fmt.Sprintf(
+		"loop backing_file=%s%s",
+		l.BackingFile,
+		util.ConcatStrings(
+			offset,
+			sizeLimit,
+		),
+	)
+}
+
+// ZRAMDevice describes a compressed RAM block device
+type ZRAMDevice struct {
+	// Algorithm is the compression algorithm in use, e.g. "lzo-rle" or "zstd"
+	Algorithm string `json:"algorithm"`
+	// OriginalDataSizeBytes is the uncompressed size, in bytes, of the data
+	// stored in the device
+	OriginalDataSizeBytes uint64 `json:"original_data_size_bytes"`
+	// CompressedDataSizeBytes is the compressed size, in bytes, of the data
+	// stored in the device
+	CompressedDataSizeBytes uint64 `json:"compressed_data_size_bytes"`
+	// MemoryUsedBytes is the amount of memory, in bytes, the device uses to
+	// store the compressed data, including allocator overhead
+	MemoryUsedBytes uint64 `json:"memory_used_bytes"`
+	// MemoryLimitBytes is the maximum amount of memory, in bytes, the device
+	// may use, or 0 if there is no limit
+	MemoryLimitBytes uint64 `json:"memory_limit_bytes"`
+}
+
+// String returns a short string with information about the zram device.
+func (z *ZRAMDevice) String() string {
+	return fmt.Sprintf(
+		"zram algorithm=%s original=%d compressed=%d",
+		z.Algorithm,
+		z.OriginalDataSizeBytes,
+		z.CompressedDataSizeBytes,
+	)
+}
+
+// NetworkTarget describes the remote storage backing a network block device
+type NetworkTarget struct {
+	// Protocol is the protocol used to reach the remote storage: "nbd",
+	// "rbd" or "iscsi"
+	Protocol string `json:"protocol"`
+	// Target identifies the remote volume. This is the target name (IQN) for
+	// iSCSI, "pool/image[@snapshot]" for Ceph RBD and the backend identifier
+	// set by the client for NBD.
+	Target string `json:"target"`
+	// Address is the address of the remote server, e.g. the "host:port" of
+	// an iSCSI portal, or empty if it is not known
+	Address string `json:"address,omitempty"`
+}
+
+// String returns a short string with information about the network target.
+func (n *NetworkTarget) String() string {
+	address := ""
+	if n.Address != "" {
+		address = " address=" + n.Address
+	}
+	return fmt.Sprintf(
+		"%s target=%s%s",
+		n.Protocol,
+		n.Target,
+		address,
+	)
+}
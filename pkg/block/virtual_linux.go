@@ -0,0 +1,172 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package block
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+	"github.com/go-hardware/ghw/pkg/util"
+)
+
+// diskLoopDevice returns information about the file backing the loop device,
+// or nil if the disk is not an attached loop device.
+func diskLoopDevice(paths *ghwpath.Paths, disk string) *LoopDevice {
+	// The /sys/block/loopN/loop directory only exists while the loop device
+	// is bound to a backing file
+	loopDir := filepath.Join(paths.SysBlock, disk, "loop")
+	backingFile := util.ReadTrimmedFile(filepath.Join(loopDir, "backing_file"))
+	if backingFile == "" {
+		return nil
+	}
+	return &LoopDevice{
+		BackingFile:    backingFile,
+		OffsetBytes:    util.ReadUint64File(filepath.Join(loopDir, "offset")),
+		SizeLimitBytes: util.ReadUint64File(filepath.Join(loopDir, "sizelimit")),
+		IsAutoClear:    util.ReadTrimmedFile(filepath.Join(loopDir, "autoclear")) == "1",
+		IsPartScan:     util.ReadTrimmedFile(filepath.Join(loopDir, "partscan")) == "1",
+		IsDirectIO:     util.ReadTrimmedFile(filepath.Join(loopDir, "dio")) == "1",
+	}
+}
+
+// diskZRAMDevice returns information about the compressed RAM block device,
+// or nil if the disk is not a zram device.
+func diskZRAMDevice(paths *ghwpath.Paths, disk string) *ZRAMDevice {
+	diskDir := filepath.Join(paths.SysBlock, disk)
+	algorithms := util.ReadTrimmedFile(filepath.Join(diskDir, "comp_algorithm"))
+	if algorithms == "" {
+		return nil
+	}
+	zram := &ZRAMDevice{
+		Algorithm: parseZRAMAlgorithm(algorithms),
+	}
+	// mm_stat contains, in order: orig_data_size, compr_data_size,
+	// mem_used_total, mem_limit, mem_used_max, same_pages, pages_compacted,
+	// huge_pages and huge_pages_since
+	fields := strings.Fields(util.ReadTrimmedFile(filepath.Join(diskDir, "mm_stat")))
+	stats := make([]uint64, 4)
+	for x := range stats {
+		if x >= len(fields) {
+			break
+		}
+		if val, err := strconv.ParseUint(fields[x], 10, 64); err == nil {
+			stats[x] = val
+		}
+	}
+	zram.OriginalDataSizeBytes = stats[0]
+	zram.CompressedDataSizeBytes = stats[1]
+	zram.MemoryUsedBytes = stats[2]
+	zram.MemoryLimitBytes = stats[3]
+	return zram
+}
+
+// parseZRAMAlgorithm returns the selected algorithm from the contents of the
+// zram comp_algorithm pseudofile, which lists all the available algorithms
+// with the selected one in brackets, e.g. "lzo [lzo-rle] lz4 zstd"
+func parseZRAMAlgorithm(algorithms string) string {
+	for _, algo := range strings.Fields(algorithms) {
+		if strings.HasPrefix(algo, "[") && strings.HasSuffix(algo, "]") {
+			return strings.Trim(algo, "[]")
+		}
+	}
+	return algorithms
+}
+
+// diskNetworkTarget returns information about the remote storage backing a
+// network block device (NBD, Ceph RBD or an iSCSI LUN), or nil if the disk is
+// not a network block device.
+func diskNetworkTarget(paths *ghwpath.Paths, disk string) *NetworkTarget {
+	switch {
+	case strings.HasPrefix(disk, "nbd"):
+		return nbdNetworkTarget(paths, disk)
+	case strings.HasPrefix(disk, "rbd"):
+		return rbdNetworkTarget(paths, disk)
+	case strings.HasPrefix(disk, "sd"):
+		return iscsiNetworkTarget(paths, disk)
+	}
+	return nil
+}
+
+func nbdNetworkTarget(paths *ghwpath.Paths, disk string) *NetworkTarget {
+	diskDir := filepath.Join(paths.SysBlock, disk)
+	// The pid pseudofile only exists while an NBD client is connected
+	if _, err := os.Stat(filepath.Join(diskDir, "pid")); err != nil {
+		return nil
+	}
+	return &NetworkTarget{
+		Protocol: "nbd",
+		// The backend identifier is supplied by the client when connecting
+		// over netlink, e.g. the export name, and is not always set
+		Target: util.ReadTrimmedFile(filepath.Join(diskDir, "backend")),
+	}
+}
+
+func rbdNetworkTarget(paths *ghwpath.Paths, disk string) *NetworkTarget {
+	// /sys/block/rbdN corresponds to /sys/bus/rbd/devices/N
+	devDir := filepath.Join(paths.SysBusRBDDevices, strings.TrimPrefix(disk, "rbd"))
+	pool := util.ReadTrimmedFile(filepath.Join(devDir, "pool"))
+	image := util.ReadTrimmedFile(filepath.Join(devDir, "name"))
+	if pool == "" || image == "" {
+		return nil
+	}
+	target := pool + "/"
+	if ns := util.ReadTrimmedFile(filepath.Join(devDir, "pool_ns")); ns != "" {
+		target += ns + "/"
+	}
+	target += image
+	if snap := util.ReadTrimmedFile(filepath.Join(devDir, "current_snap")); snap != "" && snap != "-" {
+		target += "@" + snap
+	}
+	return &NetworkTarget{
+		Protocol: "rbd",
+		Target:   target,
+	}
+}
+
+func iscsiNetworkTarget(paths *ghwpath.Paths, disk string) *NetworkTarget {
+	// An iSCSI LUN sits below the session in the /sys/devices tree, e.g.
+	// ../devices/platform/host3/session1/target3:0:0/3:0:0:1/block/sdb
+	devPath, err := os.Readlink(filepath.Join(paths.SysBlock, disk))
+	if err != nil {
+		return nil
+	}
+	session := ""
+	for _, part := range strings.Split(filepath.ToSlash(devPath), "/") {
+		if strings.HasPrefix(part, "session") {
+			session = part
+			break
+		}
+	}
+	if session == "" {
+		return nil
+	}
+	targetName := util.ReadTrimmedFile(filepath.Join(paths.SysClassISCSISession, session, "targetname"))
+	if targetName == "" {
+		return nil
+	}
+	target := &NetworkTarget{
+		Protocol: "iscsi",
+		Target:   targetName,
+	}
+	// Connections are named connection<host>:<cid>. Use the first one of
+	// the session to find the address of the portal.
+	conns, err := filepath.Glob(filepath.Join(paths.SysClassISCSISession, session, "device", "connection*"))
+	if err == nil && len(conns) > 0 {
+		connDir := filepath.Join(paths.SysClassISCSIConn, filepath.Base(conns[0]))
+		address := util.ReadTrimmedFile(filepath.Join(connDir, "persistent_address"))
+		port := util.ReadTrimmedFile(filepath.Join(connDir, "persistent_port"))
+		if address != "" && port != "" {
+			target.Address = net.JoinHostPort(address, port)
+		} else {
+			target.Address = address
+		}
+	}
+	return target
+}
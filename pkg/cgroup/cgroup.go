@@ -0,0 +1,53 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+// Package cgroup reads the CPU and memory limits control groups (cgroups)
+// impose on the processes they contain, e.g. the limits of a container.
+// Both cgroup v1 and cgroup v2 hierarchies are supported.
+package cgroup
+
+import (
+	"github.com/go-hardware/ghw/pkg/cpuset"
+)
+
+// Unlimited is the value of the limits a cgroup does not set
+const Unlimited = -1
+
+// CPU describes the CPU limits of a cgroup, including the limits inherited
+// from its ancestors
+type CPU struct {
+	// Version is the version of the cgroup hierarchy, 1 or 2
+	Version int `json:"version"`
+	// Path is the path of the cgroup relative to the root of the hierarchy,
+	// e.g. "/kubepods.slice/kubepods-pod1.slice"
+	Path string `json:"path"`
+	// CPUs contains the logical processors the processes of the cgroup may
+	// run on (the effective cpuset), or is nil if the cpuset controller is
+	// not available
+	CPUs cpuset.CPUSet `json:"cpus,omitempty"`
+	// QuotaMicros is the CPU time, in microseconds, the processes of the
+	// cgroup may use in each period, or `Unlimited`
+	QuotaMicros int64 `json:"quota_us"`
+	// PeriodMicros is the length, in microseconds, of the period the quota
+	// applies to
+	PeriodMicros int64 `json:"period_us,omitempty"`
+}
+
+// Memory describes the memory limits of a cgroup, including the limits
+// inherited from its ancestors
+type Memory struct {
+	// Version is the version of the cgroup hierarchy, 1 or 2
+	Version int `json:"version"`
+	// Path is the path of the cgroup relative to the root of the hierarchy
+	Path string `json:"path"`
+	// MaxBytes is the amount of memory the processes of the cgroup may use
+	// before they are killed, or `Unlimited`
+	MaxBytes int64 `json:"max_bytes"`
+	// HighBytes is the amount of memory above which the kernel throttles the
+	// processes of the cgroup and reclaims their memory, or `Unlimited`.
+	// cgroup v1 has no such limit.
+	HighBytes int64 `json:"high_bytes"`
+}
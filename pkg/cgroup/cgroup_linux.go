@@ -0,0 +1,225 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cgroup
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	ghwcontext "github.com/go-hardware/ghw/pkg/context"
+	"github.com/go-hardware/ghw/pkg/cpuset"
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+	"github.com/go-hardware/ghw/pkg/util"
+)
+
+// v1MemoryUnlimited is the threshold above which cgroup v1 memory limits mean
+// no limit. The kernel reports no limit as the largest page aligned int64.
+const v1MemoryUnlimited = 1 << 62
+
+// CPULimits returns the CPU limits of the cgroup set in the context options,
+// or nil if the options do not ask for cgroup limits
+func CPULimits(ctx context.Context) (*CPU, error) {
+	opts := ghwcontext.OptionsFromContext(ctx)
+	if opts.CgroupPath == nil {
+		return nil, nil
+	}
+	paths := ghwpath.New(ctx)
+	if isUnified(paths) {
+		root, path, err := cgroupDir(paths, 2, "", *opts.CgroupPath)
+		if err != nil {
+			return nil, err
+		}
+		limits := &CPU{Version: 2, Path: path, QuotaMicros: Unlimited}
+		dir := filepath.Join(root, path)
+		limits.CPUs = cpuset.ReadFile(filepath.Join(dir, "cpuset.cpus.effective"))
+		for _, d := range ancestors(root, dir) {
+			fields := strings.Fields(util.ReadTrimmedFile(filepath.Join(d, "cpu.max")))
+			if len(fields) != 2 || fields[0] == "max" {
+				continue
+			}
+			quota, _ := strconv.ParseInt(fields[0], 10, 64)
+			period, _ := strconv.ParseInt(fields[1], 10, 64)
+			limits.setQuota(quota, period)
+		}
+		return limits, nil
+	}
+
+	root, path, err := cgroupDir(paths, 1, "cpu", *opts.CgroupPath)
+	if err != nil {
+		return nil, err
+	}
+	limits := &CPU{Version: 1, Path: path, QuotaMicros: Unlimited}
+	for _, d := range ancestors(root, filepath.Join(root, path)) {
+		quota, err := strconv.ParseInt(util.ReadTrimmedFile(filepath.Join(d, "cpu.cfs_quota_us")), 10, 64)
+		if err != nil || quota < 0 {
+			continue
+		}
+		period, _ := strconv.ParseInt(util.ReadTrimmedFile(filepath.Join(d, "cpu.cfs_period_us")), 10, 64)
+		limits.setQuota(quota, period)
+	}
+	if root, path, err := cgroupDir(paths, 1, "cpuset", *opts.CgroupPath); err == nil {
+		dir := filepath.Join(root, path)
+		limits.CPUs = cpuset.ReadFile(filepath.Join(dir, "cpuset.effective_cpus"))
+		if limits.CPUs == nil {
+			limits.CPUs = cpuset.ReadFile(filepath.Join(dir, "cpuset.cpus"))
+		}
+	}
+	return limits, nil
+}
+
+// setQuota sets the supplied CPU quota if it is tighter than the current one
+func (c *CPU) setQuota(quota int64, period int64) {
+	if quota <= 0 || period <= 0 {
+		return
+	}
+	if c.QuotaMicros == Unlimited || float64(quota)/float64(period) < float64(c.QuotaMicros)/float64(c.PeriodMicros) {
+		c.QuotaMicros = quota
+		c.PeriodMicros = period
+	}
+}
+
+// MemoryLimits returns the memory limits of the cgroup set in the context
+// options, or nil if the options do not ask for cgroup limits
+func MemoryLimits(ctx context.Context) (*Memory, error) {
+	opts := ghwcontext.OptionsFromContext(ctx)
+	if opts.CgroupPath == nil {
+		return nil, nil
+	}
+	paths := ghwpath.New(ctx)
+	version := 1
+	controller := "memory"
+	if isUnified(paths) {
+		version = 2
+		controller = ""
+	}
+	root, path, err := cgroupDir(paths, version, controller, *opts.CgroupPath)
+	if err != nil {
+		return nil, err
+	}
+	limits := &Memory{
+		Version:   version,
+		Path:      path,
+		MaxBytes:  Unlimited,
+		HighBytes: Unlimited,
+	}
+	for _, d := range ancestors(root, filepath.Join(root, path)) {
+		if version == 2 {
+			limits.MaxBytes = tighterMemoryLimit(limits.MaxBytes, util.ReadTrimmedFile(filepath.Join(d, "memory.max")))
+			limits.HighBytes = tighterMemoryLimit(limits.HighBytes, util.ReadTrimmedFile(filepath.Join(d, "memory.high")))
+		} else {
+			limits.MaxBytes = tighterMemoryLimit(limits.MaxBytes, util.ReadTrimmedFile(filepath.Join(d, "memory.limit_in_bytes")))
+		}
+	}
+	return limits, nil
+}
+
+// tighterMemoryLimit returns the tighter of the supplied limit and of the
+// limit read from a cgroup file, which is "max" or unreasonably large when
+// the cgroup sets no limit
+func tighterMemoryLimit(limit int64, val string) int64 {
+	bytes, err := strconv.ParseInt(val, 10, 64)
+	if err != nil || bytes < 0 || bytes >= v1MemoryUnlimited {
+		return limit
+	}
+	if limit == Unlimited || bytes < limit {
+		return bytes
+	}
+	return limit
+}
+
+// isUnified returns true if /sys/fs/cgroup is a cgroup v2 (unified)
+// hierarchy. Hosts mounting the cgroup v1 controllers below /sys/fs/cgroup
+// and an empty cgroup v2 hierarchy below /sys/fs/cgroup/unified ("hybrid"
+// mode) are considered cgroup v1 hosts, since the limits are set in v1.
+func isUnified(paths *ghwpath.Paths) bool {
+	_, err := os.Stat(filepath.Join(paths.SysFsCgroup, "cgroup.controllers"))
+	return err == nil
+}
+
+// cgroupDir returns the root of the hierarchy holding the supplied controller
+// and the path of the cgroup in that hierarchy. An empty cgroup path means
+// the cgroup of the current process.
+func cgroupDir(paths *ghwpath.Paths, version int, controller string, path string) (string, string, error) {
+	root := paths.SysFsCgroup
+	if version == 1 {
+		// the controllers of cgroup v1 are mounted by name, e.g.
+		// /sys/fs/cgroup/cpu, possibly as links to co-mounted controllers
+		// like /sys/fs/cgroup/cpu,cpuacct
+		root = filepath.Join(root, controller)
+	}
+	if _, err := os.Stat(root); err != nil {
+		return "", "", fmt.Errorf("cgroup controller %q not found: %w", controller, err)
+	}
+	if path != "" {
+		path = filepath.Clean("/" + path)
+		if _, err := os.Stat(filepath.Join(root, path)); err != nil {
+			return "", "", fmt.Errorf("cgroup %q not found: %w", path, err)
+		}
+		return root, path, nil
+	}
+	path, err := processCgroupPath(paths, version, controller)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := os.Stat(filepath.Join(root, path)); err != nil {
+		// Containers without a cgroup namespace see the cgroup of their
+		// process in /proc/self/cgroup, but have it mounted as the root of
+		// the hierarchy
+		path = "/"
+	}
+	return root, path, nil
+}
+
+// processCgroupPath returns the path of the cgroup of the current process in
+// the hierarchy holding the supplied controller, read from /proc/self/cgroup,
+// whose lines look like:
+//
+//	0::/user.slice/user-1000.slice/session-2.scope
+//	4:memory:/docker/0123456789ab
+//	2:cpu,cpuacct:/docker/0123456789ab
+func processCgroupPath(paths *ghwpath.Paths, version int, controller string) (string, error) {
+	f, err := os.Open(paths.ProcSelfCgroup)
+	if err != nil {
+		return "", err
+	}
+	defer util.SafeClose(f)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if version == 2 {
+			if fields[0] == "0" && fields[1] == "" {
+				return fields[2], nil
+			}
+			continue
+		}
+		for _, name := range strings.Split(fields[1], ",") {
+			if name == controller {
+				return fields[2], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("cgroup of the current process not found in %s", paths.ProcSelfCgroup)
+}
+
+// ancestors returns the supplied cgroup directory and the directories of its
+// ancestors, up to and including the root of the hierarchy
+func ancestors(root string, dir string) []string {
+	dirs := []string{dir}
+	for dir != root && strings.HasPrefix(dir, root) {
+		dir = filepath.Dir(dir)
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
@@ -0,0 +1,144 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cgroup_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-hardware/ghw/pkg/cgroup"
+	ghwcontext "github.com/go-hardware/ghw/pkg/context"
+	"github.com/go-hardware/ghw/pkg/cpuset"
+)
+
+// writeFiles writes the supplied files, keyed by path relative to the
+// supplied root
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for path, contents := range files {
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Expected nil err, but got %v", err)
+		}
+		if err := os.WriteFile(path, []byte(contents+"\n"), 0644); err != nil {
+			t.Fatalf("Expected nil err, but got %v", err)
+		}
+	}
+}
+
+func TestCgroupV2(t *testing.T) {
+	root := t.TempDir()
+	// the pod sets the memory limit and a CPU quota of 4 CPUs, the container
+	// a tighter CPU quota of 1.5 CPUs and a memory high limit
+	writeFiles(t, root, map[string]string{
+		"proc/self/cgroup":                                       "0::/kubepods/pod1/ctr1",
+		"sys/fs/cgroup/cgroup.controllers":                       "cpuset cpu io memory pids",
+		"sys/fs/cgroup/kubepods/pod1/cpu.max":                    "400000 100000",
+		"sys/fs/cgroup/kubepods/pod1/memory.max":                 "2147483648",
+		"sys/fs/cgroup/kubepods/pod1/memory.high":                "max",
+		"sys/fs/cgroup/kubepods/pod1/ctr1/cpu.max":               "150000 100000",
+		"sys/fs/cgroup/kubepods/pod1/ctr1/memory.max":            "max",
+		"sys/fs/cgroup/kubepods/pod1/ctr1/memory.high":           "1073741824",
+		"sys/fs/cgroup/kubepods/pod1/ctr1/cpuset.cpus.effective": "2-5",
+	})
+
+	ctx := ghwcontext.New(ghwcontext.WithRootMountpoint(root), ghwcontext.WithCgroup())
+	cpuLimits, err := cgroup.CPULimits(ctx)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	expectedCPU := &cgroup.CPU{
+		Version:      2,
+		Path:         "/kubepods/pod1/ctr1",
+		CPUs:         cpuset.CPUSet{2, 3, 4, 5},
+		QuotaMicros:  150000,
+		PeriodMicros: 100000,
+	}
+	if !reflect.DeepEqual(expectedCPU, cpuLimits) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", expectedCPU, cpuLimits)
+	}
+
+	memLimits, err := cgroup.MemoryLimits(ctx)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	expectedMem := &cgroup.Memory{
+		Version:   2,
+		Path:      "/kubepods/pod1/ctr1",
+		MaxBytes:  2147483648,
+		HighBytes: 1073741824,
+	}
+	if !reflect.DeepEqual(expectedMem, memLimits) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", expectedMem, memLimits)
+	}
+
+	// an explicit path reports the limits of the pod
+	ctx = ghwcontext.New(ghwcontext.WithRootMountpoint(root), ghwcontext.WithCgroupPath("kubepods/pod1"))
+	memLimits, err = cgroup.MemoryLimits(ctx)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if memLimits.Path != "/kubepods/pod1" || memLimits.HighBytes != cgroup.Unlimited {
+		t.Fatalf("Expected the unlimited high limit of /kubepods/pod1 but got %+v", memLimits)
+	}
+
+	ctx = ghwcontext.New(ghwcontext.WithRootMountpoint(root), ghwcontext.WithCgroupPath("/nonexistent"))
+	if _, err := cgroup.CPULimits(ctx); err == nil {
+		t.Fatalf("Expected an error for a nonexistent cgroup")
+	}
+}
+
+func TestCgroupV1(t *testing.T) {
+	root := t.TempDir()
+	// a container without cgroup namespace sees its own cgroup mounted as
+	// the root of each hierarchy
+	writeFiles(t, root, map[string]string{
+		"proc/self/cgroup":                           "5:memory:/docker/abc\n4:cpuset:/docker/abc\n3:cpu,cpuacct:/docker/abc\n0::/",
+		"sys/fs/cgroup/cpu/cpu.cfs_quota_us":         "200000",
+		"sys/fs/cgroup/cpu/cpu.cfs_period_us":        "100000",
+		"sys/fs/cgroup/cpuset/cpuset.cpus":           "0-3",
+		"sys/fs/cgroup/memory/memory.limit_in_bytes": "536870912",
+	})
+
+	ctx := ghwcontext.New(ghwcontext.WithRootMountpoint(root), ghwcontext.WithCgroup())
+	cpuLimits, err := cgroup.CPULimits(ctx)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	expectedCPU := &cgroup.CPU{
+		Version:      1,
+		Path:         "/",
+		CPUs:         cpuset.CPUSet{0, 1, 2, 3},
+		QuotaMicros:  200000,
+		PeriodMicros: 100000,
+	}
+	if !reflect.DeepEqual(expectedCPU, cpuLimits) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", expectedCPU, cpuLimits)
+	}
+
+	memLimits, err := cgroup.MemoryLimits(ctx)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	expectedMem := &cgroup.Memory{
+		Version:   1,
+		Path:      "/",
+		MaxBytes:  536870912,
+		HighBytes: cgroup.Unlimited,
+	}
+	if !reflect.DeepEqual(expectedMem, memLimits) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", expectedMem, memLimits)
+	}
+
+	// no limits are reported unless asked for
+	ctx = ghwcontext.New(ghwcontext.WithRootMountpoint(root))
+	if cpuLimits, err := cgroup.CPULimits(ctx); cpuLimits != nil || err != nil {
+		t.Fatalf("Expected no limits but got %+v (%v)", cpuLimits, err)
+	}
+}
@@ -59,6 +59,18 @@ func WithOptions(opts *Options) ContextModifier {
 		if opts.DisableExternalTools == nil {
 			opts.DisableExternalTools = defOpts.DisableExternalTools
 		}
+		if opts.ExcludeVirtualDisks == nil {
+			opts.ExcludeVirtualDisks = defOpts.ExcludeVirtualDisks
+		}
+		if opts.EnableDiskHealth == nil {
+			opts.EnableDiskHealth = defOpts.EnableDiskHealth
+		}
+		if opts.EnableCryptHeaders == nil {
+			opts.EnableCryptHeaders = defOpts.EnableCryptHeaders
+		}
+		if opts.CgroupPath == nil {
+			opts.CgroupPath = defOpts.CgroupPath
+		}
 		return context.WithValue(ctx, optsKey, opts)
 	}
 }
@@ -85,6 +97,55 @@ func WithDisableExternalTools() ContextModifier {
 	}
 }
 
+// WithExcludeVirtualDisks leaves virtual disks (loop, zram and RAM disks) out
+// of the block storage information.
+func WithExcludeVirtualDisks() ContextModifier {
+	return func(ctx context.Context) context.Context {
+		opts := OptionsFromContext(ctx)
+		_true := true
+		opts.ExcludeVirtualDisks = &_true
+		return context.WithValue(ctx, optsKey, opts)
+	}
+}
+
+// WithDiskHealth queries the SMART / drive health of each disk.
+func WithDiskHealth() ContextModifier {
+	return func(ctx context.Context) context.Context {
+		opts := OptionsFromContext(ctx)
+		_true := true
+		opts.EnableDiskHealth = &_true
+		return context.WithValue(ctx, optsKey, opts)
+	}
+}
+
+// WithCryptHeaders reads the LUKS header of each encrypted volume to learn
+// its cipher.
+func WithCryptHeaders() ContextModifier {
+	return func(ctx context.Context) context.Context {
+		opts := OptionsFromContext(ctx)
+		_true := true
+		opts.EnableCryptHeaders = &_true
+		return context.WithValue(ctx, optsKey, opts)
+	}
+}
+
+// WithCgroup reports the CPU and memory limits of the control group (cgroup)
+// of the current process, e.g. the limits of the container ghw runs in.
+func WithCgroup() ContextModifier {
+	return WithCgroupPath("")
+}
+
+// WithCgroupPath reports the CPU and memory limits of the control group
+// (cgroup) at the supplied path, relative to the root of the cgroup
+// hierarchy.
+func WithCgroupPath(path string) ContextModifier {
+	return func(ctx context.Context) context.Context {
+		opts := OptionsFromContext(ctx)
+		opts.CgroupPath = &path
+		return context.WithValue(ctx, optsKey, opts)
+	}
+}
+
 // WithRootMountpoint sets the root mountpoint ghw uses when querying system
 // information.
 func WithRootMountpoint(path string) ContextModifier {
@@ -7,6 +7,8 @@
 package context
 
 import (
+	"os"
+
 	"github.com/jaypipes/envutil"
 )
 
@@ -14,6 +16,9 @@ const (
 	defaultRootMountpoint       = "/"
 	defaultDisableWarnings      = false
 	defaultDisableExternalTools = false
+	defaultExcludeVirtualDisks  = false
+	defaultEnableDiskHealth     = false
+	defaultEnableCryptHeaders   = false
 )
 
 const (
@@ -22,6 +27,10 @@ const (
 	envKeyDisableWarnings      = "GHW_DISABLE_WARNINGS"
 	envKeyDisableTools         = "GHW_DISABLE_TOOLS"
 	envKeyDisableExternalTools = "GHW_DISABLE_EXTERNAL_TOOLS"
+	envKeyExcludeVirtualDisks  = "GHW_EXCLUDE_VIRTUAL_DISKS"
+	envKeyCgroupPath           = "GHW_CGROUP_PATH"
+	envKeyEnableDiskHealth     = "GHW_ENABLE_DISK_HEALTH"
+	envKeyEnableCryptHeaders   = "GHW_ENABLE_CRYPT_HEADERS"
 )
 
 // PathOverrides is a map, keyed by the string name of a mount path, of
@@ -66,6 +75,44 @@ type Options struct {
 	// set, we will use that value. Please use the GHW_DISABLE_EXTERNAL_TOOLS
 	// environs variable instead.
 	DisableExternalTools *bool
+
+	// ExcludeVirtualDisks tells ghw to leave virtual disks (loop, zram and
+	// RAM disks) out of the block storage information, including the total
+	// storage size. The default is to report virtual disks.
+	//
+	// Set the GHW_EXCLUDE_VIRTUAL_DISKS environs variable to 1 or any truthy
+	// value to exclude virtual disks.
+	ExcludeVirtualDisks *bool
+
+	// EnableDiskHealth tells ghw to query the SMART / drive health of each
+	// disk. This sends commands to the drives and usually requires root
+	// privileges, so the default is not to report drive health. Drives that
+	// are spun down are never woken up to query their health.
+	//
+	// Set the GHW_ENABLE_DISK_HEALTH environs variable to 1 or any truthy
+	// value to report drive health.
+	EnableDiskHealth *bool
+
+	// EnableCryptHeaders tells ghw to read the LUKS header of each encrypted
+	// volume to learn its cipher. Reading the header usually requires root
+	// privileges and may spin up drives in standby, so the default is to
+	// only report what the udev database knows about encrypted volumes.
+	//
+	// Set the GHW_ENABLE_CRYPT_HEADERS environs variable to 1 or any truthy
+	// value to read LUKS headers.
+	EnableCryptHeaders *bool
+
+	// CgroupPath tells ghw to also report the CPU and memory limits of a
+	// control group (cgroup), for instance to learn how much of the host a
+	// container may use. It is the path of the cgroup relative to the root of
+	// the cgroup hierarchy, e.g. "/kubepods.slice/kubepods-pod1.slice". An
+	// empty path means the cgroup of the current process. The default, nil,
+	// is not to report cgroup limits.
+	//
+	// Set the GHW_CGROUP_PATH environs variable to a cgroup path, or to an
+	// empty value for the cgroup of the current process, to report cgroup
+	// limits.
+	CgroupPath *string
 }
 
 // defaultOpts returns the default set of options derived from any environs
@@ -89,9 +136,29 @@ func defaultOpts() *Options {
 			defaultDisableExternalTools,
 		),
 	)
+	envDefaultExcludeVirtualDisks := envutil.WithDefaultBool(
+		envKeyExcludeVirtualDisks,
+		defaultExcludeVirtualDisks,
+	)
+	envDefaultEnableDiskHealth := envutil.WithDefaultBool(
+		envKeyEnableDiskHealth,
+		defaultEnableDiskHealth,
+	)
+	envDefaultEnableCryptHeaders := envutil.WithDefaultBool(
+		envKeyEnableCryptHeaders,
+		defaultEnableCryptHeaders,
+	)
+	var envDefaultCgroupPath *string
+	if path, ok := os.LookupEnv(envKeyCgroupPath); ok {
+		envDefaultCgroupPath = &path
+	}
 	return &Options{
 		RootMountpoint:       &envDefaultRootMountpoint,
 		DisableWarnings:      &envDefaultDisableWarnings,
 		DisableExternalTools: &envDefaultDisableExternalTools,
+		ExcludeVirtualDisks:  &envDefaultExcludeVirtualDisks,
+		EnableDiskHealth:     &envDefaultEnableDiskHealth,
+		EnableCryptHeaders:   &envDefaultEnableCryptHeaders,
+		CgroupPath:           envDefaultCgroupPath,
 	}
 }
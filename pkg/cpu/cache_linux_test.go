@@ -0,0 +1,86 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	ghwcontext "github.com/go-hardware/ghw/pkg/context"
+	"github.com/go-hardware/ghw/pkg/cpu"
+	"github.com/go-hardware/ghw/pkg/memory"
+)
+
+func TestProcessorCaches(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+	root := t.TempDir()
+	writeFakeCPUs(t, root, 4)
+
+	// each core has its own L1 and L2 caches, and shares an L3 cache with
+	// another core, like the cores of an AMD CCX
+	for lpID := 0; lpID < 4; lpID++ {
+		l3Shared := "0-1"
+		if lpID >= 2 {
+			l3Shared = "2-3"
+		}
+		indexes := []map[string]string{
+			{"level": "1", "type": "Data", "size": "32K", "ways_of_associativity": "8", "number_of_sets": "64", "shared_cpu_list": fmt.Sprint(lpID)},
+			{"level": "1", "type": "Instruction", "size": "32K", "ways_of_associativity": "8", "number_of_sets": "64", "shared_cpu_list": fmt.Sprint(lpID)},
+			{"level": "2", "type": "Unified", "size": "1024K", "ways_of_associativity": "8", "number_of_sets": "2048", "shared_cpu_list": fmt.Sprint(lpID)},
+			{"level": "3", "type": "Unified", "size": "32768K", "ways_of_associativity": "16", "number_of_sets": "32768", "shared_cpu_list": l3Shared},
+		}
+		for idx, attrs := range indexes {
+			indexDir := filepath.Join(root, "sys", "devices", "system", "cpu", fmt.Sprintf("cpu%d", lpID), "cache", fmt.Sprintf("index%d", idx))
+			_ = os.MkdirAll(indexDir, 0755)
+			attrs["coherency_line_size"] = "64"
+			attrs["physical_line_partition"] = "1"
+			for name, val := range attrs {
+				_ = os.WriteFile(filepath.Join(indexDir, name), []byte(val+"\n"), 0644)
+			}
+		}
+	}
+
+	info, err := cpu.New(ghwcontext.New(ghwcontext.WithRootMountpoint(root)))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	proc := info.Processors[0]
+	if len(proc.Caches) != 14 {
+		t.Fatalf("Expected 14 caches but got %d", len(proc.Caches))
+	}
+
+	core := proc.CoreByID(2)
+	if len(core.Caches) != 4 {
+		t.Fatalf("Expected 4 caches for core 2 but got %d", len(core.Caches))
+	}
+	expectedL3 := &memory.Cache{
+		Level:                 3,
+		Type:                  memory.CacheTypeUnified,
+		SizeBytes:             32 * 1024 * 1024,
+		LogicalProcessors:     []uint32{2, 3},
+		LineSizeBytes:         64,
+		WaysOfAssociativity:   16,
+		NumberOfSets:          32768,
+		PhysicalLinePartition: 1,
+	}
+	if !reflect.DeepEqual(expectedL3, core.Caches[3]) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", expectedL3, core.Caches[3])
+	}
+	if core.Caches[0].Level != 1 || core.Caches[0].Type != memory.CacheTypeInstruction ||
+		!reflect.DeepEqual([]uint32{2}, core.Caches[0].LogicalProcessors) {
+		t.Fatalf("Expected the private L1i cache of core 2 first but got %+v", core.Caches[0])
+	}
+	// the cores sharing an L3 cache share the same Cache
+	if proc.CoreByID(3).Caches[3] != core.Caches[3] {
+		t.Fatalf("Expected cores 2 and 3 to share their L3 cache")
+	}
+}
@@ -0,0 +1,74 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CoreType describes the kind of a processor core on hybrid processors, like
+// Intel Alder Lake and later or ARM big.LITTLE designs, which pack cores of
+// different performance and power efficiency into the same package
+type CoreType int
+
+const (
+	// CoreTypeUnknown means the core type could not be determined, which is
+	// the case for all cores of processors whose cores are all alike
+	CoreTypeUnknown CoreType = iota
+	// CoreTypePerformance indicates a performance core, e.g. an Intel P-core
+	// or an ARM "big" core
+	CoreTypePerformance
+	// CoreTypeEfficiency indicates a power efficient core, e.g. an Intel
+	// E-core or an ARM "LITTLE" core
+	CoreTypeEfficiency
+)
+
+var (
+	coreTypeString = map[CoreType]string{
+		CoreTypeUnknown:     "Unknown",
+		CoreTypePerformance: "performance",
+		CoreTypeEfficiency:  "efficiency",
+	}
+
+	// NOTE: the keys are all lowercase and do not match the keys in the
+	// opposite table `coreTypeString`. This is done because of the choice we
+	// made in CoreType::MarshalJSON. We use this table only in UnmarshalJSON,
+	// so it should be OK.
+	stringCoreType = map[string]CoreType{
+		"unknown":     CoreTypeUnknown,
+		"performance": CoreTypePerformance,
+		"efficiency":  CoreTypeEfficiency,
+	}
+)
+
+func (ct CoreType) String() string {
+	return coreTypeString[ct]
+}
+
+// NOTE: since serialized output is as "official" as we're going to get,
+// let's lowercase the string output when serializing, in order to
+// "normalize" the expected serialized output
+func (ct CoreType) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strings.ToLower(ct.String()))), nil
+}
+
+func (ct *CoreType) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	key := strings.ToLower(s)
+	val, ok := stringCoreType[key]
+	if !ok {
+		return fmt.Errorf("unknown core type: %q", key)
+	}
+	*ct = val
+	return nil
+}
@@ -0,0 +1,139 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/go-hardware/ghw/pkg/cpuset"
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+	"github.com/go-hardware/ghw/pkg/util"
+)
+
+// armImplementerARM is the "CPU implementer" of cores designed by ARM Ltd.
+const armImplementerARM = 0x41
+
+// armEfficiencyParts contains the "CPU part" numbers of the in-order, power
+// efficient cores ARM pairs with its performance cores in big.LITTLE and
+// DynamIQ designs
+var armEfficiencyParts = map[uint64]bool{
+	0xc05: true, // Cortex-A5
+	0xc07: true, // Cortex-A7
+	0xd03: true, // Cortex-A53
+	0xd04: true, // Cortex-A35
+	0xd05: true, // Cortex-A55
+	0xd46: true, // Cortex-A510
+	0xd80: true, // Cortex-A520
+}
+
+// logicalProcessorCoreTypes returns the core type of the supplied logical
+// processors, keyed by logical processor ID, or nil if the processors are not
+// hybrid. In order of preference, the core types come from:
+//
+//   - the CPU lists of the cpu_core and cpu_atom PMUs Intel hybrid processors
+//     register in /sys/devices
+//   - the relative capacities in /sys/devices/system/cpu/cpu{N}/cpu_capacity,
+//     which the kernel reports on ARM, the most capable cores being the
+//     performance ones
+//   - the "CPU implementer" and "CPU part" attributes of /proc/cpuinfo on ARM
+func logicalProcessorCoreTypes(
+	paths *ghwpath.Paths,
+	lps map[int]*logicalProcessor,
+	lpIDs []int,
+) map[int]CoreType {
+	if types := coreTypesFromPMUs(paths); types != nil {
+		return types
+	}
+	if types := coreTypesFromCapacity(paths, lpIDs); types != nil {
+		return types
+	}
+	return coreTypesFromARMParts(lps)
+}
+
+// coreTypesFromPMUs returns the core types found in the CPU lists of the
+// cpu_core (P-cores) and cpu_atom (E-cores) PMUs of Intel hybrid processors
+func coreTypesFromPMUs(paths *ghwpath.Paths) map[int]CoreType {
+	types := map[int]CoreType{}
+	for pmu, ct := range map[string]CoreType{
+		"cpu_core": CoreTypePerformance,
+		"cpu_atom": CoreTypeEfficiency,
+	} {
+		cpus, _ := cpuset.Parse(util.ReadTrimmedFile(filepath.Join(paths.SysDevices, pmu, "cpus")))
+		for _, lpID := range cpus {
+			types[lpID] = ct
+		}
+	}
+	if len(types) == 0 {
+		return nil
+	}
+	return types
+}
+
+// coreTypesFromCapacity returns the core types derived from the capacities of
+// the supplied logical processors, if they differ
+func coreTypesFromCapacity(paths *ghwpath.Paths, lpIDs []int) map[int]CoreType {
+	capacities := map[int]uint64{}
+	var maxCapacity uint64
+	differ := false
+	for _, lpID := range lpIDs {
+		capacity := util.ReadUint64File(filepath.Join(
+			paths.SysDevicesSystemCPU, fmt.Sprintf("cpu%d", lpID), "cpu_capacity",
+		))
+		if capacity == 0 {
+			return nil
+		}
+		if len(capacities) > 0 && capacity != maxCapacity {
+			differ = true
+		}
+		if capacity > maxCapacity {
+			maxCapacity = capacity
+		}
+		capacities[lpID] = capacity
+	}
+	if !differ {
+		return nil
+	}
+	types := map[int]CoreType{}
+	for lpID, capacity := range capacities {
+		if capacity == maxCapacity {
+			types[lpID] = CoreTypePerformance
+		} else {
+			types[lpID] = CoreTypeEfficiency
+		}
+	}
+	return types
+}
+
+// coreTypesFromARMParts returns the core types derived from the part numbers
+// of the supplied logical processors, if they mix ARM efficiency cores with
+// other cores
+func coreTypesFromARMParts(lps map[int]*logicalProcessor) map[int]CoreType {
+	types := map[int]CoreType{}
+	numEfficiency := 0
+	for lpID, lp := range lps {
+		implementer, err := strconv.ParseUint(lp.Attrs["CPU implementer"], 0, 64)
+		if err != nil {
+			return nil
+		}
+		part, err := strconv.ParseUint(lp.Attrs["CPU part"], 0, 64)
+		if err != nil {
+			return nil
+		}
+		if implementer == armImplementerARM && armEfficiencyParts[part] {
+			types[lpID] = CoreTypeEfficiency
+			numEfficiency++
+		} else {
+			types[lpID] = CoreTypePerformance
+		}
+	}
+	if numEfficiency == 0 || numEfficiency == len(types) {
+		return nil
+	}
+	return types
+}
@@ -0,0 +1,131 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	ghwcontext "github.com/go-hardware/ghw/pkg/context"
+	"github.com/go-hardware/ghw/pkg/cpu"
+)
+
+func TestCoreTypes(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+
+	P := cpu.CoreTypePerformance
+	E := cpu.CoreTypeEfficiency
+	U := cpu.CoreTypeUnknown
+
+	tests := []struct {
+		name     string
+		setup    func(root string)
+		expected []cpu.CoreType
+	}{
+		{
+			name:     "uniform",
+			setup:    func(root string) {},
+			expected: []cpu.CoreType{U, U, U, U},
+		},
+		{
+			name: "intel hybrid PMUs",
+			setup: func(root string) {
+				for pmu, cpus := range map[string]string{"cpu_core": "0-1", "cpu_atom": "2-3"} {
+					pmuDir := filepath.Join(root, "sys", "devices", pmu)
+					_ = os.MkdirAll(pmuDir, 0755)
+					_ = os.WriteFile(filepath.Join(pmuDir, "cpus"), []byte(cpus+"\n"), 0644)
+				}
+			},
+			expected: []cpu.CoreType{P, P, E, E},
+		},
+		{
+			name: "cpu capacity",
+			setup: func(root string) {
+				for lpID, capacity := range []string{"446", "446", "1024", "1024"} {
+					path := filepath.Join(root, "sys", "devices", "system", "cpu", fmt.Sprintf("cpu%d", lpID), "cpu_capacity")
+					_ = os.WriteFile(path, []byte(capacity+"\n"), 0644)
+				}
+			},
+			expected: []cpu.CoreType{E, E, P, P},
+		},
+		{
+			name: "arm part numbers",
+			setup: func(root string) {
+				cpuinfo := ""
+				for lpID, part := range []string{"0xd05", "0xd05", "0xd0b", "0xd0b"} {
+					cpuinfo += fmt.Sprintf(
+						"processor\t: %d\nBogoMIPS\t: 38.40\nFeatures\t: fp asimd\nCPU implementer\t: 0x41\nCPU architecture: 8\nCPU variant\t: 0x1\nCPU part\t: %s\nCPU revision\t: 0\n\n",
+						lpID, part,
+					)
+				}
+				_ = os.WriteFile(filepath.Join(root, "proc", "cpuinfo"), []byte(cpuinfo), 0644)
+			},
+			expected: []cpu.CoreType{E, E, P, P},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFakeCPUs(t, root, 4)
+			test.setup(root)
+
+			info, err := cpu.New(ghwcontext.New(ghwcontext.WithRootMountpoint(root)))
+			if err != nil {
+				t.Fatalf("Expected nil err, but got %v", err)
+			}
+			proc := info.Processors[0]
+			actual := make([]cpu.CoreType, 4)
+			var numP, numE uint32
+			for _, ct := range test.expected {
+				switch ct {
+				case P:
+					numP++
+				case E:
+					numE++
+				}
+			}
+			for _, core := range proc.Cores {
+				actual[core.ID] = core.CoreType
+			}
+			if !reflect.DeepEqual(test.expected, actual) {
+				t.Fatalf("Expected %v but got %v", test.expected, actual)
+			}
+			if proc.NumPerformanceCores != numP || proc.NumEfficiencyCores != numE {
+				t.Fatalf(
+					"Expected %d performance and %d efficiency cores but got %d and %d",
+					numP, numE, proc.NumPerformanceCores, proc.NumEfficiencyCores,
+				)
+			}
+		})
+	}
+}
+
+func TestCoreTypeJSON(t *testing.T) {
+	b, err := json.Marshal(cpu.CoreTypeEfficiency)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if string(b) != `"efficiency"` {
+		t.Fatalf("Expected \"efficiency\" but got %s", b)
+	}
+	var ct cpu.CoreType
+	if err := json.Unmarshal([]byte(`"performance"`), &ct); err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if ct != cpu.CoreTypePerformance {
+		t.Fatalf("Expected performance but got %v", ct)
+	}
+	if err := json.Unmarshal([]byte(`"turbo"`), &ct); err == nil {
+		t.Fatalf("Expected an error for an unknown core type")
+	}
+}
@@ -10,7 +10,10 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/go-hardware/ghw/pkg/cgroup"
+	"github.com/go-hardware/ghw/pkg/cpuset"
 	"github.com/go-hardware/ghw/pkg/marshal"
+	"github.com/go-hardware/ghw/pkg/memory"
 )
 
 // ProcessorCore describes a physical host processor core. A processor core is
@@ -29,19 +32,63 @@ type ProcessorCore struct {
 	// called the "thread siblings". Logical processor IDs are the *zero-based*
 	// index of the processor on the host and are *not* related to the core ID.
 	LogicalProcessors []int `json:"logical_processors"`
+	// CoreType indicates whether this is a performance or an efficiency core
+	// of a hybrid processor. It is `CoreTypeUnknown` for the cores of
+	// processors whose cores are all alike.
+	CoreType CoreType `json:"core_type"`
+	// ARM is a pointer to an `ARMIdentification` struct identifying the
+	// core, or nil if it is not an ARM core. The cores of big.LITTLE and
+	// DynamIQ processors have different parts.
+	ARM *ARMIdentification `json:"arm,omitempty"`
+	// Caches is a slice of `memory.Cache` struct pointers describing the
+	// caches the core has access to, from its private L1 caches to the
+	// caches it shares with other cores, sorted by level
+	Caches []*memory.Cache `json:"caches,omitempty"`
+	// Temperature is a pointer to the `Temperature` reported by the core's
+	// sensor, or nil if the core has no sensor of its own
+	Temperature *Temperature `json:"temperature,omitempty"`
+	// ThrottleCount is the number of times the core was throttled because
+	// it got too hot
+	ThrottleCount uint64 `json:"throttle_count,omitempty"`
 }
 
 // String returns a short string indicating important information about the
 // processor core
 func (c *ProcessorCore) String() string {
+	ctStr := ""
+	if c.CoreType != CoreTypeUnknown {
+		ctStr = " " + c.CoreType.String()
+	}
 	return fmt.Sprintf(
-		"processor core #%d (%d threads), logical processors %v",
+		"processor%s core #%d (%d threads), logical processors %v",
+		ctStr,
 		c.ID,
 		c.NumThreads,
 		c.LogicalProcessors,
 	)
 }
 
+// LogicalProcessor describes a logical processor, or hardware thread, of a
+// physical host processor.
+type LogicalProcessor struct {
+	// ID is the *zero-based* index of the logical processor on the host
+	ID int `json:"id"`
+	// CoreID is the ID of the `ProcessorCore` the logical processor belongs
+	// to
+	CoreID int `json:"core_id"`
+	// Frequency is a pointer to a `Frequency` struct describing the
+	// frequency scaling of the logical processor, or nil if the host does not
+	// support frequency scaling
+	Frequency *Frequency `json:"frequency,omitempty"`
+	// Online is true if the logical processor is online, i.e. available to
+	// the scheduler
+	Online bool `json:"online"`
+	// IdleStates is a slice of `IdleState` struct pointers describing the
+	// idle states (C-states) of the logical processor, ordered by index, or
+	// nil if no cpuidle driver manages them
+	IdleStates []*IdleState `json:"idle_states,omitempty"`
+}
+
 // Processor describes a physical host central processing unit (CPU).
 type Processor struct {
 	// ID is the physical processor `uint32` ID according to the system
@@ -50,16 +97,69 @@ type Processor struct {
 	NumCores uint32 `json:"total_cores"`
 	// NumThreads is the number of hardware threads in the processor package
 	NumThreads uint32 `json:"total_threads"`
+	// NumPerformanceCores is the number of performance cores in the
+	// processor package, or 0 if the processor is not hybrid
+	NumPerformanceCores uint32 `json:"total_performance_cores,omitempty"`
+	// NumEfficiencyCores is the number of efficiency cores in the processor
+	// package, or 0 if the processor is not hybrid
+	NumEfficiencyCores uint32 `json:"total_efficiency_cores,omitempty"`
 	// Vendor is a string containing the vendor name
 	Vendor string `json:"vendor"`
 	// Model` is a string containing the vendor's model name
 	Model string `json:"model"`
+	// X86 is a pointer to a `X86Identification` struct containing the CPUID
+	// signature of the processor, or nil if it is not an x86 processor
+	X86 *X86Identification `json:"x86,omitempty"`
+	// ARM is a pointer to an `ARMIdentification` struct containing the
+	// identification of the first core of the processor, or nil if it is not
+	// an ARM processor. Processors mixing cores of different parts, e.g.
+	// big.LITTLE processors, identify each core in `ProcessorCore.ARM`.
+	ARM *ARMIdentification `json:"arm,omitempty"`
+	// BogoMIPS is the kernel's rough measurement of the processor speed,
+	// used to calibrate busy loops
+	BogoMIPS float64 `json:"bogomips,omitempty"`
+	// PhysicalAddressBits and VirtualAddressBits are the number of bits in
+	// the physical and virtual addresses the processor supports, or 0 if the
+	// kernel does not report them
+	PhysicalAddressBits uint32 `json:"physical_address_bits,omitempty"`
+	VirtualAddressBits  uint32 `json:"virtual_address_bits,omitempty"`
+	// Microcode is the revision of the microcode the processor runs, e.g.
+	// "0x2b000590", or empty if the kernel does not report it
+	Microcode string `json:"microcode,omitempty"`
 	// Capabilities is a slice of strings indicating the features the processor
 	// has enabled
 	Capabilities []string `json:"capabilities"`
+	// Features is a slice of the `Feature`s the processor supports, derived
+	// from its capabilities and named the same way whatever the
+	// architecture, sorted
+	Features []Feature `json:"features,omitempty"`
+	// X86Level is the highest x86-64 microarchitecture level, from 1 to 4
+	// (x86-64-v1 to x86-64-v4), the processor supports, or 0 if it is not an
+	// x86-64 processor
+	X86Level int `json:"x86_level,omitempty"`
 	// Cores is a slice of ProcessorCore` struct pointers that are packed onto
 	// this physical processor
 	Cores []*ProcessorCore `json:"cores"`
+	// LogicalProcessors is a slice of `LogicalProcessor` struct pointers, one
+	// for each hardware thread of this physical processor, ordered by ID
+	LogicalProcessors []*LogicalProcessor `json:"logical_processors,omitempty"`
+	// Caches is a slice of `memory.Cache` struct pointers describing the
+	// caches of this physical processor, sorted by level. A processor may
+	// have several caches of the same level, e.g. one L3 cache per AMD CCX.
+	Caches []*memory.Cache `json:"caches,omitempty"`
+	// Frequency is a pointer to a `FrequencySummary` struct summarizing the
+	// frequency scaling of the logical processors of this physical
+	// processor, or nil if the host does not support frequency scaling
+	Frequency *FrequencySummary `json:"frequency,omitempty"`
+	// Thermal is a pointer to a `Thermal` struct describing the temperature
+	// sensors and thermal throttling of this physical processor, or nil if
+	// the host does not report them
+	Thermal *Thermal `json:"thermal,omitempty"`
+	// RAPL is a slice of pointers to the `RAPLDomain` structs of this
+	// physical processor, one per die of the package, whose energy counters
+	// tell how much energy it consumed. It is empty if the host does not
+	// support RAPL.
+	RAPL []*RAPLDomain `json:"rapl,omitempty"`
 }
 
 // CoreByID returns the ProcessorCore having the supplied ID.
@@ -72,6 +172,16 @@ func (p *Processor) CoreByID(coreID int) *ProcessorCore {
 	return nil
 }
 
+// LogicalProcessorByID returns the LogicalProcessor having the supplied ID.
+func (p *Processor) LogicalProcessorByID(lpID int) *LogicalProcessor {
+	for _, lp := range p.LogicalProcessors {
+		if lp.ID == lpID {
+			return lp
+		}
+	}
+	return nil
+}
+
 // HasCapability returns true if the Processor has the supplied cpuid
 // capability, false otherwise. Example of cpuid capabilities would be 'vmx' or
 // 'sse4_2'. To see a list of potential cpuid capabilitiies, see the section on
@@ -87,6 +197,19 @@ func (p *Processor) HasCapability(find string) bool {
 	return false
 }
 
+// HasFeature returns true if the Processor supports the supplied feature,
+// false otherwise. Unlike HasCapability, HasFeature does not depend on the
+// architecture: `FeatureAES` is found on both x86 and ARM64 processors
+// supporting AES instructions.
+func (p *Processor) HasFeature(find Feature) bool {
+	for _, f := range p.Features {
+		if f == find {
+			return true
+		}
+	}
+	return false
+}
+
 // String returns a short string describing the Processor
 func (p *Processor) String() string {
 	ncs := "cores"
@@ -97,11 +220,20 @@ func (p *Processor) String() string {
 	if p.NumThreads == 1 {
 		nts = "thread"
 	}
+	hybridStr := ""
+	if p.NumPerformanceCores > 0 || p.NumEfficiencyCores > 0 {
+		hybridStr = fmt.Sprintf(
+			" (%d performance, %d efficiency)",
+			p.NumPerformanceCores,
+			p.NumEfficiencyCores,
+		)
+	}
 	return fmt.Sprintf(
-		"physical package #%d (%d %s, %d hardware %s)",
+		"physical package #%d (%d %s%s, %d hardware %s)",
 		p.ID,
 		p.NumCores,
 		ncs,
+		hybridStr,
 		p.NumThreads,
 		nts,
 	)
@@ -119,6 +251,44 @@ type Info struct {
 	// Processors is a slice of Processor struct pointers, one for each
 	// physical processor package contained in the host
 	Processors []*Processor `json:"processors"`
+	// Online contains the logical processors that are online, i.e. available
+	// to the scheduler
+	Online cpuset.CPUSet `json:"online,omitempty"`
+	// Offline contains the logical processors that are offline, either
+	// because they were taken offline or because they are possible but not
+	// present
+	Offline cpuset.CPUSet `json:"offline,omitempty"`
+	// Possible contains the logical processors the kernel allocated resources
+	// for, including the ones that may be hotplugged later
+	Possible cpuset.CPUSet `json:"possible,omitempty"`
+	// Present contains the logical processors that are present in the system
+	Present cpuset.CPUSet `json:"present,omitempty"`
+	// Isolated contains the logical processors isolated from the scheduler
+	// with the isolcpus kernel parameter
+	Isolated cpuset.CPUSet `json:"isolated,omitempty"`
+	// NohzFull contains the logical processors running in adaptive-ticks
+	// mode, set with the nohz_full kernel parameter
+	NohzFull cpuset.CPUSet `json:"nohz_full,omitempty"`
+	// Vulnerabilities is a slice of Vulnerability struct pointers, one for
+	// each CPU vulnerability the kernel knows about, sorted by name
+	Vulnerabilities []*Vulnerability `json:"vulnerabilities,omitempty"`
+	// Idle is a pointer to an `Idle` struct describing the idle management
+	// (cpuidle) of the logical processors, or nil if the kernel does not
+	// support cpuidle
+	Idle *Idle `json:"idle,omitempty"`
+	// PStateDriver is a pointer to a `PStateDriver` struct describing the
+	// status of the intel_pstate or amd_pstate driver, or nil if neither is
+	// loaded
+	PStateDriver *PStateDriver `json:"pstate_driver,omitempty"`
+	// Cgroup is a pointer to a `cgroup.CPU` struct describing the CPU limits
+	// of the cgroup requested with the CgroupPath option, or nil if the
+	// option is not set
+	Cgroup *cgroup.CPU `json:"cgroup,omitempty"`
+	// EffectiveCPUs is the number of logical processors the processes of the
+	// cgroup requested with the CgroupPath option can keep busy: the online
+	// logical processors of its cpuset, lowered by its CPU quota. It is 0 if
+	// the option is not set.
+	EffectiveCPUs float64 `json:"effective_cpus,omitempty"`
 }
 
 // New returns a pointer to an Info struct that contains information about the
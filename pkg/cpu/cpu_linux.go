@@ -16,7 +16,10 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/go-hardware/ghw/pkg/cgroup"
 	ghwcontext "github.com/go-hardware/ghw/pkg/context"
+	"github.com/go-hardware/ghw/pkg/cpuset"
+	"github.com/go-hardware/ghw/pkg/memory"
 	ghwpath "github.com/go-hardware/ghw/pkg/path"
 	"github.com/go-hardware/ghw/pkg/util"
 )
@@ -26,7 +29,17 @@ var (
 )
 
 func (i *Info) load(ctx context.Context) error {
-	i.Processors = processorsGet(ctx)
+	paths := ghwpath.New(ctx)
+	i.Online = cpuset.ReadFile(filepath.Join(paths.SysDevicesSystemCPU, "online"))
+	i.Offline = cpuset.ReadFile(filepath.Join(paths.SysDevicesSystemCPU, "offline"))
+	i.Possible = cpuset.ReadFile(filepath.Join(paths.SysDevicesSystemCPU, "possible"))
+	i.Present = cpuset.ReadFile(filepath.Join(paths.SysDevicesSystemCPU, "present"))
+	i.Isolated = cpuset.ReadFile(filepath.Join(paths.SysDevicesSystemCPU, "isolated"))
+	i.NohzFull = cpuset.ReadFile(filepath.Join(paths.SysDevicesSystemCPU, "nohz_full"))
+	i.Processors = processorsGet(ctx, i.Online)
+	i.Vulnerabilities = vulnerabilities(paths)
+	i.Idle = idle(paths)
+	i.PStateDriver = pstateDriver(paths)
 	var totCores uint32
 	var totThreads uint32
 	for _, p := range i.Processors {
@@ -35,10 +48,44 @@ func (i *Info) load(ctx context.Context) error {
 	}
 	i.TotalCores = totCores
 	i.TotalThreads = totThreads
+	limits, err := cgroup.CPULimits(ctx)
+	if err != nil {
+		ghwcontext.Warn(ctx, "failed to read cgroup CPU limits: %s", err)
+	} else if limits != nil {
+		i.Cgroup = limits
+		i.EffectiveCPUs = effectiveCPUs(limits, i.Online, i.TotalThreads)
+	}
 	return nil
 }
 
-func processorsGet(ctx context.Context) []*Processor {
+// effectiveCPUs returns the number of logical processors the supplied cgroup
+// limits allow to keep busy, out of the supplied online logical processors
+// or, if the kernel does not report them, total number of hardware threads
+func effectiveCPUs(limits *cgroup.CPU, online cpuset.CPUSet, totThreads uint32) float64 {
+	cpus := float64(totThreads)
+	if online != nil {
+		cpus = float64(len(online))
+	}
+	if limits.CPUs != nil {
+		cpus = 0
+		for _, lpID := range limits.CPUs {
+			if online == nil || online.Contains(lpID) {
+				cpus++
+			}
+		}
+	}
+	if limits.QuotaMicros != cgroup.Unlimited {
+		if quota := float64(limits.QuotaMicros) / float64(limits.PeriodMicros); quota < cpus {
+			cpus = quota
+		}
+	}
+	return cpus
+}
+
+// processorsGet returns the physical processors of the host. The supplied set
+// of online logical processors, if any, is used to tell which logical
+// processors are online.
+func processorsGet(ctx context.Context, online cpuset.CPUSet) []*Processor {
 	paths := ghwpath.New(ctx)
 
 	lps := logicalProcessorsFromProcCPUInfo(ctx)
@@ -65,6 +112,17 @@ func processorsGet(ctx context.Context) []*Processor {
 			continue
 		}
 
+		lpOnline := logicalProcessorOnline(paths, online, lpID)
+		if !lpOnline {
+			// The kernel removes the topology of logical processors taken
+			// offline, leaving no way to tell which core and package they
+			// belong to. They are still listed in Info.Offline.
+			topoPath := filepath.Join(paths.SysDevicesSystemCPU, fname.Name(), "topology")
+			if _, err := os.Stat(topoPath); err != nil {
+				continue
+			}
+		}
+
 		procID := processorIDFromLogicalProcessorID(ctx, lpID)
 		proc, found := procs[procID]
 		if !found {
@@ -84,19 +142,36 @@ func processorsGet(ctx context.Context) []*Processor {
 			// can we drop /proc/cpuinfo.
 			if len(lp.Attrs["flags"]) != 0 { // x86
 				proc.Capabilities = strings.Split(lp.Attrs["flags"], " ")
+				proc.Features = features(proc.Capabilities, x86FlagFeatures)
+				proc.X86Level = x86Level(proc.Capabilities)
 			} else if len(lp.Attrs["Features"]) != 0 { // ARM64
 				proc.Capabilities = strings.Split(lp.Attrs["Features"], " ")
+				proc.Features = features(proc.Capabilities, armFeatureFeatures)
 			}
 			if len(lp.Attrs["model name"]) != 0 {
 				proc.Model = lp.Attrs["model name"]
 			} else if len(lp.Attrs["uarch"]) != 0 { // SiFive
 				proc.Model = lp.Attrs["uarch"]
 			}
+			proc.Microcode = lp.Attrs["microcode"]
+			proc.X86 = x86Identification(lp.Attrs)
+			proc.ARM = armIdentification(lp.Attrs)
+			if len(lp.Attrs["bogomips"]) != 0 { // x86
+				proc.BogoMIPS, _ = strconv.ParseFloat(lp.Attrs["bogomips"], 64)
+			} else if len(lp.Attrs["BogoMIPS"]) != 0 { // ARM64
+				proc.BogoMIPS, _ = strconv.ParseFloat(lp.Attrs["BogoMIPS"], 64)
+			}
+			proc.PhysicalAddressBits, proc.VirtualAddressBits = parseAddressSizes(lp.Attrs["address sizes"])
 			if len(lp.Attrs["vendor_id"]) != 0 {
 				proc.Vendor = lp.Attrs["vendor_id"]
 			} else if len(lp.Attrs["isa"]) != 0 { // RISCV64
 				proc.Vendor = lp.Attrs["isa"]
 			}
+			if proc.ARM != nil && proc.Vendor == "" {
+				// ARM processors do not report a vendor or model name. The
+				// model is named after the parts of all the cores below.
+				proc.Vendor = proc.ARM.ImplementerName
+			}
 			procs[procID] = proc
 		}
 
@@ -104,6 +179,9 @@ func processorsGet(ctx context.Context) []*Processor {
 		core := proc.CoreByID(coreID)
 		if core == nil {
 			core = &ProcessorCore{ID: coreID, NumThreads: 1}
+			if lp, ok := lps[lpID]; ok {
+				core.ARM = armIdentification(lp.Attrs)
+			}
 			proc.Cores = append(proc.Cores, core)
 			proc.NumCores += 1
 		} else {
@@ -111,17 +189,89 @@ func processorsGet(ctx context.Context) []*Processor {
 		}
 		proc.NumThreads += 1
 		core.LogicalProcessors = append(core.LogicalProcessors, lpID)
+		proc.LogicalProcessors = append(proc.LogicalProcessors, &LogicalProcessor{
+			ID:         lpID,
+			CoreID:     coreID,
+			Frequency:  logicalProcessorFrequency(paths, lpID),
+			Online:     lpOnline,
+			IdleStates: logicalProcessorIdleStates(paths, lpID),
+		})
 	}
+	lpIDs := []int{}
+	for _, p := range procs {
+		for _, lp := range p.LogicalProcessors {
+			lpIDs = append(lpIDs, lp.ID)
+		}
+	}
+	coreTypes := logicalProcessorCoreTypes(paths, lps, lpIDs)
 	res := []*Processor{}
 	for _, p := range procs {
 		for _, c := range p.Cores {
 			sort.Ints(c.LogicalProcessors)
+			// all the hardware threads of a core share its type
+			c.CoreType = coreTypes[c.LogicalProcessors[0]]
+			switch c.CoreType {
+			case CoreTypePerformance:
+				p.NumPerformanceCores++
+			case CoreTypeEfficiency:
+				p.NumEfficiencyCores++
+			}
+		}
+		sort.Slice(p.LogicalProcessors, func(i, j int) bool {
+			return p.LogicalProcessors[i].ID < p.LogicalProcessors[j].ID
+		})
+		if p.ARM != nil && p.Model == "" {
+			p.Model = armPartNames(p.Cores)
 		}
+		p.Frequency = frequencySummary(p.LogicalProcessors)
+		setProcessorCaches(ctx, p)
 		res = append(res, p)
 	}
+	setProcessorThermals(paths, res)
+	setProcessorRAPL(paths, res)
 	return res
 }
 
+// logicalProcessorOnline returns whether the supplied logical processor is
+// online, according to the supplied set of online logical processors or, if
+// the kernel did not report it, to /sys/devices/system/cpu/cpu{N}/online.
+// Logical processors that cannot be taken offline, like cpu0 on most x86
+// hosts, have no such file.
+func logicalProcessorOnline(paths *ghwpath.Paths, online cpuset.CPUSet, lpID int) bool {
+	if online != nil {
+		return online.Contains(lpID)
+	}
+	return util.ReadTrimmedFile(filepath.Join(paths.SysDevicesSystemCPU, fmt.Sprintf("cpu%d", lpID), "online")) != "0"
+}
+
+// setProcessorCaches sets the caches of the supplied processor and of its
+// cores
+func setProcessorCaches(ctx context.Context, p *Processor) {
+	lpIDs := make([]int, 0, len(p.LogicalProcessors))
+	for _, lp := range p.LogicalProcessors {
+		lpIDs = append(lpIDs, lp.ID)
+	}
+	caches, err := memory.CachesForLogicalProcessors(ctx, lpIDs)
+	if err != nil {
+		ghwcontext.Warn(ctx, "failed to read caches of processor %d: %s", p.ID, err)
+		return
+	}
+	if len(caches) == 0 {
+		return
+	}
+	p.Caches = caches
+	for _, c := range p.Cores {
+		for _, cache := range caches {
+			for _, lpID := range cache.LogicalProcessors {
+				if int(lpID) == c.LogicalProcessors[0] {
+					c.Caches = append(c.Caches, cache)
+					break
+				}
+			}
+		}
+	}
+}
+
 // processorIDFromLogicalProcessorID returns the processor physical package ID
 // for the supplied logical processor ID
 func processorIDFromLogicalProcessorID(ctx context.Context, lpID int) int {
@@ -9,11 +9,13 @@ package cpu_test
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/go-hardware/ghw/cmd/ghw/snapshot"
 	ghwcontext "github.com/go-hardware/ghw/pkg/context"
 	"github.com/go-hardware/ghw/pkg/cpu"
+	"github.com/go-hardware/ghw/pkg/cpuset"
 
 	"github.com/go-hardware/ghw/testdata"
 )
@@ -80,3 +82,106 @@ func TestArmCPU(t *testing.T) {
 		}
 	}
 }
+
+func TestCPUSets(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+	root := t.TempDir()
+	writeFakeCPUs(t, root, 4)
+
+	// cpu2 and cpu3 were taken offline, the kernel removed the topology of
+	// the latter only
+	cpuDir := filepath.Join(root, "sys", "devices", "system", "cpu")
+	_ = os.RemoveAll(filepath.Join(cpuDir, "cpu3", "topology"))
+	for name, list := range map[string]string{
+		"online":   "0-1",
+		"offline":  "2-3,4-7",
+		"possible": "0-7",
+		"present":  "0-3",
+		"isolated": "1",
+	} {
+		_ = os.WriteFile(filepath.Join(cpuDir, name), []byte(list+"\n"), 0644)
+	}
+
+	info, err := cpu.New(ghwcontext.New(ghwcontext.WithRootMountpoint(root)))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	expectedSets := map[string][2]cpuset.CPUSet{
+		"online":    {cpuset.CPUSet{0, 1}, info.Online},
+		"offline":   {cpuset.CPUSet{2, 3, 4, 5, 6, 7}, info.Offline},
+		"possible":  {cpuset.CPUSet{0, 1, 2, 3, 4, 5, 6, 7}, info.Possible},
+		"present":   {cpuset.CPUSet{0, 1, 2, 3}, info.Present},
+		"isolated":  {cpuset.CPUSet{1}, info.Isolated},
+		"nohz_full": {nil, info.NohzFull},
+	}
+	for name, sets := range expectedSets {
+		if !reflect.DeepEqual(sets[0], sets[1]) {
+			t.Fatalf("Expected %s %v but got %v", name, sets[0], sets[1])
+		}
+	}
+
+	if len(info.Processors) != 1 {
+		t.Fatalf("Expected 1 processor but got %d", len(info.Processors))
+	}
+	proc := info.Processors[0]
+	if proc.NumThreads != 3 {
+		t.Fatalf("Expected 3 threads but got %d", proc.NumThreads)
+	}
+	for lpID, online := range []bool{true, true, false} {
+		lp := proc.LogicalProcessorByID(lpID)
+		if lp == nil || lp.Online != online {
+			t.Fatalf("Expected logical processor %d online=%t but got %+v", lpID, online, lp)
+		}
+	}
+}
+
+func TestCgroupEffectiveCPUs(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+	root := t.TempDir()
+	writeFakeCPUs(t, root, 8)
+
+	// the cgroup may run on 4 of the 7 online logical processors, but only
+	// for 2.5 logical processors worth of CPU time
+	for path, contents := range map[string]string{
+		"proc/self/cgroup":                        "0::/ctr",
+		"sys/devices/system/cpu/online":           "0-6",
+		"sys/fs/cgroup/cgroup.controllers":        "cpuset cpu memory",
+		"sys/fs/cgroup/ctr/cpuset.cpus.effective": "0-3",
+		"sys/fs/cgroup/ctr/cpu.max":               "250000 100000",
+	} {
+		_ = os.MkdirAll(filepath.Dir(filepath.Join(root, path)), 0755)
+		_ = os.WriteFile(filepath.Join(root, path), []byte(contents+"\n"), 0644)
+	}
+
+	info, err := cpu.New(ghwcontext.New(ghwcontext.WithRootMountpoint(root)))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if info.Cgroup != nil || info.EffectiveCPUs != 0 {
+		t.Fatalf("Expected no cgroup limits unless asked for but got %+v", info.Cgroup)
+	}
+
+	info, err = cpu.New(ghwcontext.New(ghwcontext.WithRootMountpoint(root), ghwcontext.WithCgroup()))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if info.Cgroup == nil || info.Cgroup.Path != "/ctr" {
+		t.Fatalf("Expected the limits of cgroup /ctr but got %+v", info.Cgroup)
+	}
+	if info.EffectiveCPUs != 2.5 {
+		t.Fatalf("Expected 2.5 effective CPUs but got %v", info.EffectiveCPUs)
+	}
+
+	_ = os.Remove(filepath.Join(root, "sys", "fs", "cgroup", "ctr", "cpu.max"))
+	info, err = cpu.New(ghwcontext.New(ghwcontext.WithRootMountpoint(root), ghwcontext.WithCgroup()))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if info.EffectiveCPUs != 4 {
+		t.Fatalf("Expected 4 effective CPUs but got %v", info.EffectiveCPUs)
+	}
+}
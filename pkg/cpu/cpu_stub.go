@@ -11,6 +11,7 @@ package cpu
 import (
 	"context"
 	"runtime"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -18,3 +19,9 @@ import (
 func (i *Info) load(_ context.Context) error {
 	return errors.New("cpu.Info.load not implemented on " + runtime.GOOS)
 }
+
+// SamplePower returns the average power the RAPL domains of the host's
+// physical processors drew over the supplied interval
+func SamplePower(_ context.Context, _ time.Duration) ([]*PowerSample, error) {
+	return nil, errors.New("cpu.SamplePower not implemented on " + runtime.GOOS)
+}
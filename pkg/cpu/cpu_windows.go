@@ -10,6 +10,10 @@ package cpu
 
 import (
 	"context"
+	"runtime"
+	"time"
+
+	"github.com/pkg/errors"
 
 	"github.com/yusufpapurcu/wmi"
 )
@@ -42,6 +46,12 @@ func (i *Info) load(_ context.Context) error {
 	return nil
 }
 
+// SamplePower returns the average power the RAPL domains of the host's
+// physical processors drew over the supplied interval
+func SamplePower(_ context.Context, _ time.Duration) ([]*PowerSample, error) {
+	return nil, errors.New("cpu.SamplePower not implemented on " + runtime.GOOS)
+}
+
 func processorsGet(win32descriptions []win32Processor) []*Processor {
 	var procs []*Processor
 	// Converting into standard structures
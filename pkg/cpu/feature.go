@@ -0,0 +1,183 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Feature is a processor feature, named the same way whatever the
+// architecture. Unlike `Processor.Capabilities`, which holds the raw x86
+// `flags` or ARM64 `Features` reported by the kernel, e.g. "sha_ni" on x86
+// and "sha2" on ARM64, the same feature, e.g. `FeatureSHA256`, is reported
+// for both.
+type Feature int
+
+const (
+	// FeatureUnknown is never reported for a processor
+	FeatureUnknown Feature = iota
+
+	// Features found on several architectures
+	FeatureAES     // AES encryption instructions (x86 AES-NI, ARMv8 AES)
+	FeatureCLMUL   // carry-less multiplication (x86 PCLMULQDQ, ARMv8 PMULL)
+	FeatureSHA1    // SHA-1 instructions (x86 SHA-NI, ARMv8 SHA1)
+	FeatureSHA256  // SHA-256 instructions (x86 SHA-NI, ARMv8 SHA2)
+	FeatureSHA512  // SHA-512 instructions
+	FeatureSHA3    // SHA-3 instructions
+	FeatureCRC32   // CRC32 instructions (x86 SSE4.2, ARMv8 CRC32)
+	FeatureRNG     // hardware random number generator (x86 RDRAND, ARMv8.5 RNG)
+	FeatureBF16    // bfloat16 arithmetic
+	FeatureFP16    // half precision floating point arithmetic
+	FeatureDotProd // int8 dot product instructions
+	FeatureI8MM    // int8 matrix multiplication instructions
+	FeatureAtomics // atomic read-modify-write instructions (ARMv8.1 LSE)
+
+	// x86 features
+	FeatureSSE
+	FeatureSSE2
+	FeatureSSE3
+	FeatureSSSE3
+	FeatureSSE41
+	FeatureSSE42
+	FeaturePOPCNT
+	FeatureCX16 // CMPXCHG16B
+	FeatureLAHFSAHF
+	FeatureLZCNT
+	FeatureMOVBE
+	FeatureXSAVE
+	FeatureAVX
+	FeatureAVX2
+	FeatureFMA
+	FeatureF16C
+	FeatureBMI1
+	FeatureBMI2
+	FeatureVAES
+	FeatureVPCLMULQDQ
+	FeatureGFNI
+	FeatureAVXVNNI
+	FeatureAVX512F
+	FeatureAVX512CD
+	FeatureAVX512BW
+	FeatureAVX512DQ
+	FeatureAVX512VL
+	FeatureAVX512IFMA
+	FeatureAVX512VBMI
+	FeatureAVX512VBMI2
+	FeatureAVX512VNNI
+	FeatureAVX512BITALG
+	FeatureAVX512VPOPCNTDQ
+	FeatureAVX512BF16
+	FeatureAVX512FP16
+	FeatureAMXTile
+	FeatureAMXInt8
+	FeatureAMXBF16
+
+	// ARM64 features
+	FeatureASIMD // Advanced SIMD, a.k.a. NEON
+	FeatureSVE
+	FeatureSVE2
+)
+
+var (
+	featureString = map[Feature]string{
+		FeatureUnknown:         "Unknown",
+		FeatureAES:             "aes",
+		FeatureCLMUL:           "clmul",
+		FeatureSHA1:            "sha1",
+		FeatureSHA256:          "sha256",
+		FeatureSHA512:          "sha512",
+		FeatureSHA3:            "sha3",
+		FeatureCRC32:           "crc32",
+		FeatureRNG:             "rng",
+		FeatureBF16:            "bf16",
+		FeatureFP16:            "fp16",
+		FeatureDotProd:         "dotprod",
+		FeatureI8MM:            "i8mm",
+		FeatureAtomics:         "atomics",
+		FeatureSSE:             "sse",
+		FeatureSSE2:            "sse2",
+		FeatureSSE3:            "sse3",
+		FeatureSSSE3:           "ssse3",
+		FeatureSSE41:           "sse4.1",
+		FeatureSSE42:           "sse4.2",
+		FeaturePOPCNT:          "popcnt",
+		FeatureCX16:            "cx16",
+		FeatureLAHFSAHF:        "lahf_sahf",
+		FeatureLZCNT:           "lzcnt",
+		FeatureMOVBE:           "movbe",
+		FeatureXSAVE:           "xsave",
+		FeatureAVX:             "avx",
+		FeatureAVX2:            "avx2",
+		FeatureFMA:             "fma",
+		FeatureF16C:            "f16c",
+		FeatureBMI1:            "bmi1",
+		FeatureBMI2:            "bmi2",
+		FeatureVAES:            "vaes",
+		FeatureVPCLMULQDQ:      "vpclmulqdq",
+		FeatureGFNI:            "gfni",
+		FeatureAVXVNNI:         "avx_vnni",
+		FeatureAVX512F:         "avx512f",
+		FeatureAVX512CD:        "avx512cd",
+		FeatureAVX512BW:        "avx512bw",
+		FeatureAVX512DQ:        "avx512dq",
+		FeatureAVX512VL:        "avx512vl",
+		FeatureAVX512IFMA:      "avx512ifma",
+		FeatureAVX512VBMI:      "avx512vbmi",
+		FeatureAVX512VBMI2:     "avx512vbmi2",
+		FeatureAVX512VNNI:      "avx512vnni",
+		FeatureAVX512BITALG:    "avx512bitalg",
+		FeatureAVX512VPOPCNTDQ: "avx512vpopcntdq",
+		FeatureAVX512BF16:      "avx512bf16",
+		FeatureAVX512FP16:      "avx512fp16",
+		FeatureAMXTile:         "amx_tile",
+		FeatureAMXInt8:         "amx_int8",
+		FeatureAMXBF16:         "amx_bf16",
+		FeatureASIMD:           "asimd",
+		FeatureSVE:             "sve",
+		FeatureSVE2:            "sve2",
+	}
+
+	// NOTE: the keys are all lowercase and do not match the keys in the
+	// opposite table `featureString`. This is done because of the choice we
+	// made in Feature::MarshalJSON. We use this table only in UnmarshalJSON,
+	// so it should be OK.
+	stringFeature = map[string]Feature{}
+)
+
+func init() {
+	for f, s := range featureString {
+		stringFeature[strings.ToLower(s)] = f
+	}
+}
+
+func (f Feature) String() string {
+	return featureString[f]
+}
+
+// NOTE: since serialized output is as "official" as we're going to get,
+// let's lowercase the string output when serializing, in order to
+// "normalize" the expected serialized output
+func (f Feature) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strings.ToLower(f.String()))), nil
+}
+
+func (f *Feature) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	key := strings.ToLower(s)
+	val, ok := stringFeature[key]
+	if !ok {
+		return fmt.Errorf("unknown feature: %q", key)
+	}
+	*f = val
+	return nil
+}
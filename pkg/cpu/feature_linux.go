@@ -0,0 +1,134 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu
+
+import "sort"
+
+var (
+	// x86FlagFeatures maps the x86 `flags` of /proc/cpuinfo to the features
+	// they indicate
+	x86FlagFeatures = map[string][]Feature{
+		"aes":              {FeatureAES},
+		"pclmulqdq":        {FeatureCLMUL},
+		"sha_ni":           {FeatureSHA1, FeatureSHA256},
+		"sha512":           {FeatureSHA512},
+		"rdrand":           {FeatureRNG},
+		"sse":              {FeatureSSE},
+		"sse2":             {FeatureSSE2},
+		"pni":              {FeatureSSE3},
+		"ssse3":            {FeatureSSSE3},
+		"sse4_1":           {FeatureSSE41},
+		"sse4_2":           {FeatureSSE42, FeatureCRC32},
+		"popcnt":           {FeaturePOPCNT},
+		"cx16":             {FeatureCX16},
+		"lahf_lm":          {FeatureLAHFSAHF},
+		"abm":              {FeatureLZCNT},
+		"movbe":            {FeatureMOVBE},
+		"xsave":            {FeatureXSAVE},
+		"avx":              {FeatureAVX},
+		"avx2":             {FeatureAVX2},
+		"fma":              {FeatureFMA},
+		"f16c":             {FeatureF16C},
+		"bmi1":             {FeatureBMI1},
+		"bmi2":             {FeatureBMI2},
+		"vaes":             {FeatureVAES},
+		"vpclmulqdq":       {FeatureVPCLMULQDQ},
+		"gfni":             {FeatureGFNI},
+		"avx_vnni":         {FeatureAVXVNNI, FeatureDotProd},
+		"avx512f":          {FeatureAVX512F},
+		"avx512cd":         {FeatureAVX512CD},
+		"avx512bw":         {FeatureAVX512BW},
+		"avx512dq":         {FeatureAVX512DQ},
+		"avx512vl":         {FeatureAVX512VL},
+		"avx512ifma":       {FeatureAVX512IFMA},
+		"avx512vbmi":       {FeatureAVX512VBMI},
+		"avx512_vbmi2":     {FeatureAVX512VBMI2},
+		"avx512_vnni":      {FeatureAVX512VNNI, FeatureDotProd},
+		"avx512_bitalg":    {FeatureAVX512BITALG},
+		"avx512_vpopcntdq": {FeatureAVX512VPOPCNTDQ},
+		"avx512_bf16":      {FeatureAVX512BF16, FeatureBF16},
+		"avx512_fp16":      {FeatureAVX512FP16, FeatureFP16},
+		"amx_tile":         {FeatureAMXTile},
+		"amx_int8":         {FeatureAMXInt8},
+		"amx_bf16":         {FeatureAMXBF16},
+	}
+
+	// armFeatureFeatures maps the ARM64 `Features` of /proc/cpuinfo to the
+	// features they indicate
+	armFeatureFeatures = map[string][]Feature{
+		"aes":     {FeatureAES},
+		"pmull":   {FeatureCLMUL},
+		"sha1":    {FeatureSHA1},
+		"sha2":    {FeatureSHA256},
+		"sha512":  {FeatureSHA512},
+		"sha3":    {FeatureSHA3},
+		"crc32":   {FeatureCRC32},
+		"rng":     {FeatureRNG},
+		"bf16":    {FeatureBF16},
+		"fphp":    {FeatureFP16},
+		"asimdhp": {FeatureFP16},
+		"asimddp": {FeatureDotProd},
+		"i8mm":    {FeatureI8MM},
+		"atomics": {FeatureAtomics},
+		"asimd":   {FeatureASIMD},
+		"sve":     {FeatureSVE},
+		"sve2":    {FeatureSVE2},
+	}
+
+	// x86Levels lists, for each x86-64 microarchitecture level defined by
+	// the x86-64 psABI, the `flags` a processor needs on top of those of the
+	// previous level. /proc/cpuinfo does not report OSXSAVE, required by
+	// x86-64-v3, so XSAVE stands for it.
+	x86Levels = [][]string{
+		{"lm", "cmov", "cx8", "fpu", "fxsr", "mmx", "syscall", "sse", "sse2"},
+		{"cx16", "lahf_lm", "popcnt", "pni", "sse4_1", "sse4_2", "ssse3"},
+		{"avx", "avx2", "bmi1", "bmi2", "f16c", "fma", "abm", "movbe", "xsave"},
+		{"avx512f", "avx512bw", "avx512cd", "avx512dq", "avx512vl"},
+	}
+)
+
+// features returns the sorted features indicated by the supplied
+// capabilities, according to the supplied mapping
+func features(caps []string, mapping map[string][]Feature) []Feature {
+	found := map[Feature]bool{}
+	for _, c := range caps {
+		for _, f := range mapping[c] {
+			found[f] = true
+		}
+	}
+	if len(found) == 0 {
+		return nil
+	}
+	res := make([]Feature, 0, len(found))
+	for f := range found {
+		res = append(res, f)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i] < res[j]
+	})
+	return res
+}
+
+// x86Level returns the highest x86-64 microarchitecture level, from 1 to 4,
+// whose requirements the supplied x86 capabilities meet, or 0 if they do not
+// meet the requirements of the baseline x86-64 level
+func x86Level(caps []string) int {
+	has := make(map[string]bool, len(caps))
+	for _, c := range caps {
+		has[c] = true
+	}
+	level := 0
+	for _, flags := range x86Levels {
+		for _, flag := range flags {
+			if !has[flag] {
+				return level
+			}
+		}
+		level++
+	}
+	return level
+}
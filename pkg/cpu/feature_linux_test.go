@@ -0,0 +1,140 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	ghwcontext "github.com/go-hardware/ghw/pkg/context"
+	"github.com/go-hardware/ghw/pkg/cpu"
+)
+
+func TestFeatures(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+
+	baseline := "fpu vme de pse tsc msr pae mce cx8 apic sep mtrr pge mca cmov pat pse36 clflush mmx fxsr sse sse2 ht syscall nx lm"
+	v2 := baseline + " pni ssse3 cx16 sse4_1 sse4_2 popcnt lahf_lm"
+	v3 := v2 + " pclmulqdq aes xsave avx f16c fma movbe abm bmi1 avx2 bmi2 rdrand"
+	v4 := v3 + " avx512f avx512dq avx512cd avx512bw avx512vl sha_ni avx512_vnni"
+
+	tcs := []struct {
+		name             string
+		cpuinfo          string
+		expectedLevel    int
+		expectedFeatures []cpu.Feature
+	}{
+		{
+			name:          "32-bit x86",
+			cpuinfo:       "processor\t: 0\nvendor_id\t: GenuineIntel\nflags\t\t: fpu vme de pse tsc cx8 cmov mmx fxsr sse sse2\n\n",
+			expectedLevel: 0,
+			expectedFeatures: []cpu.Feature{
+				cpu.FeatureSSE, cpu.FeatureSSE2,
+			},
+		},
+		{
+			// x86-64-v3 requires AVX2 as well
+			name:          "x86-64-v2 with AVX",
+			cpuinfo:       "processor\t: 0\nvendor_id\t: GenuineIntel\nflags\t\t: " + v2 + " avx\n\n",
+			expectedLevel: 2,
+			expectedFeatures: []cpu.Feature{
+				cpu.FeatureCRC32,
+				cpu.FeatureSSE, cpu.FeatureSSE2, cpu.FeatureSSE3, cpu.FeatureSSSE3,
+				cpu.FeatureSSE41, cpu.FeatureSSE42, cpu.FeaturePOPCNT, cpu.FeatureCX16,
+				cpu.FeatureLAHFSAHF, cpu.FeatureAVX,
+			},
+		},
+		{
+			name:          "x86-64-v3",
+			cpuinfo:       "processor\t: 0\nvendor_id\t: AuthenticAMD\nflags\t\t: " + v3 + "\n\n",
+			expectedLevel: 3,
+		},
+		{
+			name:          "x86-64-v4",
+			cpuinfo:       "processor\t: 0\nvendor_id\t: GenuineIntel\nflags\t\t: " + v4 + "\n\n",
+			expectedLevel: 4,
+		},
+		{
+			name: "Neoverse-V1",
+			cpuinfo: "processor\t: 0\nBogoMIPS\t: 2100.00\n" +
+				"Features\t: fp asimd evtstrm aes pmull sha1 sha2 crc32 atomics fphp asimdhp cpuid asimdrdm jscvt fcma lrcpc dcpop sha3 sm3 sm4 asimddp sha512 sve asimdfhm dit uscat ilrcpc flagm ssbs paca pacg dcpodp svei8mm svebf16 i8mm bf16 dgh rng\n" +
+				"CPU implementer\t: 0x41\nCPU architecture: 8\nCPU variant\t: 0x1\nCPU part\t: 0xd40\nCPU revision\t: 1\n\n",
+			expectedLevel: 0,
+			expectedFeatures: []cpu.Feature{
+				cpu.FeatureAES, cpu.FeatureCLMUL, cpu.FeatureSHA1, cpu.FeatureSHA256,
+				cpu.FeatureSHA512, cpu.FeatureSHA3, cpu.FeatureCRC32, cpu.FeatureRNG,
+				cpu.FeatureBF16, cpu.FeatureFP16, cpu.FeatureDotProd, cpu.FeatureI8MM,
+				cpu.FeatureAtomics, cpu.FeatureASIMD, cpu.FeatureSVE,
+			},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFakeCPUs(t, root, 1)
+			_ = os.WriteFile(filepath.Join(root, "proc", "cpuinfo"), []byte(tc.cpuinfo), 0644)
+
+			info, err := cpu.New(ghwcontext.New(ghwcontext.WithRootMountpoint(root)))
+			if err != nil {
+				t.Fatalf("Expected nil err, but got %v", err)
+			}
+			proc := info.Processors[0]
+			if proc.X86Level != tc.expectedLevel {
+				t.Fatalf("Expected x86-64 level %d but got %d", tc.expectedLevel, proc.X86Level)
+			}
+			if tc.expectedFeatures != nil && !reflect.DeepEqual(tc.expectedFeatures, proc.Features) {
+				t.Fatalf("Expected:\n%v\nActual:\n%v\n", tc.expectedFeatures, proc.Features)
+			}
+		})
+	}
+
+	// the same feature is reported whatever the name of the flag
+	root := t.TempDir()
+	writeFakeCPUs(t, root, 1)
+	cpuinfo := "processor\t: 0\nvendor_id\t: GenuineIntel\nflags\t\t: " + v4 + "\n\n"
+	_ = os.WriteFile(filepath.Join(root, "proc", "cpuinfo"), []byte(cpuinfo), 0644)
+	info, err := cpu.New(ghwcontext.New(ghwcontext.WithRootMountpoint(root)))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	proc := info.Processors[0]
+	for _, f := range []cpu.Feature{cpu.FeatureSHA256, cpu.FeatureAVX512VNNI, cpu.FeatureDotProd} {
+		if !proc.HasFeature(f) {
+			t.Fatalf("Expected feature %s", f)
+		}
+	}
+	if proc.HasFeature(cpu.FeatureSVE) {
+		t.Fatalf("Expected no SVE on x86")
+	}
+}
+
+func TestFeatureJSON(t *testing.T) {
+	features := []cpu.Feature{cpu.FeatureSSE41, cpu.FeatureAVX512VPOPCNTDQ, cpu.FeatureSVE2}
+	b, err := json.Marshal(features)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if string(b) != `["sse4.1","avx512vpopcntdq","sve2"]` {
+		t.Fatalf("Unexpected JSON: %s", b)
+	}
+	var decoded []cpu.Feature
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if !reflect.DeepEqual(features, decoded) {
+		t.Fatalf("Expected:\n%v\nActual:\n%v\n", features, decoded)
+	}
+	var f cpu.Feature
+	if err := json.Unmarshal([]byte(`"avx1024"`), &f); err == nil {
+		t.Fatalf("Expected an error for an unknown feature")
+	}
+}
@@ -0,0 +1,73 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu
+
+// Frequency describes the frequency scaling (cpufreq) of a logical processor.
+// Frequencies are in kHz, like the kernel reports them.
+type Frequency struct {
+	// MinKHz is the lowest frequency the logical processor can run at
+	MinKHz uint64 `json:"min_khz"`
+	// MaxKHz is the highest frequency the logical processor can run at,
+	// including boost frequencies
+	MaxKHz uint64 `json:"max_khz"`
+	// BaseKHz is the base, or nominal, frequency of the logical processor,
+	// or 0 if the scaling driver does not report it
+	BaseKHz uint64 `json:"base_khz,omitempty"`
+	// CurrentKHz is the frequency the logical processor last ran at, as known
+	// to the kernel
+	CurrentKHz uint64 `json:"current_khz"`
+	// ScalingMinKHz and ScalingMaxKHz are the bounds the scaling governor
+	// keeps the frequency within
+	ScalingMinKHz uint64 `json:"scaling_min_khz"`
+	ScalingMaxKHz uint64 `json:"scaling_max_khz"`
+	// Driver is the name of the scaling driver, e.g. "intel_pstate" or
+	// "acpi-cpufreq"
+	Driver string `json:"driver"`
+	// Governor is the name of the scaling governor, e.g. "performance" or
+	// "schedutil"
+	Governor string `json:"governor"`
+	// AvailableGovernors contains the names of the scaling governors the
+	// logical processor can use
+	AvailableGovernors []string `json:"available_governors,omitempty"`
+	// EnergyPerformancePreference is the energy vs performance hint given to
+	// processors managing their frequency themselves (HWP on Intel, CPPC on
+	// AMD), e.g. "performance" or "balance_power", or empty if the scaling
+	// driver does not support it
+	EnergyPerformancePreference string `json:"energy_performance_preference,omitempty"`
+	// AvailableEnergyPerformancePreferences contains the energy vs
+	// performance hints the logical processor supports
+	AvailableEnergyPerformancePreferences []string `json:"available_energy_performance_preferences,omitempty"`
+	// BoostSupported is true if the scaling driver allows boost frequencies,
+	// e.g. Intel Turbo Boost or AMD Core Performance Boost, to be toggled
+	BoostSupported bool `json:"boost_supported"`
+	// BoostEnabled is true if boost frequencies are enabled
+	BoostEnabled bool `json:"boost_enabled"`
+}
+
+// FrequencySummary summarizes the frequency scaling of the logical processors
+// of a physical processor package.
+type FrequencySummary struct {
+	// MinKHz is the lowest frequency any logical processor can run at
+	MinKHz uint64 `json:"min_khz"`
+	// MaxKHz is the highest frequency any logical processor can run at
+	MaxKHz uint64 `json:"max_khz"`
+	// BaseKHz is the highest base frequency of the logical processors, or 0
+	// if the scaling driver does not report it
+	BaseKHz uint64 `json:"base_khz,omitempty"`
+	// Drivers contains the sorted names of the scaling drivers in use
+	Drivers []string `json:"drivers"`
+	// Governors contains the sorted names of the scaling governors in use.
+	// It contains a single name when all logical processors use the same
+	// governor.
+	Governors []string `json:"governors"`
+	// EnergyPerformancePreferences contains the sorted energy vs performance
+	// hints in use
+	EnergyPerformancePreferences []string `json:"energy_performance_preferences,omitempty"`
+	// BoostEnabled is true if boost frequencies are enabled for any logical
+	// processor
+	BoostEnabled bool `json:"boost_enabled"`
+}
@@ -0,0 +1,122 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+	"github.com/go-hardware/ghw/pkg/util"
+)
+
+// logicalProcessorFrequency returns the frequency scaling of the supplied
+// logical processor, found in /sys/devices/system/cpu/cpu{N}/cpufreq, which
+// links to the directory of the cpufreq policy the logical processor belongs
+// to. It returns nil if the logical processor has no cpufreq policy, e.g.
+// in virtual machines.
+func logicalProcessorFrequency(paths *ghwpath.Paths, lpID int) *Frequency {
+	cpuPath := filepath.Join(paths.SysDevicesSystemCPU, fmt.Sprintf("cpu%d", lpID))
+	policyPath := filepath.Join(cpuPath, "cpufreq")
+	if _, err := os.Stat(policyPath); err != nil {
+		return nil
+	}
+	freq := &Frequency{
+		MinKHz:                                util.ReadUint64File(filepath.Join(policyPath, "cpuinfo_min_freq")),
+		MaxKHz:                                util.ReadUint64File(filepath.Join(policyPath, "cpuinfo_max_freq")),
+		BaseKHz:                               util.ReadUint64File(filepath.Join(policyPath, "base_frequency")),
+		CurrentKHz:                            util.ReadUint64File(filepath.Join(policyPath, "scaling_cur_freq")),
+		ScalingMinKHz:                         util.ReadUint64File(filepath.Join(policyPath, "scaling_min_freq")),
+		ScalingMaxKHz:                         util.ReadUint64File(filepath.Join(policyPath, "scaling_max_freq")),
+		Driver:                                util.ReadTrimmedFile(filepath.Join(policyPath, "scaling_driver")),
+		Governor:                              util.ReadTrimmedFile(filepath.Join(policyPath, "scaling_governor")),
+		AvailableGovernors:                    strings.Fields(util.ReadTrimmedFile(filepath.Join(policyPath, "scaling_available_governors"))),
+		EnergyPerformancePreference:           util.ReadTrimmedFile(filepath.Join(policyPath, "energy_performance_preference")),
+		AvailableEnergyPerformancePreferences: strings.Fields(util.ReadTrimmedFile(filepath.Join(policyPath, "energy_performance_available_preferences"))),
+	}
+	if freq.BaseKHz == 0 {
+		// amd-pstate and other CPPC based drivers report the nominal
+		// frequency in MHz instead
+		freq.BaseKHz = util.ReadUint64File(filepath.Join(cpuPath, "acpi_cppc", "nominal_freq")) * 1000
+	}
+	freq.BoostSupported, freq.BoostEnabled = frequencyBoost(paths, policyPath)
+	return freq
+}
+
+// frequencyBoost returns whether the boost frequencies can be toggled and
+// whether they are enabled. Recent kernels report it for each cpufreq policy,
+// older ones for all of them at once, and intel_pstate through its own
+// inverted no_turbo setting.
+func frequencyBoost(paths *ghwpath.Paths, policyPath string) (bool, bool) {
+	for _, path := range []string{
+		filepath.Join(policyPath, "boost"),
+		filepath.Join(paths.SysDevicesSystemCPU, "cpufreq", "boost"),
+	} {
+		if val := util.ReadTrimmedFile(path); val != "" {
+			return true, val == "1"
+		}
+	}
+	if val := util.ReadTrimmedFile(filepath.Join(paths.SysDevicesSystemCPU, "intel_pstate", "no_turbo")); val != "" {
+		return true, val == "0"
+	}
+	return false, false
+}
+
+// frequencySummary summarizes the frequency scaling of the supplied logical
+// processors, returning nil if none of them supports frequency scaling
+func frequencySummary(lps []*LogicalProcessor) *FrequencySummary {
+	var summary *FrequencySummary
+	drivers := map[string]bool{}
+	governors := map[string]bool{}
+	epps := map[string]bool{}
+	for _, lp := range lps {
+		freq := lp.Frequency
+		if freq == nil {
+			continue
+		}
+		if summary == nil {
+			summary = &FrequencySummary{MinKHz: freq.MinKHz}
+		}
+		if freq.MinKHz < summary.MinKHz {
+			summary.MinKHz = freq.MinKHz
+		}
+		if freq.MaxKHz > summary.MaxKHz {
+			summary.MaxKHz = freq.MaxKHz
+		}
+		if freq.BaseKHz > summary.BaseKHz {
+			summary.BaseKHz = freq.BaseKHz
+		}
+		drivers[freq.Driver] = true
+		governors[freq.Governor] = true
+		if freq.EnergyPerformancePreference != "" {
+			epps[freq.EnergyPerformancePreference] = true
+		}
+		summary.BoostEnabled = summary.BoostEnabled || freq.BoostEnabled
+	}
+	if summary == nil {
+		return nil
+	}
+	summary.Drivers = sortedKeys(drivers)
+	summary.Governors = sortedKeys(governors)
+	if len(epps) > 0 {
+		summary.EnergyPerformancePreferences = sortedKeys(epps)
+	}
+	return summary
+}
+
+// sortedKeys returns the sorted keys of the supplied set
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
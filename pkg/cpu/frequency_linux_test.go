@@ -0,0 +1,150 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	ghwcontext "github.com/go-hardware/ghw/pkg/context"
+	"github.com/go-hardware/ghw/pkg/cpu"
+)
+
+// writeFakeCPUs creates a /proc/cpuinfo and the topology of the supplied
+// number of logical processors, one per core of a single package, below the
+// supplied root mountpoint
+func writeFakeCPUs(t *testing.T, root string, numLPs int) {
+	t.Helper()
+	cpuinfo := ""
+	for lpID := 0; lpID < numLPs; lpID++ {
+		cpuinfo += fmt.Sprintf(
+			"processor\t: %d\nvendor_id\t: GenuineIntel\nmodel name\t: Intel(R) Xeon(R) Gold 6338N CPU @ 2.20GHz\nmicrocode\t: 0xd0003a5\nflags\t\t: fpu vme sse sse2\n\n",
+			lpID,
+		)
+		topoDir := filepath.Join(root, "sys", "devices", "system", "cpu", fmt.Sprintf("cpu%d", lpID), "topology")
+		if err := os.MkdirAll(topoDir, 0755); err != nil {
+			t.Fatalf("Expected nil err, but got %v", err)
+		}
+		_ = os.WriteFile(filepath.Join(topoDir, "physical_package_id"), []byte("0\n"), 0644)
+		_ = os.WriteFile(filepath.Join(topoDir, "core_id"), []byte(fmt.Sprintf("%d\n", lpID)), 0644)
+	}
+	_ = os.MkdirAll(filepath.Join(root, "proc"), 0755)
+	_ = os.WriteFile(filepath.Join(root, "proc", "cpuinfo"), []byte(cpuinfo), 0644)
+}
+
+func TestFrequency(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+	root := t.TempDir()
+	writeFakeCPUs(t, root, 2)
+
+	// intel_pstate in active mode, with a cpufreq policy per logical
+	// processor and turbo enabled
+	cpuDir := filepath.Join(root, "sys", "devices", "system", "cpu")
+	policies := map[int]map[string]string{
+		0: {
+			"scaling_governor":              "performance",
+			"scaling_cur_freq":              "3199992",
+			"energy_performance_preference": "performance",
+		},
+		1: {
+			"scaling_governor":              "powersave",
+			"scaling_cur_freq":              "800000",
+			"energy_performance_preference": "balance_power",
+		},
+	}
+	for lpID, attrs := range policies {
+		policyDir := filepath.Join(cpuDir, "cpufreq", fmt.Sprintf("policy%d", lpID))
+		_ = os.MkdirAll(policyDir, 0755)
+		attrs["cpuinfo_min_freq"] = "800000"
+		attrs["cpuinfo_max_freq"] = "3200000"
+		attrs["base_frequency"] = "2200000"
+		attrs["scaling_min_freq"] = "800000"
+		attrs["scaling_max_freq"] = "3200000"
+		attrs["scaling_driver"] = "intel_pstate"
+		attrs["scaling_available_governors"] = "performance powersave"
+		attrs["energy_performance_available_preferences"] = "default performance balance_performance balance_power power"
+		for name, val := range attrs {
+			_ = os.WriteFile(filepath.Join(policyDir, name), []byte(val+"\n"), 0644)
+		}
+		_ = os.Symlink(
+			filepath.Join("..", "cpufreq", fmt.Sprintf("policy%d", lpID)),
+			filepath.Join(cpuDir, fmt.Sprintf("cpu%d", lpID), "cpufreq"),
+		)
+	}
+	_ = os.MkdirAll(filepath.Join(cpuDir, "intel_pstate"), 0755)
+	_ = os.WriteFile(filepath.Join(cpuDir, "intel_pstate", "no_turbo"), []byte("0\n"), 0644)
+
+	info, err := cpu.New(ghwcontext.New(ghwcontext.WithRootMountpoint(root)))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if len(info.Processors) != 1 {
+		t.Fatalf("Expected 1 processor but got %d", len(info.Processors))
+	}
+	proc := info.Processors[0]
+	if len(proc.LogicalProcessors) != 2 {
+		t.Fatalf("Expected 2 logical processors but got %d", len(proc.LogicalProcessors))
+	}
+
+	expected := &cpu.Frequency{
+		MinKHz:                                800000,
+		MaxKHz:                                3200000,
+		BaseKHz:                               2200000,
+		CurrentKHz:                            800000,
+		ScalingMinKHz:                         800000,
+		ScalingMaxKHz:                         3200000,
+		Driver:                                "intel_pstate",
+		Governor:                              "powersave",
+		AvailableGovernors:                    []string{"performance", "powersave"},
+		EnergyPerformancePreference:           "balance_power",
+		AvailableEnergyPerformancePreferences: []string{"default", "performance", "balance_performance", "balance_power", "power"},
+		BoostSupported:                        true,
+		BoostEnabled:                          true,
+	}
+	lp := proc.LogicalProcessorByID(1)
+	if lp == nil || lp.CoreID != 1 {
+		t.Fatalf("Expected logical processor 1 on core 1 but got %+v", lp)
+	}
+	if !reflect.DeepEqual(expected, lp.Frequency) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", expected, lp.Frequency)
+	}
+
+	expectedSummary := &cpu.FrequencySummary{
+		MinKHz:                       800000,
+		MaxKHz:                       3200000,
+		BaseKHz:                      2200000,
+		Drivers:                      []string{"intel_pstate"},
+		Governors:                    []string{"performance", "powersave"},
+		EnergyPerformancePreferences: []string{"balance_power", "performance"},
+		BoostEnabled:                 true,
+	}
+	if !reflect.DeepEqual(expectedSummary, proc.Frequency) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", expectedSummary, proc.Frequency)
+	}
+}
+
+func TestFrequencyUnsupported(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+	root := t.TempDir()
+	writeFakeCPUs(t, root, 1)
+
+	info, err := cpu.New(ghwcontext.New(ghwcontext.WithRootMountpoint(root)))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	proc := info.Processors[0]
+	if proc.Frequency != nil || proc.LogicalProcessors[0].Frequency != nil {
+		t.Fatalf("Expected no frequency information without cpufreq but got %+v", proc.Frequency)
+	}
+}
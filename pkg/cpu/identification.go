@@ -0,0 +1,66 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu
+
+import "fmt"
+
+// X86Identification contains the CPUID signature of an x86 processor, which
+// tells processor generations apart more reliably than model names do.
+type X86Identification struct {
+	// Family is the processor family, e.g. 6 for most Intel Core and Xeon
+	// processors or 25 (0x19) for AMD Zen 3 and Zen 4 processors
+	Family int `json:"family"`
+	// Model is the model number within the family, e.g. 143 (0x8f) for
+	// Intel Sapphire Rapids
+	Model int `json:"model"`
+	// Stepping is the revision of the model
+	Stepping int `json:"stepping"`
+}
+
+// String returns a short string describing the X86Identification
+func (x *X86Identification) String() string {
+	return fmt.Sprintf("family %d model %d stepping %d", x.Family, x.Model, x.Stepping)
+}
+
+// ARMIdentification contains the identification of an ARM processor, as read
+// from its Main ID Register (MIDR).
+type ARMIdentification struct {
+	// Implementer is the code of the company that designed the processor
+	// core, e.g. 0x41 for ARM Ltd
+	Implementer int `json:"implementer"`
+	// ImplementerName is the name of the company that designed the processor
+	// core, e.g. "ARM", or empty if the implementer code is not known to ghw
+	ImplementerName string `json:"implementer_name,omitempty"`
+	// Architecture is the ARM architecture version, e.g. 8
+	Architecture int `json:"architecture"`
+	// Part is the implementer specific part number of the processor core,
+	// e.g. 0xd0c
+	Part int `json:"part"`
+	// PartName is the name of the processor core, e.g. "Neoverse-N1", or
+	// empty if the part number is not known to ghw
+	PartName string `json:"part_name,omitempty"`
+	// Variant is the major revision of the processor core, the "r" in the
+	// "r3p1" revisions ARM uses
+	Variant int `json:"variant"`
+	// Revision is the minor revision of the processor core, the "p" in the
+	// "r3p1" revisions ARM uses
+	Revision int `json:"revision"`
+}
+
+// String returns a short string describing the ARMIdentification, e.g.
+// "ARM Neoverse-N1 r3p1"
+func (a *ARMIdentification) String() string {
+	implementer := a.ImplementerName
+	if implementer == "" {
+		implementer = fmt.Sprintf("implementer 0x%02x", a.Implementer)
+	}
+	part := a.PartName
+	if part == "" {
+		part = fmt.Sprintf("part 0x%03x", a.Part)
+	}
+	return fmt.Sprintf("%s %s r%dp%d", implementer, part, a.Variant, a.Revision)
+}
@@ -0,0 +1,204 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// armImplementers contains the names of the companies designing ARM
+// processor cores, keyed by "CPU implementer" code
+var armImplementers = map[int]string{
+	0x41: "ARM",
+	0x42: "Broadcom",
+	0x43: "Cavium",
+	0x46: "Fujitsu",
+	0x48: "HiSilicon",
+	0x4e: "NVIDIA",
+	0x50: "APM",
+	0x51: "Qualcomm",
+	0x53: "Samsung",
+	0x56: "Marvell",
+	0x61: "Apple",
+	0x69: "Intel",
+	0x6d: "Microsoft",
+	0xc0: "Ampere",
+}
+
+// armParts contains the names of ARM processor cores, keyed by "CPU
+// implementer" code, then by "CPU part" number
+var armParts = map[int]map[int]string{
+	0x41: {
+		0xc05: "Cortex-A5",
+		0xc07: "Cortex-A7",
+		0xc08: "Cortex-A8",
+		0xc09: "Cortex-A9",
+		0xc0d: "Cortex-A12",
+		0xc0f: "Cortex-A15",
+		0xc0e: "Cortex-A17",
+		0xd01: "Cortex-A32",
+		0xd02: "Cortex-A34",
+		0xd03: "Cortex-A53",
+		0xd04: "Cortex-A35",
+		0xd05: "Cortex-A55",
+		0xd06: "Cortex-A65",
+		0xd07: "Cortex-A57",
+		0xd08: "Cortex-A72",
+		0xd09: "Cortex-A73",
+		0xd0a: "Cortex-A75",
+		0xd0b: "Cortex-A76",
+		0xd0c: "Neoverse-N1",
+		0xd0d: "Cortex-A77",
+		0xd0e: "Cortex-A76AE",
+		0xd40: "Neoverse-V1",
+		0xd41: "Cortex-A78",
+		0xd42: "Cortex-A78AE",
+		0xd43: "Cortex-A65AE",
+		0xd44: "Cortex-X1",
+		0xd46: "Cortex-A510",
+		0xd47: "Cortex-A710",
+		0xd48: "Cortex-X2",
+		0xd49: "Neoverse-N2",
+		0xd4a: "Neoverse-E1",
+		0xd4b: "Cortex-A78C",
+		0xd4c: "Cortex-X1C",
+		0xd4d: "Cortex-A715",
+		0xd4e: "Cortex-X3",
+		0xd4f: "Neoverse-V2",
+		0xd80: "Cortex-A520",
+		0xd81: "Cortex-A720",
+		0xd82: "Cortex-X4",
+		0xd84: "Neoverse-V3",
+		0xd85: "Cortex-X925",
+		0xd87: "Cortex-A725",
+		0xd8e: "Neoverse-N3",
+	},
+	0x42: {
+		0x516: "Vulcan",
+	},
+	0x43: {
+		0x0a0: "ThunderX",
+		0x0a1: "ThunderX-88XX",
+		0x0a2: "ThunderX-81XX",
+		0x0a3: "ThunderX-83XX",
+		0x0af: "ThunderX2-99xx",
+	},
+	0x46: {
+		0x001: "A64FX",
+	},
+	0x48: {
+		0xd01: "TaiShan-v110",
+		0xd02: "TaiShan-v120",
+	},
+	0x4e: {
+		0x003: "Denver 2",
+		0x004: "Carmel",
+	},
+	0x51: {
+		0x800: "Kryo-2XX-Gold",
+		0x801: "Kryo-2XX-Silver",
+		0x803: "Kryo-3XX-Silver",
+		0x804: "Kryo-4XX-Gold",
+		0x805: "Kryo-4XX-Silver",
+		0xc00: "Falkor",
+		0xc01: "Saphira",
+	},
+	0xc0: {
+		0xac3: "Ampere-1",
+		0xac4: "Ampere-1a",
+	},
+}
+
+// x86Identification returns the CPUID signature found in the supplied
+// /proc/cpuinfo attributes, or nil if they do not describe an x86 processor
+func x86Identification(attrs map[string]string) *X86Identification {
+	family, err := strconv.Atoi(attrs["cpu family"])
+	if err != nil {
+		return nil
+	}
+	model, _ := strconv.Atoi(attrs["model"])
+	stepping, _ := strconv.Atoi(attrs["stepping"])
+	return &X86Identification{
+		Family:   family,
+		Model:    model,
+		Stepping: stepping,
+	}
+}
+
+// armIdentification returns the identification found in the supplied
+// /proc/cpuinfo attributes, or nil if they do not describe an ARM processor
+func armIdentification(attrs map[string]string) *ARMIdentification {
+	implementer, err := strconv.ParseInt(attrs["CPU implementer"], 0, 64)
+	if err != nil {
+		return nil
+	}
+	arch, _ := strconv.ParseInt(attrs["CPU architecture"], 0, 64)
+	part, _ := strconv.ParseInt(attrs["CPU part"], 0, 64)
+	variant, _ := strconv.ParseInt(attrs["CPU variant"], 0, 64)
+	revision, _ := strconv.ParseInt(attrs["CPU revision"], 0, 64)
+	return &ARMIdentification{
+		Implementer:     int(implementer),
+		ImplementerName: armImplementers[int(implementer)],
+		Architecture:    int(arch),
+		Part:            int(part),
+		PartName:        armParts[int(implementer)][int(part)],
+		Variant:         int(variant),
+		Revision:        int(revision),
+	}
+}
+
+// armPartNames returns the distinct part names of the supplied ARM cores,
+// e.g. "Cortex-A76 + Cortex-A55" for a big.LITTLE processor, ordered from the
+// part with the highest number, usually the biggest core. Parts unknown to
+// ghw are named after their number, e.g. "part 0xd4b".
+func armPartNames(cores []*ProcessorCore) string {
+	// the same part number means different parts from different implementers
+	type armPart struct {
+		implementer int
+		part        int
+	}
+	parts := map[armPart]string{}
+	for _, c := range cores {
+		if c.ARM == nil {
+			continue
+		}
+		name := c.ARM.PartName
+		if name == "" {
+			name = fmt.Sprintf("part 0x%03x", c.ARM.Part)
+		}
+		parts[armPart{c.ARM.Implementer, c.ARM.Part}] = name
+	}
+	keys := make([]armPart, 0, len(parts))
+	for key := range parts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].part != keys[j].part {
+			return keys[i].part > keys[j].part
+		}
+		return keys[i].implementer < keys[j].implementer
+	})
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		names = append(names, parts[key])
+	}
+	return strings.Join(names, " + ")
+}
+
+// parseAddressSizes parses the "address sizes" attribute of /proc/cpuinfo on
+// x86, like "46 bits physical, 57 bits virtual", returning the number of
+// physical and virtual address bits
+func parseAddressSizes(sizes string) (uint32, uint32) {
+	var physical, virtual uint32
+	if _, err := fmt.Sscanf(strings.TrimSpace(sizes), "%d bits physical, %d bits virtual", &physical, &virtual); err != nil {
+		return 0, 0
+	}
+	return physical, virtual
+}
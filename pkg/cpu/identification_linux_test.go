@@ -0,0 +1,176 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-hardware/ghw/cmd/ghw/snapshot"
+	ghwcontext "github.com/go-hardware/ghw/pkg/context"
+	"github.com/go-hardware/ghw/pkg/cpu"
+
+	"github.com/go-hardware/ghw/testdata"
+)
+
+func TestX86Identification(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+	root := t.TempDir()
+	writeFakeCPUs(t, root, 1)
+	cpuinfo := "processor\t: 0\nvendor_id\t: GenuineIntel\ncpu family\t: 6\nmodel\t\t: 143\n" +
+		"model name\t: Intel(R) Xeon(R) Platinum 8480+\nstepping\t: 8\nmicrocode\t: 0x2b000590\n" +
+		"bogomips\t: 4000.00\naddress sizes\t: 46 bits physical, 57 bits virtual\n\n"
+	_ = os.WriteFile(filepath.Join(root, "proc", "cpuinfo"), []byte(cpuinfo), 0644)
+
+	info, err := cpu.New(ghwcontext.New(ghwcontext.WithRootMountpoint(root)))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	proc := info.Processors[0]
+	expected := &cpu.X86Identification{Family: 6, Model: 143, Stepping: 8}
+	if !reflect.DeepEqual(expected, proc.X86) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", expected, proc.X86)
+	}
+	if proc.ARM != nil {
+		t.Fatalf("Expected no ARM identification but got %+v", proc.ARM)
+	}
+	if proc.BogoMIPS != 4000 {
+		t.Fatalf("Expected 4000 BogoMIPS but got %v", proc.BogoMIPS)
+	}
+	if proc.PhysicalAddressBits != 46 || proc.VirtualAddressBits != 57 {
+		t.Fatalf(
+			"Expected 46 physical and 57 virtual address bits but got %d and %d",
+			proc.PhysicalAddressBits, proc.VirtualAddressBits,
+		)
+	}
+}
+
+func TestARMIdentification(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+
+	testdataPath, err := testdata.SnapshotsDirectory()
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	armSnapshot := filepath.Join(testdataPath, "linux-arm64-c288e0776090cd558ef793b2a4e61939.tar.gz")
+	root := t.TempDir()
+	if err := snapshot.Expand(armSnapshot, root); err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+
+	info, err := cpu.New(ghwcontext.New(ghwcontext.WithRootMountpoint(root)))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	proc := info.Processors[0]
+	expected := &cpu.ARMIdentification{
+		Implementer:     0x41,
+		ImplementerName: "ARM",
+		Architecture:    8,
+		Part:            0xd0c,
+		PartName:        "Neoverse-N1",
+		Variant:         3,
+		Revision:        1,
+	}
+	if !reflect.DeepEqual(expected, proc.ARM) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", expected, proc.ARM)
+	}
+	if s := proc.ARM.String(); s != "ARM Neoverse-N1 r3p1" {
+		t.Fatalf("Expected \"ARM Neoverse-N1 r3p1\" but got %q", s)
+	}
+	if proc.X86 != nil {
+		t.Fatalf("Expected no x86 identification but got %+v", proc.X86)
+	}
+	if proc.Vendor != "ARM" || proc.Model != "Neoverse-N1" {
+		t.Fatalf("Expected vendor ARM and model Neoverse-N1 but got %q and %q", proc.Vendor, proc.Model)
+	}
+	if proc.BogoMIPS != 243.75 {
+		t.Fatalf("Expected 243.75 BogoMIPS but got %v", proc.BogoMIPS)
+	}
+}
+
+func TestARMIdentificationMixedParts(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+	root := t.TempDir()
+	writeFakeCPUs(t, root, 4)
+	// a big.LITTLE processor with two Cortex-A55 and two Cortex-A76 cores
+	cpuinfo := ""
+	for lpID, part := range []string{"0xd05", "0xd05", "0xd0b", "0xd0b"} {
+		cpuinfo += fmt.Sprintf(
+			"processor\t: %d\nBogoMIPS\t: 52.00\nFeatures\t: fp asimd\n"+
+				"CPU implementer\t: 0x41\nCPU architecture: 8\nCPU variant\t: 0x1\n"+
+				"CPU part\t: %s\nCPU revision\t: 0\n\n",
+			lpID, part,
+		)
+	}
+	_ = os.WriteFile(filepath.Join(root, "proc", "cpuinfo"), []byte(cpuinfo), 0644)
+
+	info, err := cpu.New(ghwcontext.New(ghwcontext.WithRootMountpoint(root)))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	proc := info.Processors[0]
+	if proc.Model != "Cortex-A76 + Cortex-A55" {
+		t.Fatalf("Expected model \"Cortex-A76 + Cortex-A55\" but got %q", proc.Model)
+	}
+	if proc.ARM == nil || proc.ARM.PartName != "Cortex-A55" {
+		t.Fatalf("Expected the first core to be a Cortex-A55 but got %+v", proc.ARM)
+	}
+	expectedParts := map[int]string{
+		0: "Cortex-A55",
+		1: "Cortex-A55",
+		2: "Cortex-A76",
+		3: "Cortex-A76",
+	}
+	for coreID, expected := range expectedParts {
+		core := proc.CoreByID(coreID)
+		if core == nil || core.ARM == nil {
+			t.Fatalf("Expected core %d to be identified but got %+v", coreID, core)
+		}
+		if core.ARM.PartName != expected {
+			t.Fatalf("Expected core %d to be a %s but got %s", coreID, expected, core.ARM.PartName)
+		}
+	}
+}
+
+func TestARMIdentificationUnknownParts(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+	root := t.TempDir()
+	writeFakeCPUs(t, root, 3)
+	// the same part number names different parts for ARM and HiSilicon,
+	// while the last part is unknown to ghw
+	cpuinfo := ""
+	for lpID, id := range [][2]string{{"0x41", "0xd01"}, {"0x48", "0xd01"}, {"0x41", "0xfff"}} {
+		cpuinfo += fmt.Sprintf(
+			"processor\t: %d\nBogoMIPS\t: 52.00\nFeatures\t: fp asimd\n"+
+				"CPU implementer\t: %s\nCPU architecture: 8\nCPU variant\t: 0x1\n"+
+				"CPU part\t: %s\nCPU revision\t: 0\n\n",
+			lpID, id[0], id[1],
+		)
+	}
+	_ = os.WriteFile(filepath.Join(root, "proc", "cpuinfo"), []byte(cpuinfo), 0644)
+
+	info, err := cpu.New(ghwcontext.New(ghwcontext.WithRootMountpoint(root)))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	expected := "part 0xfff + Cortex-A32 + TaiShan-v110"
+	if model := info.Processors[0].Model; model != expected {
+		t.Fatalf("Expected model %q but got %q", expected, model)
+	}
+}
@@ -0,0 +1,60 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu
+
+// Idle describes the idle management (cpuidle) of the host's logical
+// processors.
+type Idle struct {
+	// Driver is the name of the cpuidle driver, e.g. "intel_idle" or
+	// "acpi_idle", or "none" if no driver manages the idle states
+	Driver string `json:"driver"`
+	// Governor is the name of the cpuidle governor picking the idle states,
+	// e.g. "menu" or "teo"
+	Governor string `json:"governor"`
+	// AvailableGovernors contains the names of the cpuidle governors the
+	// kernel supports
+	AvailableGovernors []string `json:"available_governors,omitempty"`
+}
+
+// IdleState describes an idle state (C-state) a logical processor may enter
+// when it has nothing to run. Deeper states save more power, but take longer
+// to exit.
+type IdleState struct {
+	// Index is the index of the idle state, 0 being the shallowest
+	Index int `json:"index"`
+	// Name is the name of the idle state, e.g. "POLL", "C1E" or "C6"
+	Name string `json:"name"`
+	// Description is the description of the idle state, e.g. "MWAIT 0x20"
+	Description string `json:"description"`
+	// LatencyMicros is the time, in microseconds, the logical processor
+	// takes to exit the idle state
+	LatencyMicros uint64 `json:"latency_us"`
+	// TargetResidencyMicros is the minimum time, in microseconds, the
+	// logical processor should stay in the idle state for entering it to be
+	// worth it
+	TargetResidencyMicros uint64 `json:"target_residency_us"`
+	// Disabled is true if the idle state was disabled, so the logical
+	// processor never enters it
+	Disabled bool `json:"disabled"`
+	// Usage is the number of times the logical processor entered the idle
+	// state
+	Usage uint64 `json:"usage"`
+	// TimeMicros is the total time, in microseconds, the logical processor
+	// spent in the idle state
+	TimeMicros uint64 `json:"time_us"`
+}
+
+// PStateDriver describes the status of a scaling driver letting the
+// processor manage its own performance states (P-states), i.e. intel_pstate
+// or amd_pstate.
+type PStateDriver struct {
+	// Name is the name of the driver, "intel_pstate" or "amd_pstate"
+	Name string `json:"name"`
+	// Status is the operation mode of the driver, e.g. "active", "passive",
+	// "guided" (amd_pstate only) or "off"
+	Status string `json:"status"`
+}
@@ -0,0 +1,88 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+	"github.com/go-hardware/ghw/pkg/util"
+)
+
+// idle returns the cpuidle driver and governor found in
+// /sys/devices/system/cpu/cpuidle, or nil if the kernel does not support
+// cpuidle
+func idle(paths *ghwpath.Paths) *Idle {
+	idlePath := filepath.Join(paths.SysDevicesSystemCPU, "cpuidle")
+	driver := util.ReadTrimmedFile(filepath.Join(idlePath, "current_driver"))
+	if driver == "" {
+		return nil
+	}
+	governor := util.ReadTrimmedFile(filepath.Join(idlePath, "current_governor"))
+	if governor == "" {
+		// older kernels do not allow to change the governor
+		governor = util.ReadTrimmedFile(filepath.Join(idlePath, "current_governor_ro"))
+	}
+	return &Idle{
+		Driver:             driver,
+		Governor:           governor,
+		AvailableGovernors: strings.Fields(util.ReadTrimmedFile(filepath.Join(idlePath, "available_governors"))),
+	}
+}
+
+// logicalProcessorIdleStates returns the idle states of the supplied logical
+// processor, found in /sys/devices/system/cpu/cpu{N}/cpuidle/state{M},
+// ordered by index
+func logicalProcessorIdleStates(paths *ghwpath.Paths, lpID int) []*IdleState {
+	idlePath := filepath.Join(paths.SysDevicesSystemCPU, fmt.Sprintf("cpu%d", lpID), "cpuidle")
+	entries, err := os.ReadDir(idlePath)
+	if err != nil {
+		return nil
+	}
+	var states []*IdleState
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), "state") {
+			continue
+		}
+		index, err := strconv.Atoi(strings.TrimPrefix(entry.Name(), "state"))
+		if err != nil {
+			continue
+		}
+		statePath := filepath.Join(idlePath, entry.Name())
+		states = append(states, &IdleState{
+			Index:                 index,
+			Name:                  util.ReadTrimmedFile(filepath.Join(statePath, "name")),
+			Description:           util.ReadTrimmedFile(filepath.Join(statePath, "desc")),
+			LatencyMicros:         util.ReadUint64File(filepath.Join(statePath, "latency")),
+			TargetResidencyMicros: util.ReadUint64File(filepath.Join(statePath, "residency")),
+			Disabled:              util.ReadTrimmedFile(filepath.Join(statePath, "disable")) == "1",
+			Usage:                 util.ReadUint64File(filepath.Join(statePath, "usage")),
+			TimeMicros:            util.ReadUint64File(filepath.Join(statePath, "time")),
+		})
+	}
+	// os.ReadDir sorts state10 before state2
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Index < states[j].Index
+	})
+	return states
+}
+
+// pstateDriver returns the status of the intel_pstate or amd_pstate driver,
+// or nil if neither is loaded
+func pstateDriver(paths *ghwpath.Paths) *PStateDriver {
+	for _, name := range []string{"intel_pstate", "amd_pstate"} {
+		if status := util.ReadTrimmedFile(filepath.Join(paths.SysDevicesSystemCPU, name, "status")); status != "" {
+			return &PStateDriver{Name: name, Status: status}
+		}
+	}
+	return nil
+}
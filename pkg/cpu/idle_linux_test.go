@@ -0,0 +1,88 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	ghwcontext "github.com/go-hardware/ghw/pkg/context"
+	"github.com/go-hardware/ghw/pkg/cpu"
+)
+
+func TestIdle(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+	root := t.TempDir()
+	writeFakeCPUs(t, root, 1)
+
+	// intel_idle with C6 disabled, as done on latency sensitive hosts
+	cpuDir := filepath.Join(root, "sys", "devices", "system", "cpu")
+	states := []map[string]string{
+		{"name": "POLL", "desc": "CPUIDLE CORE POLL IDLE", "latency": "0", "residency": "0", "disable": "0", "usage": "1024", "time": "2048"},
+		{"name": "C1", "desc": "MWAIT 0x00", "latency": "2", "residency": "2", "disable": "0", "usage": "4096", "time": "81920"},
+		{"name": "C1E", "desc": "MWAIT 0x01", "latency": "10", "residency": "20", "disable": "0", "usage": "512", "time": "40960"},
+		{"name": "C6", "desc": "MWAIT 0x20", "latency": "170", "residency": "600", "disable": "1", "usage": "0", "time": "0"},
+	}
+	for idx, attrs := range states {
+		stateDir := filepath.Join(cpuDir, "cpu0", "cpuidle", fmt.Sprintf("state%d", idx))
+		_ = os.MkdirAll(stateDir, 0755)
+		for name, val := range attrs {
+			_ = os.WriteFile(filepath.Join(stateDir, name), []byte(val+"\n"), 0644)
+		}
+	}
+	for path, val := range map[string]string{
+		"cpuidle/current_driver":      "intel_idle",
+		"cpuidle/current_governor":    "menu",
+		"cpuidle/available_governors": "ladder menu teo",
+		"intel_pstate/status":         "active",
+	} {
+		_ = os.MkdirAll(filepath.Dir(filepath.Join(cpuDir, path)), 0755)
+		_ = os.WriteFile(filepath.Join(cpuDir, path), []byte(val+"\n"), 0644)
+	}
+
+	info, err := cpu.New(ghwcontext.New(ghwcontext.WithRootMountpoint(root)))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+
+	expectedIdle := &cpu.Idle{
+		Driver:             "intel_idle",
+		Governor:           "menu",
+		AvailableGovernors: []string{"ladder", "menu", "teo"},
+	}
+	if !reflect.DeepEqual(expectedIdle, info.Idle) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", expectedIdle, info.Idle)
+	}
+	expectedPState := &cpu.PStateDriver{Name: "intel_pstate", Status: "active"}
+	if !reflect.DeepEqual(expectedPState, info.PStateDriver) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", expectedPState, info.PStateDriver)
+	}
+
+	idleStates := info.Processors[0].LogicalProcessorByID(0).IdleStates
+	if len(idleStates) != 4 {
+		t.Fatalf("Expected 4 idle states but got %d", len(idleStates))
+	}
+	expected := &cpu.IdleState{
+		Index:                 3,
+		Name:                  "C6",
+		Description:           "MWAIT 0x20",
+		LatencyMicros:         170,
+		TargetResidencyMicros: 600,
+		Disabled:              true,
+	}
+	if !reflect.DeepEqual(expected, idleStates[3]) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", expected, idleStates[3])
+	}
+	if idleStates[1].Name != "C1" || idleStates[1].Usage != 4096 || idleStates[1].TimeMicros != 81920 {
+		t.Fatalf("Expected C1 entered 4096 times for 81920us but got %+v", idleStates[1])
+	}
+}
@@ -0,0 +1,191 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+	"github.com/go-hardware/ghw/pkg/util"
+)
+
+var (
+	// the top-level RAPL zones, e.g. intel-rapl:0, and not their subzones,
+	// e.g. intel-rapl:0:1, or the zones of the MMIO interface, which
+	// duplicate them
+	regexForRAPLZone = regexp.MustCompile(`^intel-rapl:[0-9]+$`)
+	// packages with several dies have one zone per die, e.g.
+	// "package-0-die-1"
+	regexForRAPLPackage = regexp.MustCompile(`^package-([0-9]+)(?:-die-[0-9]+)?$`)
+)
+
+// raplZone is a RAPL domain along with the processor it belongs to and the
+// powercap zone exposing its energy counter
+type raplZone struct {
+	processorID int
+	path        string
+	domain      *RAPLDomain
+}
+
+// setProcessorRAPL sets the RAPL domains of the supplied processors, one
+// per die of the package
+func setProcessorRAPL(paths *ghwpath.Paths, procs []*Processor) {
+	zones := raplZones(paths)
+	for _, p := range procs {
+		for _, z := range zones {
+			if z.processorID == p.ID && regexForRAPLPackage.MatchString(z.domain.Name) {
+				p.RAPL = append(p.RAPL, z.domain)
+			}
+		}
+	}
+}
+
+// raplPackageIDs maps the logical package IDs the RAPL package domains are
+// named after to the physical package IDs of the processors, using the
+// logical_package_id the kernel reports next to physical_package_id. Without
+// it, both IDs are assumed to be the same.
+func raplPackageIDs(paths *ghwpath.Paths) map[int]int {
+	ids := map[int]int{}
+	entries, err := os.ReadDir(paths.SysDevicesSystemCPU)
+	if err != nil {
+		return ids
+	}
+	for _, entry := range entries {
+		if !regexForCpulCore.MatchString(entry.Name()) {
+			continue
+		}
+		topoPath := filepath.Join(paths.SysDevicesSystemCPU, entry.Name(), "topology")
+		logicalID, err := strconv.Atoi(util.ReadTrimmedFile(filepath.Join(topoPath, "logical_package_id")))
+		if err != nil {
+			continue
+		}
+		physicalID, err := strconv.Atoi(util.ReadTrimmedFile(filepath.Join(topoPath, "physical_package_id")))
+		if err != nil {
+			continue
+		}
+		ids[logicalID] = physicalID
+	}
+	return ids
+}
+
+// raplZones returns the RAPL package domains found in /sys/class/powercap,
+// one per die of each package, each followed by its subdomains. Other
+// domains, e.g. the "psys" domain of the whole platform, are skipped.
+func raplZones(paths *ghwpath.Paths) []*raplZone {
+	entries, err := os.ReadDir(paths.SysClassPowercap)
+	if err != nil {
+		return nil
+	}
+	packageIDs := raplPackageIDs(paths)
+	zones := []*raplZone{}
+	for _, entry := range entries {
+		if !regexForRAPLZone.MatchString(entry.Name()) {
+			continue
+		}
+		zonePath := filepath.Join(paths.SysClassPowercap, entry.Name())
+		pkg := raplDomain(zonePath)
+		m := regexForRAPLPackage.FindStringSubmatch(pkg.Name)
+		if m == nil {
+			continue
+		}
+		procID, _ := strconv.Atoi(m[1])
+		if id, ok := packageIDs[procID]; ok {
+			procID = id
+		}
+		zones = append(zones, &raplZone{processorID: procID, path: zonePath, domain: pkg})
+		for _, sub := range entries {
+			if !strings.HasPrefix(sub.Name(), entry.Name()+":") {
+				continue
+			}
+			subPath := filepath.Join(paths.SysClassPowercap, sub.Name())
+			domain := raplDomain(subPath)
+			pkg.Subdomains = append(pkg.Subdomains, domain)
+			zones = append(zones, &raplZone{processorID: procID, path: subPath, domain: domain})
+		}
+	}
+	return zones
+}
+
+// raplDomain returns the RAPL domain exposed by the supplied powercap zone
+func raplDomain(zonePath string) *RAPLDomain {
+	return &RAPLDomain{
+		Name:                      util.ReadTrimmedFile(filepath.Join(zonePath, "name")),
+		EnergyMicrojoules:         util.ReadUint64File(filepath.Join(zonePath, "energy_uj")),
+		MaxEnergyRangeMicrojoules: util.ReadUint64File(filepath.Join(zonePath, "max_energy_range_uj")),
+	}
+}
+
+// SamplePower reads the RAPL energy counters of the host's physical
+// processors twice, the supplied interval apart, and returns the average
+// power each RAPL domain drew in between, ordered by processor ID. The
+// interval must be positive. It returns early with an error if the context
+// is done. Recent kernels only allow root to read the energy counters.
+func SamplePower(ctx context.Context, interval time.Duration) ([]*PowerSample, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid sampling interval %s", interval)
+	}
+	paths := ghwpath.New(ctx)
+	zones := raplZones(paths)
+	if len(zones) == 0 {
+		return nil, fmt.Errorf("no RAPL domains found in %s", paths.SysClassPowercap)
+	}
+	before := make([]uint64, len(zones))
+	for x, z := range zones {
+		energy, err := readEnergy(z.path)
+		if err != nil {
+			return nil, err
+		}
+		before[x] = energy
+	}
+	start := time.Now()
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+	}
+	elapsed := time.Since(start).Seconds()
+	samples := make([]*PowerSample, 0, len(zones))
+	for x, z := range zones {
+		energy, err := readEnergy(z.path)
+		if err != nil {
+			return nil, err
+		}
+		consumed := energy - before[x]
+		if energy < before[x] {
+			// the counter wrapped around
+			consumed = z.domain.MaxEnergyRangeMicrojoules - before[x] + energy
+		}
+		samples = append(samples, &PowerSample{
+			ProcessorID: z.processorID,
+			Domain:      z.domain.Name,
+			Watts:       float64(consumed) / 1e6 / elapsed,
+		})
+	}
+	sort.SliceStable(samples, func(i, j int) bool {
+		return samples[i].ProcessorID < samples[j].ProcessorID
+	})
+	return samples, nil
+}
+
+// readEnergy returns the energy counter of the supplied powercap zone
+func readEnergy(zonePath string) (uint64, error) {
+	contents, err := os.ReadFile(filepath.Join(zonePath, "energy_uj"))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(contents)), 10, 64)
+}
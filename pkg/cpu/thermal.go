@@ -0,0 +1,78 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu
+
+import "fmt"
+
+// Temperature is a reading of a processor temperature sensor.
+type Temperature struct {
+	// Label is the name of the sensor, e.g. "Package id 0", "Core 4", "Tctl"
+	// or, for thermal zones, the type of the zone, e.g. "x86_pkg_temp"
+	Label string `json:"label"`
+	// Source is the hwmon driver exposing the sensor, e.g. "coretemp" or
+	// "k10temp", or "thermal_zone" for thermal zones
+	Source string `json:"source"`
+	// MilliCelsius is the temperature, in thousandths of a degree Celsius
+	MilliCelsius int64 `json:"millicelsius"`
+	// MaxMilliCelsius is the temperature, in thousandths of a degree
+	// Celsius, above which the processor starts throttling, or 0 if the
+	// sensor does not report it
+	MaxMilliCelsius int64 `json:"max_millicelsius,omitempty"`
+	// CriticalMilliCelsius is the temperature, in thousandths of a degree
+	// Celsius, above which the host shuts down, or 0 if the sensor does not
+	// report it
+	CriticalMilliCelsius int64 `json:"critical_millicelsius,omitempty"`
+}
+
+func (t *Temperature) String() string {
+	return fmt.Sprintf("%s %.1f°C", t.Label, float64(t.MilliCelsius)/1000)
+}
+
+// Thermal describes the thermal state of a physical processor package.
+type Thermal struct {
+	// Package is a pointer to the `Temperature` of the whole package, or nil
+	// if no sensor reports it
+	Package *Temperature `json:"package,omitempty"`
+	// Sensors is a slice of `Temperature` struct pointers, one for each
+	// sensor of the package, including the package, per-core and per-CCD
+	// sensors and the thermal zones of the package
+	Sensors []*Temperature `json:"sensors,omitempty"`
+	// ThrottleCount is the number of times the package was throttled
+	// because it got too hot
+	ThrottleCount uint64 `json:"throttle_count"`
+}
+
+// RAPLDomain is a Running Average Power Limit (RAPL) power domain of a
+// physical processor package. Its energy counter tells how much energy the
+// domain consumed.
+type RAPLDomain struct {
+	// Name is the name of the domain, e.g. "package-0" for the whole
+	// package, "package-0-die-1" for one die of a package with several dies
+	// or "core", "uncore" and "dram" for its subdomains
+	Name string `json:"name"`
+	// EnergyMicrojoules is the energy, in microjoules, the domain consumed
+	// since the counter last wrapped around. Recent kernels only allow root
+	// to read it, it is 0 otherwise.
+	EnergyMicrojoules uint64 `json:"energy_uj"`
+	// MaxEnergyRangeMicrojoules is the value, in microjoules, at which the
+	// energy counter wraps around
+	MaxEnergyRangeMicrojoules uint64 `json:"max_energy_range_uj"`
+	// Subdomains is a slice of `RAPLDomain` struct pointers for the parts of
+	// the package, e.g. its cores and its DRAM, measured separately
+	Subdomains []*RAPLDomain `json:"subdomains,omitempty"`
+}
+
+// PowerSample is the average power a RAPL domain of a physical processor
+// package drew over a sampling interval.
+type PowerSample struct {
+	// ProcessorID is the ID of the physical processor owning the domain
+	ProcessorID int `json:"processor_id"`
+	// Domain is the name of the RAPL domain, e.g. "package-0" or "dram"
+	Domain string `json:"domain"`
+	// Watts is the average power drawn, in watts
+	Watts float64 `json:"watts"`
+}
@@ -0,0 +1,241 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+	"github.com/go-hardware/ghw/pkg/util"
+)
+
+var (
+	// older kernels label the package sensor "Physical id N"
+	regexForCoretempPackage = regexp.MustCompile(`^(?:Package|Physical) id ([0-9]+)$`)
+	regexForCoretempCore    = regexp.MustCompile(`^Core ([0-9]+)$`)
+	regexForHwmonInput      = regexp.MustCompile(`^temp([0-9]+)_input$`)
+	regexForThermalZone     = regexp.MustCompile(`^thermal_zone([0-9]+)$`)
+)
+
+// setProcessorThermals sets the temperature sensors and thermal throttling
+// counts of the supplied processors and of their cores, found in
+// /sys/class/hwmon, /sys/class/thermal and
+// /sys/devices/system/cpu/cpu{N}/thermal_throttle
+func setProcessorThermals(paths *ghwpath.Paths, procs []*Processor) {
+	sorted := make([]*Processor, len(procs))
+	copy(sorted, procs)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+	byID := make(map[int]*Processor, len(sorted))
+	for _, p := range sorted {
+		byID[p.ID] = p
+	}
+	thermalOf := func(p *Processor) *Thermal {
+		if p.Thermal == nil {
+			p.Thermal = &Thermal{}
+		}
+		return p.Thermal
+	}
+
+	var k10temps []string
+	entries, _ := os.ReadDir(paths.SysClassHwmon)
+	for _, entry := range entries {
+		hwmonPath := filepath.Join(paths.SysClassHwmon, entry.Name())
+		switch util.ReadTrimmedFile(filepath.Join(hwmonPath, "name")) {
+		case "coretemp":
+			// coretemp has one instance per package, whose package sensor
+			// tells which package the core sensors belong to
+			temps := hwmonTemperatures(hwmonPath, "coretemp")
+			var proc *Processor
+			for _, t := range temps {
+				if m := regexForCoretempPackage.FindStringSubmatch(t.Label); m != nil {
+					procID, _ := strconv.Atoi(m[1])
+					proc = byID[procID]
+					break
+				}
+			}
+			if proc == nil {
+				continue
+			}
+			th := thermalOf(proc)
+			th.Sensors = append(th.Sensors, temps...)
+			for _, t := range temps {
+				if regexForCoretempPackage.MatchString(t.Label) {
+					th.Package = t
+				} else if m := regexForCoretempCore.FindStringSubmatch(t.Label); m != nil {
+					coreID, _ := strconv.Atoi(m[1])
+					if core := proc.CoreByID(coreID); core != nil {
+						core.Temperature = t
+					}
+				}
+			}
+		case "k10temp":
+			k10temps = append(k10temps, hwmonPath)
+		}
+	}
+
+	// k10temp does not tell which package it belongs to. It has one instance
+	// per package, bound to the package's data fabric PCI device, so the
+	// instances are in the order of the packages once sorted by device.
+	sort.Slice(k10temps, func(i, j int) bool {
+		return hwmonDevicePath(k10temps[i]) < hwmonDevicePath(k10temps[j])
+	})
+	for x, hwmonPath := range k10temps {
+		if x >= len(sorted) {
+			break
+		}
+		th := thermalOf(sorted[x])
+		temps := hwmonTemperatures(hwmonPath, "k10temp")
+		th.Sensors = append(th.Sensors, temps...)
+		// Tdie is the actual temperature of the die, Tctl may be offset to
+		// make the fans spin faster on some models
+		for _, label := range []string{"Tdie", "Tctl"} {
+			for _, t := range temps {
+				if th.Package == nil && t.Label == label {
+					th.Package = t
+				}
+			}
+		}
+	}
+
+	// x86_pkg_temp zones are registered in the order of the packages. Other
+	// thermal zones of processors, e.g. "cpu-thermal" on ARM hosts, cannot
+	// be told apart when there are several packages.
+	pkgZones := 0
+	for _, t := range thermalZones(paths) {
+		var proc *Processor
+		if t.Label == "x86_pkg_temp" {
+			if pkgZones >= len(sorted) {
+				continue
+			}
+			proc = sorted[pkgZones]
+			pkgZones++
+		} else if strings.Contains(strings.ToLower(t.Label), "cpu") && len(sorted) == 1 {
+			proc = sorted[0]
+		} else {
+			continue
+		}
+		th := thermalOf(proc)
+		th.Sensors = append(th.Sensors, t)
+		if th.Package == nil && t.Label == "x86_pkg_temp" {
+			th.Package = t
+		}
+	}
+
+	for _, p := range sorted {
+		if len(p.LogicalProcessors) == 0 {
+			continue
+		}
+		throttlePath := filepath.Join(
+			paths.SysDevicesSystemCPU,
+			fmt.Sprintf("cpu%d", p.LogicalProcessors[0].ID),
+			"thermal_throttle",
+		)
+		if _, err := os.Stat(throttlePath); err != nil {
+			continue
+		}
+		thermalOf(p).ThrottleCount = util.ReadUint64File(filepath.Join(throttlePath, "package_throttle_count"))
+		for _, c := range p.Cores {
+			c.ThrottleCount = util.ReadUint64File(filepath.Join(
+				paths.SysDevicesSystemCPU,
+				fmt.Sprintf("cpu%d", c.LogicalProcessors[0]),
+				"thermal_throttle", "core_throttle_count",
+			))
+		}
+	}
+}
+
+// hwmonTemperatures returns the temperatures reported by the supplied hwmon
+// device, ordered by sensor index
+func hwmonTemperatures(hwmonPath string, source string) []*Temperature {
+	entries, err := os.ReadDir(hwmonPath)
+	if err != nil {
+		return nil
+	}
+	indexes := []int{}
+	for _, entry := range entries {
+		m := regexForHwmonInput.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		index, _ := strconv.Atoi(m[1])
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	temps := make([]*Temperature, 0, len(indexes))
+	for _, index := range indexes {
+		prefix := filepath.Join(hwmonPath, fmt.Sprintf("temp%d_", index))
+		milliC, err := strconv.ParseInt(util.ReadTrimmedFile(prefix+"input"), 10, 64)
+		if err != nil {
+			continue
+		}
+		label := util.ReadTrimmedFile(prefix + "label")
+		if label == "" {
+			label = fmt.Sprintf("temp%d", index)
+		}
+		maxMilliC, _ := strconv.ParseInt(util.ReadTrimmedFile(prefix+"max"), 10, 64)
+		critMilliC, _ := strconv.ParseInt(util.ReadTrimmedFile(prefix+"crit"), 10, 64)
+		temps = append(temps, &Temperature{
+			Label:                label,
+			Source:               source,
+			MilliCelsius:         milliC,
+			MaxMilliCelsius:      maxMilliC,
+			CriticalMilliCelsius: critMilliC,
+		})
+	}
+	return temps
+}
+
+// hwmonDevicePath returns the path of the device the supplied hwmon device
+// is bound to, e.g. /sys/devices/pci0000:00/0000:00:18.3/hwmon/hwmon2
+func hwmonDevicePath(hwmonPath string) string {
+	if devPath, err := filepath.EvalSymlinks(hwmonPath); err == nil {
+		return devPath
+	}
+	return hwmonPath
+}
+
+// thermalZones returns the temperatures of the thermal zones found in
+// /sys/class/thermal, labelled by zone type and ordered by zone index
+func thermalZones(paths *ghwpath.Paths) []*Temperature {
+	entries, err := os.ReadDir(paths.SysClassThermal)
+	if err != nil {
+		return nil
+	}
+	indexes := []int{}
+	for _, entry := range entries {
+		m := regexForThermalZone.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		index, _ := strconv.Atoi(m[1])
+		indexes = append(indexes, index)
+	}
+	// os.ReadDir sorts thermal_zone10 before thermal_zone2
+	sort.Ints(indexes)
+	temps := []*Temperature{}
+	for _, index := range indexes {
+		zonePath := filepath.Join(paths.SysClassThermal, fmt.Sprintf("thermal_zone%d", index))
+		milliC, err := strconv.ParseInt(util.ReadTrimmedFile(filepath.Join(zonePath, "temp")), 10, 64)
+		if err != nil {
+			continue
+		}
+		temps = append(temps, &Temperature{
+			Label:        util.ReadTrimmedFile(filepath.Join(zonePath, "type")),
+			Source:       "thermal_zone",
+			MilliCelsius: milliC,
+		})
+	}
+	return temps
+}
@@ -0,0 +1,290 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	ghwcontext "github.com/go-hardware/ghw/pkg/context"
+	"github.com/go-hardware/ghw/pkg/cpu"
+)
+
+// writeFakeAttrs writes the supplied sysfs attributes into the supplied
+// directory
+func writeFakeAttrs(t *testing.T, dir string, attrs map[string]string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	for name, val := range attrs {
+		_ = os.WriteFile(filepath.Join(dir, name), []byte(val+"\n"), 0644)
+	}
+}
+
+func TestThermalCoretemp(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+	root := t.TempDir()
+	writeFakeCPUs(t, root, 2)
+
+	sysDir := filepath.Join(root, "sys")
+	writeFakeAttrs(t, filepath.Join(sysDir, "class", "hwmon", "hwmon0"), map[string]string{
+		"name": "acpitz",
+	})
+	writeFakeAttrs(t, filepath.Join(sysDir, "class", "hwmon", "hwmon1"), map[string]string{
+		"name":        "coretemp",
+		"temp1_label": "Package id 0",
+		"temp1_input": "54000",
+		"temp1_max":   "100000",
+		"temp1_crit":  "100000",
+		"temp2_label": "Core 0",
+		"temp2_input": "51000",
+		"temp3_label": "Core 1",
+		"temp3_input": "53000",
+	})
+	writeFakeAttrs(t, filepath.Join(sysDir, "class", "thermal", "thermal_zone0"), map[string]string{
+		"type": "acpitz",
+		"temp": "27800",
+	})
+	writeFakeAttrs(t, filepath.Join(sysDir, "class", "thermal", "thermal_zone1"), map[string]string{
+		"type": "x86_pkg_temp",
+		"temp": "55000",
+	})
+	cpuDir := filepath.Join(sysDir, "devices", "system", "cpu")
+	writeFakeAttrs(t, filepath.Join(cpuDir, "cpu0", "thermal_throttle"), map[string]string{
+		"package_throttle_count": "12",
+		"core_throttle_count":    "3",
+	})
+	writeFakeAttrs(t, filepath.Join(cpuDir, "cpu1", "thermal_throttle"), map[string]string{
+		"package_throttle_count": "12",
+		"core_throttle_count":    "0",
+	})
+
+	info, err := cpu.New(ghwcontext.New(ghwcontext.WithRootMountpoint(root)))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	proc := info.Processors[0]
+	th := proc.Thermal
+	if th == nil {
+		t.Fatalf("Expected thermal information but got nil")
+	}
+	expectedPackage := &cpu.Temperature{
+		Label:                "Package id 0",
+		Source:               "coretemp",
+		MilliCelsius:         54000,
+		MaxMilliCelsius:      100000,
+		CriticalMilliCelsius: 100000,
+	}
+	if !reflect.DeepEqual(expectedPackage, th.Package) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", expectedPackage, th.Package)
+	}
+	// the acpitz zone is not a processor zone
+	if len(th.Sensors) != 4 {
+		t.Fatalf("Expected 4 sensors but got %d", len(th.Sensors))
+	}
+	if th.Sensors[3].Label != "x86_pkg_temp" || th.Sensors[3].MilliCelsius != 55000 {
+		t.Fatalf("Expected the x86_pkg_temp zone last but got %+v", th.Sensors[3])
+	}
+	if th.ThrottleCount != 12 {
+		t.Fatalf("Expected package throttle count 12 but got %d", th.ThrottleCount)
+	}
+	core := proc.CoreByID(1)
+	if core.Temperature == nil || core.Temperature.MilliCelsius != 53000 {
+		t.Fatalf("Expected core 1 at 53000 millicelsius but got %+v", core.Temperature)
+	}
+	if proc.CoreByID(0).ThrottleCount != 3 {
+		t.Fatalf("Expected core 0 throttle count 3 but got %d", proc.CoreByID(0).ThrottleCount)
+	}
+}
+
+func TestThermalK10temp(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+	root := t.TempDir()
+	writeFakeCPUs(t, root, 2)
+
+	writeFakeAttrs(t, filepath.Join(root, "sys", "class", "hwmon", "hwmon2"), map[string]string{
+		"name":        "k10temp",
+		"temp1_label": "Tctl",
+		"temp1_input": "72125",
+		"temp2_label": "Tdie",
+		"temp2_input": "62125",
+		"temp3_label": "Tccd1",
+		"temp3_input": "60500",
+	})
+
+	info, err := cpu.New(ghwcontext.New(ghwcontext.WithRootMountpoint(root)))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	th := info.Processors[0].Thermal
+	if th == nil || th.Package == nil {
+		t.Fatalf("Expected a package temperature but got %+v", th)
+	}
+	// Tctl is offset on some models, Tdie is the actual temperature
+	if th.Package.Label != "Tdie" || th.Package.MilliCelsius != 62125 {
+		t.Fatalf("Expected the Tdie sensor but got %+v", th.Package)
+	}
+	if len(th.Sensors) != 3 {
+		t.Fatalf("Expected 3 sensors but got %d", len(th.Sensors))
+	}
+}
+
+func TestRAPL(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+	root := t.TempDir()
+	writeFakeCPUs(t, root, 2)
+
+	powercapDir := filepath.Join(root, "sys", "class", "powercap")
+	zones := map[string]map[string]string{
+		"intel-rapl:0":   {"name": "package-0", "energy_uj": "262142000000"},
+		"intel-rapl:0:0": {"name": "core", "energy_uj": "1000000"},
+		"intel-rapl:0:1": {"name": "dram", "energy_uj": "2000000"},
+		"intel-rapl:1":   {"name": "psys", "energy_uj": "3000000"},
+	}
+	for zone, attrs := range zones {
+		attrs["max_energy_range_uj"] = "262143328850"
+		writeFakeAttrs(t, filepath.Join(powercapDir, zone), attrs)
+	}
+
+	ctx := ghwcontext.New(ghwcontext.WithRootMountpoint(root))
+	info, err := cpu.New(ctx)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	expected := &cpu.RAPLDomain{
+		Name:                      "package-0",
+		EnergyMicrojoules:         262142000000,
+		MaxEnergyRangeMicrojoules: 262143328850,
+		Subdomains: []*cpu.RAPLDomain{
+			{Name: "core", EnergyMicrojoules: 1000000, MaxEnergyRangeMicrojoules: 262143328850},
+			{Name: "dram", EnergyMicrojoules: 2000000, MaxEnergyRangeMicrojoules: 262143328850},
+		},
+	}
+	if !reflect.DeepEqual([]*cpu.RAPLDomain{expected}, info.Processors[0].RAPL) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", expected, info.Processors[0].RAPL)
+	}
+
+	// the package counter wraps around and the core consumes 2 joules while
+	// the power is sampled
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = os.WriteFile(filepath.Join(powercapDir, "intel-rapl:0", "energy_uj"), []byte("1670850\n"), 0644)
+		_ = os.WriteFile(filepath.Join(powercapDir, "intel-rapl:0:0", "energy_uj"), []byte("3000000\n"), 0644)
+	}()
+	interval := 200 * time.Millisecond
+	samples, err := cpu.SamplePower(ctx, interval)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if len(samples) != 3 {
+		t.Fatalf("Expected 3 samples but got %d", len(samples))
+	}
+	maxWatts := 3 / interval.Seconds()
+	if s := samples[0]; s.Domain != "package-0" || s.Watts <= 0 || s.Watts > maxWatts {
+		t.Fatalf("Expected the package to draw up to %.1fW but got %+v", maxWatts, s)
+	}
+	maxWatts = 2 / interval.Seconds()
+	if s := samples[1]; s.Domain != "core" || s.Watts <= 0 || s.Watts > maxWatts {
+		t.Fatalf("Expected the core to draw up to %.1fW but got %+v", maxWatts, s)
+	}
+	if s := samples[2]; s.Domain != "dram" || s.Watts != 0 {
+		t.Fatalf("Expected the DRAM to draw 0W but got %+v", s)
+	}
+
+	// sampling stops as soon as the context is cancelled
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	start := time.Now()
+	if _, err := cpu.SamplePower(cancelCtx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled but got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Expected SamplePower to return early but it took %s", elapsed)
+	}
+
+	// a power draw cannot be derived without time passing between samples
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if _, err := cpu.SamplePower(ctx, interval); err == nil {
+			t.Fatalf("Expected an error sampling power over %s but got nil", interval)
+		}
+	}
+}
+
+func TestRAPLMultiDie(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+	root := t.TempDir()
+	writeFakeCPUs(t, root, 2)
+	// the RAPL zones are named after the logical package ID, which differs
+	// from the physical package ID of the processor
+	for lpID := 0; lpID < 2; lpID++ {
+		topoDir := filepath.Join(root, "sys", "devices", "system", "cpu", fmt.Sprintf("cpu%d", lpID), "topology")
+		_ = os.WriteFile(filepath.Join(topoDir, "logical_package_id"), []byte("1\n"), 0644)
+	}
+
+	powercapDir := filepath.Join(root, "sys", "class", "powercap")
+	zones := map[string]map[string]string{
+		"intel-rapl:0":   {"name": "package-1-die-0", "energy_uj": "1000000"},
+		"intel-rapl:0:0": {"name": "dram", "energy_uj": "2000000"},
+		"intel-rapl:1":   {"name": "package-1-die-1", "energy_uj": "3000000"},
+	}
+	for zone, attrs := range zones {
+		attrs["max_energy_range_uj"] = "262143328850"
+		writeFakeAttrs(t, filepath.Join(powercapDir, zone), attrs)
+	}
+
+	ctx := ghwcontext.New(ghwcontext.WithRootMountpoint(root))
+	info, err := cpu.New(ctx)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	expected := []*cpu.RAPLDomain{
+		{
+			Name:                      "package-1-die-0",
+			EnergyMicrojoules:         1000000,
+			MaxEnergyRangeMicrojoules: 262143328850,
+			Subdomains: []*cpu.RAPLDomain{
+				{Name: "dram", EnergyMicrojoules: 2000000, MaxEnergyRangeMicrojoules: 262143328850},
+			},
+		},
+		{
+			Name:                      "package-1-die-1",
+			EnergyMicrojoules:         3000000,
+			MaxEnergyRangeMicrojoules: 262143328850,
+		},
+	}
+	if !reflect.DeepEqual(expected, info.Processors[0].RAPL) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", expected, info.Processors[0].RAPL)
+	}
+
+	samples, err := cpu.SamplePower(ctx, time.Millisecond)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if len(samples) != 3 {
+		t.Fatalf("Expected a sample for each die and the DRAM but got %d", len(samples))
+	}
+	for _, s := range samples {
+		if s.ProcessorID != 0 {
+			t.Fatalf("Expected the samples of processor 0 but got %+v", s)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// VulnerabilityStatus describes whether the host is affected by a CPU
+// vulnerability and, if so, whether the vulnerability is mitigated
+type VulnerabilityStatus int
+
+const (
+	// VulnerabilityStatusUnknown means the kernel could not tell whether the
+	// host is affected, e.g. because it depends on the hypervisor
+	VulnerabilityStatusUnknown VulnerabilityStatus = iota
+	// VulnerabilityStatusNotAffected indicates the CPU is not affected by the
+	// vulnerability
+	VulnerabilityStatusNotAffected
+	// VulnerabilityStatusMitigated indicates the CPU is affected by the
+	// vulnerability, and the kernel mitigates it
+	VulnerabilityStatusMitigated
+	// VulnerabilityStatusVulnerable indicates the CPU is affected by the
+	// vulnerability, and it is not mitigated
+	VulnerabilityStatusVulnerable
+)
+
+var (
+	vulnerabilityStatusString = map[VulnerabilityStatus]string{
+		VulnerabilityStatusUnknown:     "Unknown",
+		VulnerabilityStatusNotAffected: "not affected",
+		VulnerabilityStatusMitigated:   "mitigated",
+		VulnerabilityStatusVulnerable:  "vulnerable",
+	}
+
+	// NOTE: the keys are all lowercase and do not match the keys in the
+	// opposite table `vulnerabilityStatusString`. This is done because of the
+	// choice we made in VulnerabilityStatus::MarshalJSON. We use this table
+	// only in UnmarshalJSON, so it should be OK.
+	stringVulnerabilityStatus = map[string]VulnerabilityStatus{
+		"unknown":      VulnerabilityStatusUnknown,
+		"not affected": VulnerabilityStatusNotAffected,
+		"mitigated":    VulnerabilityStatusMitigated,
+		"vulnerable":   VulnerabilityStatusVulnerable,
+	}
+)
+
+func (vs VulnerabilityStatus) String() string {
+	return vulnerabilityStatusString[vs]
+}
+
+// NOTE: since serialized output is as "official" as we're going to get,
+// let's lowercase the string output when serializing, in order to
+// "normalize" the expected serialized output
+func (vs VulnerabilityStatus) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strings.ToLower(vs.String()))), nil
+}
+
+func (vs *VulnerabilityStatus) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	key := strings.ToLower(s)
+	val, ok := stringVulnerabilityStatus[key]
+	if !ok {
+		return fmt.Errorf("unknown vulnerability status: %q", key)
+	}
+	*vs = val
+	return nil
+}
+
+// Vulnerability describes the state of a CPU vulnerability, like Spectre or
+// Meltdown, on the host, as reported by the kernel.
+type Vulnerability struct {
+	// Name is the name the kernel gives the vulnerability, e.g. "spectre_v2"
+	// or "meltdown"
+	Name string `json:"name"`
+	// Status indicates whether the host is affected by the vulnerability and,
+	// if so, whether it is mitigated
+	Status VulnerabilityStatus `json:"status"`
+	// Mitigation describes the mitigations in place, e.g. "Enhanced / Automatic
+	// IBRS; IBPB: conditional", or is empty if the vulnerability is not
+	// mitigated
+	Mitigation string `json:"mitigation,omitempty"`
+	// Details is the full description of the state of the vulnerability, as
+	// reported by the kernel
+	Details string `json:"details"`
+}
+
+// String returns a short string describing the Vulnerability
+func (v *Vulnerability) String() string {
+	return fmt.Sprintf("%s: %s", v.Name, v.Details)
+}
@@ -0,0 +1,72 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+	"github.com/go-hardware/ghw/pkg/util"
+)
+
+// vulnerabilities returns the CPU vulnerabilities listed in
+// /sys/devices/system/cpu/vulnerabilities, one file per vulnerability, sorted
+// by name. It returns nil if the kernel does not report vulnerabilities.
+func vulnerabilities(paths *ghwpath.Paths) []*Vulnerability {
+	vulnsPath := filepath.Join(paths.SysDevicesSystemCPU, "vulnerabilities")
+	entries, err := os.ReadDir(vulnsPath)
+	if err != nil {
+		return nil
+	}
+	var vulns []*Vulnerability
+	// os.ReadDir returns the entries sorted by name
+	for _, entry := range entries {
+		details := util.ReadTrimmedFile(filepath.Join(vulnsPath, entry.Name()))
+		if details == "" {
+			continue
+		}
+		vulns = append(vulns, parseVulnerability(entry.Name(), details))
+	}
+	return vulns
+}
+
+// parseVulnerability parses the state of the vulnerability with the supplied
+// name, which the kernel reports as one of:
+//
+//	Not affected
+//	Mitigation: <mitigation>
+//	Vulnerable
+//	Vulnerable: <reason>
+//	Processor vulnerable
+//	Unknown: <reason>
+//
+// optionally preceded by the component the state applies to, e.g.
+// "KVM: Mitigation: Split huge pages" for itlb_multihit.
+func parseVulnerability(name string, details string) *Vulnerability {
+	vuln := &Vulnerability{
+		Name:    name,
+		Details: details,
+	}
+	state := details
+	if component, rest, found := strings.Cut(details, ": "); found &&
+		!strings.Contains(component, " ") &&
+		component != "Mitigation" && component != "Vulnerable" && component != "Unknown" {
+		state = rest
+	}
+	switch {
+	case state == "Not affected":
+		vuln.Status = VulnerabilityStatusNotAffected
+	case strings.HasPrefix(state, "Mitigation"):
+		vuln.Status = VulnerabilityStatusMitigated
+		vuln.Mitigation = strings.TrimSpace(strings.TrimPrefix(state, "Mitigation:"))
+	case strings.HasPrefix(state, "Vulnerable"), state == "Processor vulnerable":
+		vuln.Status = VulnerabilityStatusVulnerable
+	}
+	return vuln
+}
@@ -0,0 +1,100 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpu_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	ghwcontext "github.com/go-hardware/ghw/pkg/context"
+	"github.com/go-hardware/ghw/pkg/cpu"
+)
+
+func TestVulnerabilities(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_CPU"); ok {
+		t.Skip("Skipping CPU tests.")
+	}
+	root := t.TempDir()
+	writeFakeCPUs(t, root, 1)
+
+	vulnsDir := filepath.Join(root, "sys", "devices", "system", "cpu", "vulnerabilities")
+	_ = os.MkdirAll(vulnsDir, 0755)
+	for name, details := range map[string]string{
+		"meltdown":   "Not affected",
+		"spectre_v2": "Mitigation: Enhanced / Automatic IBRS; IBPB: conditional",
+		"mds":        "Vulnerable: Clear CPU buffers attempted, no microcode; SMT vulnerable",
+		"srbds":      "Unknown: Dependent on hypervisor status",
+	} {
+		_ = os.WriteFile(filepath.Join(vulnsDir, name), []byte(details+"\n"), 0644)
+	}
+
+	info, err := cpu.New(ghwcontext.New(ghwcontext.WithRootMountpoint(root)))
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	expected := []*cpu.Vulnerability{
+		{
+			Name:    "mds",
+			Status:  cpu.VulnerabilityStatusVulnerable,
+			Details: "Vulnerable: Clear CPU buffers attempted, no microcode; SMT vulnerable",
+		},
+		{
+			Name:    "meltdown",
+			Status:  cpu.VulnerabilityStatusNotAffected,
+			Details: "Not affected",
+		},
+		{
+			Name:       "spectre_v2",
+			Status:     cpu.VulnerabilityStatusMitigated,
+			Mitigation: "Enhanced / Automatic IBRS; IBPB: conditional",
+			Details:    "Mitigation: Enhanced / Automatic IBRS; IBPB: conditional",
+		},
+		{
+			Name:    "srbds",
+			Status:  cpu.VulnerabilityStatusUnknown,
+			Details: "Unknown: Dependent on hypervisor status",
+		},
+	}
+	if !reflect.DeepEqual(expected, info.Vulnerabilities) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", expected, info.Vulnerabilities)
+	}
+
+	// itlb_multihit reports the state of KVM, the only component affected
+	for details, expected := range map[string]*cpu.Vulnerability{
+		"KVM: Mitigation: Split huge pages": {
+			Status:     cpu.VulnerabilityStatusMitigated,
+			Mitigation: "Split huge pages",
+		},
+		"KVM: Mitigation: VMX disabled": {
+			Status:     cpu.VulnerabilityStatusMitigated,
+			Mitigation: "VMX disabled",
+		},
+		"KVM: Vulnerable": {
+			Status: cpu.VulnerabilityStatusVulnerable,
+		},
+		"Processor vulnerable": {
+			Status: cpu.VulnerabilityStatusVulnerable,
+		},
+	} {
+		_ = os.WriteFile(filepath.Join(vulnsDir, "itlb_multihit"), []byte(details+"\n"), 0644)
+		info, err := cpu.New(ghwcontext.New(ghwcontext.WithRootMountpoint(root)))
+		if err != nil {
+			t.Fatalf("Expected nil err, but got %v", err)
+		}
+		expected.Name = "itlb_multihit"
+		expected.Details = details
+		if actual := info.Vulnerabilities[0]; !reflect.DeepEqual(expected, actual) {
+			t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", expected, actual)
+		}
+	}
+
+	if microcode := info.Processors[0].Microcode; microcode != "0xd0003a5" {
+		t.Fatalf("Expected microcode 0xd0003a5 but got %q", microcode)
+	}
+}
@@ -0,0 +1,114 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+// Package cpuset parses and formats the lists of logical processors the
+// Linux kernel uses in sysfs, procfs and cgroups, like "0-3,8,10-11".
+package cpuset
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CPUSet is a sorted set of logical processor IDs
+type CPUSet []int
+
+// New returns a CPUSet containing the supplied logical processor IDs, sorted
+// and without duplicates
+func New(cpus ...int) CPUSet {
+	if len(cpus) == 0 {
+		return CPUSet{}
+	}
+	sorted := make([]int, len(cpus))
+	copy(sorted, cpus)
+	sort.Ints(sorted)
+	set := CPUSet{sorted[0]}
+	for _, cpu := range sorted[1:] {
+		if cpu != set[len(set)-1] {
+			set = append(set, cpu)
+		}
+	}
+	return set
+}
+
+// Parse parses a list of logical processors like "0-3,8,10-11", as found in
+// e.g. /sys/devices/system/cpu/online. An empty or blank list is an empty
+// CPUSet.
+func Parse(list string) (CPUSet, error) {
+	list = strings.TrimSpace(list)
+	if list == "" {
+		return CPUSet{}, nil
+	}
+	var cpus []int
+	for _, part := range strings.Split(list, ",") {
+		part = strings.TrimSpace(part)
+		bounds := strings.SplitN(part, "-", 2)
+		first, err := strconv.Atoi(bounds[0])
+		if err != nil || first < 0 {
+			return nil, fmt.Errorf("invalid cpu list %q: bad element %q", list, part)
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = strconv.Atoi(bounds[1]); err != nil || last < first {
+				return nil, fmt.Errorf("invalid cpu list %q: bad range %q", list, part)
+			}
+		}
+		for cpu := first; cpu <= last; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return New(cpus...), nil
+}
+
+// ReadFile returns the set of logical processors listed in the supplied file,
+// e.g. /sys/devices/system/cpu/online, or nil if the file cannot be read,
+// cannot be parsed or lists no logical processor
+func ReadFile(path string) CPUSet {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	set, err := Parse(string(contents))
+	if err != nil || len(set) == 0 {
+		return nil
+	}
+	return set
+}
+
+// Contains returns true if the CPUSet contains the supplied logical processor
+// ID, false otherwise
+func (s CPUSet) Contains(cpu int) bool {
+	idx := sort.SearchInts(s, cpu)
+	return idx < len(s) && s[idx] == cpu
+}
+
+// String returns the CPUSet formatted the way the kernel formats lists of
+// logical processors, with consecutive IDs collapsed into ranges, e.g.
+// "0-3,8,10-11"
+func (s CPUSet) String() string {
+	var b strings.Builder
+	for idx := 0; idx < len(s); {
+		first := s[idx]
+		last := first
+		for idx+1 < len(s) && s[idx+1] == last+1 {
+			idx++
+			last = s[idx]
+		}
+		idx++
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.Itoa(first))
+		if last != first {
+			b.WriteByte('-')
+			b.WriteString(strconv.Itoa(last))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,82 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package cpuset_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-hardware/ghw/pkg/cpuset"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		list     string
+		expected cpuset.CPUSet
+		str      string
+	}{
+		{list: "", expected: cpuset.CPUSet{}, str: ""},
+		{list: "0\n", expected: cpuset.CPUSet{0}, str: "0"},
+		{list: "0-3,8,10-11", expected: cpuset.CPUSet{0, 1, 2, 3, 8, 10, 11}, str: "0-3,8,10-11"},
+		{list: "8,0-1,1,2", expected: cpuset.CPUSet{0, 1, 2, 8}, str: "0-2,8"},
+	}
+	for _, test := range tests {
+		actual, err := cpuset.Parse(test.list)
+		if err != nil {
+			t.Fatalf("Expected nil err for %q, but got %v", test.list, err)
+		}
+		if !reflect.DeepEqual(test.expected, actual) {
+			t.Fatalf("Expected %v for %q but got %v", test.expected, test.list, actual)
+		}
+		if actual.String() != test.str {
+			t.Fatalf("Expected %q but got %q", test.str, actual.String())
+		}
+	}
+
+	for _, list := range []string{"a", "1-", "3-1", "-1", "0,,1"} {
+		if _, err := cpuset.Parse(list); err == nil {
+			t.Fatalf("Expected an error parsing %q", list)
+		}
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"online":  "0-2,4\n",
+		"offline": "\n",
+		"garbled": "0-\n",
+	}
+	for name, content := range files {
+		_ = os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	}
+	if set := cpuset.ReadFile(filepath.Join(dir, "online")); !reflect.DeepEqual(cpuset.CPUSet{0, 1, 2, 4}, set) {
+		t.Fatalf("Expected [0 1 2 4] but got %v", set)
+	}
+	// empty, unparseable and missing files all are nil sets
+	for _, name := range []string{"offline", "garbled", "missing"} {
+		if set := cpuset.ReadFile(filepath.Join(dir, name)); set != nil {
+			t.Fatalf("Expected a nil set for %q but got %v", name, set)
+		}
+	}
+}
+
+func TestContains(t *testing.T) {
+	set := cpuset.New(11, 0, 8, 3)
+	for _, cpu := range []int{0, 3, 8, 11} {
+		if !set.Contains(cpu) {
+			t.Fatalf("Expected %v to contain %d", set, cpu)
+		}
+	}
+	for _, cpu := range []int{-1, 1, 9, 12} {
+		if set.Contains(cpu) {
+			t.Fatalf("Expected %v not to contain %d", set, cpu)
+		}
+	}
+}
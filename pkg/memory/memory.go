@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"math"
 
+	"github.com/go-hardware/ghw/pkg/cgroup"
 	"github.com/go-hardware/ghw/pkg/marshal"
 	"github.com/go-hardware/ghw/pkg/unit"
 	"github.com/go-hardware/ghw/pkg/util"
@@ -89,6 +90,15 @@ func (a *Area) String() string {
 // Info contains information about the memory on a host system.
 type Info struct {
 	Area
+	// Cgroup is a pointer to a `cgroup.Memory` struct describing the memory
+	// limits of the cgroup requested with the CgroupPath option, or nil if
+	// the option is not set
+	Cgroup *cgroup.Memory `json:"cgroup,omitempty"`
+	// EffectiveUsableBytes is the amount of memory the processes of the
+	// cgroup requested with the CgroupPath option can use: the total usable
+	// memory, lowered by the memory limits of the cgroup. It is 0 if the
+	// option is not set.
+	EffectiveUsableBytes int64 `json:"effective_usable_bytes,omitempty"`
 }
 
 // New returns an Info struct that describes the memory on a host system.
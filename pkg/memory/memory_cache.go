@@ -93,6 +93,17 @@ type Cache struct {
 	// The set of logical processors (hardware threads) that have access to
 	// this cache.
 	LogicalProcessors []uint32 `json:"logical_processors"`
+	// LineSizeBytes is the size, in bytes, of the cache lines (the coherency
+	// line size), or 0 if unknown
+	LineSizeBytes uint32 `json:"line_size_bytes,omitempty"`
+	// WaysOfAssociativity is the number of cache lines each memory address
+	// may be stored in, or 0 if unknown or if the cache is fully associative
+	WaysOfAssociativity uint32 `json:"ways_of_associativity,omitempty"`
+	// NumberOfSets is the number of sets of cache lines, or 0 if unknown
+	NumberOfSets uint32 `json:"number_of_sets,omitempty"`
+	// PhysicalLinePartition is the number of cache lines sharing a tag, or 0
+	// if unknown
+	PhysicalLinePartition uint32 `json:"physical_line_partition,omitempty"`
 }
 
 func (c *Cache) String() string {
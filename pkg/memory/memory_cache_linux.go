@@ -16,8 +16,10 @@ import (
 	"strings"
 
 	ghwcontext "github.com/go-hardware/ghw/pkg/context"
+	"github.com/go-hardware/ghw/pkg/cpuset"
 	ghwpath "github.com/go-hardware/ghw/pkg/path"
 	"github.com/go-hardware/ghw/pkg/unit"
+	"github.com/go-hardware/ghw/pkg/util"
 )
 
 func CachesForNode(ctx context.Context, nodeID int) ([]*Cache, error) {
@@ -76,28 +78,24 @@ func CachesForNode(ctx context.Context, nodeID int) ([]*Cache, error) {
 				continue
 			}
 			cacheIndex, _ := strconv.Atoi(cacheDirFileName[5:])
+			indexPath := paths.NodeCPUCacheIndex(nodeID, lpID, cacheIndex)
 
 			// The cache information is repeated for each node, so here, we
 			// just ensure that we only have a one Cache object for each
 			// unique combination of level, type and processor map
-			level := memoryCacheLevel(ctx, paths, nodeID, lpID, cacheIndex)
-			cacheType := memoryCacheType(ctx, paths, nodeID, lpID, cacheIndex)
-			sharedCpuMap := memoryCacheSharedCPUMap(ctx, paths, nodeID, lpID, cacheIndex)
-			cacheKey := fmt.Sprintf("%d-%d-%s", level, cacheType, sharedCpuMap)
-
-			cache, exists := caches[cacheKey]
-			if !exists {
-				size := memoryCacheSize(ctx, paths, nodeID, lpID, level)
-				cache = &Cache{
-					Level:             uint8(level),
-					Type:              cacheType,
-					SizeBytes:         uint64(size) * uint64(unit.KB),
-					LogicalProcessors: make([]uint32, 0),
-				}
-				caches[cacheKey] = cache
+			cache, err := readCacheIndex(indexPath, lpID)
+			if err != nil {
+				ghwcontext.Warn(ctx, "%s", err)
+				continue
+			}
+			key := cacheKey(cache)
+			if _, exists := caches[key]; !exists {
+				// only the logical processors of the node are listed
+				cache.LogicalProcessors = make([]uint32, 0)
+				caches[key] = cache
 			}
-			cache.LogicalProcessors = append(
-				cache.LogicalProcessors,
+			caches[key].LogicalProcessors = append(
+				caches[key].LogicalProcessors,
 				uint32(lpID),
 			)
 		}
@@ -115,56 +113,97 @@ func CachesForNode(ctx context.Context, nodeID int) ([]*Cache, error) {
 	return cacheVals, nil
 }
 
-func memoryCacheLevel(ctx context.Context, paths *ghwpath.Paths, nodeID int, lpID int, cacheIndex int) int {
-	levelPath := filepath.Join(
-		paths.NodeCPUCacheIndex(nodeID, lpID, cacheIndex),
-		"level",
-	)
-	levelContents, err := os.ReadFile(levelPath)
-	if err != nil {
-		ghwcontext.Warn(ctx, "%s", err)
-		return -1
-	}
-	// levelContents is now a []byte with the last byte being a newline
-	// character. Trim that off and convert the contents to an integer.
-	level, err := strconv.Atoi(string(levelContents[:len(levelContents)-1]))
-	if err != nil {
-		ghwcontext.Warn(ctx, "Unable to parse int from %s", levelContents)
-		return -1
+// CachesForLogicalProcessors returns the caches the supplied logical
+// processors have access to, found in the
+// /sys/devices/system/cpu/cpuX/cache/indexY directories. Each cache is
+// returned once, with all the logical processors sharing it, including the
+// ones not supplied, and the caches are sorted by level, type and first
+// logical processor.
+func CachesForLogicalProcessors(ctx context.Context, lpIDs []int) ([]*Cache, error) {
+	paths := ghwpath.New(ctx)
+	caches := make(map[string]*Cache)
+	var cacheVals []*Cache
+	for _, lpID := range lpIDs {
+		cachePath := filepath.Join(
+			paths.SysDevicesSystemCPU,
+			fmt.Sprintf("cpu%d", lpID),
+			"cache",
+		)
+		cacheDirFiles, err := os.ReadDir(cachePath)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, cacheDirFile := range cacheDirFiles {
+			if !strings.HasPrefix(cacheDirFile.Name(), "index") {
+				continue
+			}
+			cache, err := readCacheIndex(filepath.Join(cachePath, cacheDirFile.Name()), lpID)
+			if err != nil {
+				ghwcontext.Warn(ctx, "%s", err)
+				continue
+			}
+			// Each cache is listed once for each logical processor sharing
+			// it
+			key := cacheKey(cache)
+			if _, exists := caches[key]; exists {
+				continue
+			}
+			caches[key] = cache
+			cacheVals = append(cacheVals, cache)
+		}
 	}
-	return level
+	sort.Sort(SortByCacheLevelTypeFirstProcessor(cacheVals))
+	return cacheVals, nil
 }
 
-func memoryCacheSize(ctx context.Context, paths *ghwpath.Paths, nodeID int, lpID int, cacheIndex int) int {
-	sizePath := filepath.Join(
-		paths.NodeCPUCacheIndex(nodeID, lpID, cacheIndex),
-		"size",
-	)
-	sizeContents, err := os.ReadFile(sizePath)
+// readCacheIndex returns the cache described in the supplied
+// /sys/devices/system/cpu/cpuX/cache/indexY directory of logical processor
+// lpID, along with all the logical processors sharing it
+func readCacheIndex(indexPath string, lpID int) (*Cache, error) {
+	level, err := strconv.Atoi(util.ReadTrimmedFile(filepath.Join(indexPath, "level")))
 	if err != nil {
-		ghwcontext.Warn(ctx, "%s", err)
-		return -1
+		return nil, fmt.Errorf("unable to read cache level from %s", indexPath)
 	}
-	// size comes as XK\n, so we trim off the K and the newline.
-	size, err := strconv.Atoi(string(sizeContents[:len(sizeContents)-2]))
-	if err != nil {
-		ghwcontext.Warn(ctx, "Unable to parse int from %s", sizeContents)
-		return -1
+	sharedCPUs := cpuset.ReadFile(filepath.Join(indexPath, "shared_cpu_list"))
+	if sharedCPUs == nil {
+		sharedCPUs = cpuset.New(lpID)
+	}
+	cache := &Cache{
+		Level:             uint8(level),
+		Type:              parseCacheType(util.ReadTrimmedFile(filepath.Join(indexPath, "type"))),
+		SizeBytes:         parseCacheSize(util.ReadTrimmedFile(filepath.Join(indexPath, "size"))),
+		LogicalProcessors: make([]uint32, 0, len(sharedCPUs)),
 	}
-	return size
+	for _, cpu := range sharedCPUs {
+		cache.LogicalProcessors = append(cache.LogicalProcessors, uint32(cpu))
+	}
+	cache.setGeometry(indexPath)
+	return cache, nil
 }
 
-func memoryCacheType(ctx context.Context, paths *ghwpath.Paths, nodeID int, lpID int, cacheIndex int) CacheType {
-	typePath := filepath.Join(
-		paths.NodeCPUCacheIndex(nodeID, lpID, cacheIndex),
-		"type",
-	)
-	cacheTypeContents, err := os.ReadFile(typePath)
-	if err != nil {
-		ghwcontext.Warn(ctx, "%s", err)
-		return CacheTypeUnified
-	}
-	switch string(cacheTypeContents[:len(cacheTypeContents)-1]) {
+// cacheKey returns the key identifying a cache among the caches listed for
+// each logical processor sharing it, built from the level, type and logical
+// processors of the supplied cache as returned by readCacheIndex
+func cacheKey(c *Cache) string {
+	return fmt.Sprintf("%d-%d-%v", c.Level, c.Type, c.LogicalProcessors)
+}
+
+// setGeometry sets the line size, associativity, number of sets and physical
+// line partition of the cache described in the supplied
+// /sys/devices/system/cpu/cpuX/cache/indexY directory
+func (c *Cache) setGeometry(indexPath string) {
+	c.LineSizeBytes = uint32(util.ReadUint64File(filepath.Join(indexPath, "coherency_line_size")))
+	c.WaysOfAssociativity = uint32(util.ReadUint64File(filepath.Join(indexPath, "ways_of_associativity")))
+	c.NumberOfSets = uint32(util.ReadUint64File(filepath.Join(indexPath, "number_of_sets")))
+	c.PhysicalLinePartition = uint32(util.ReadUint64File(filepath.Join(indexPath, "physical_line_partition")))
+}
+
+// parseCacheType parses the type of a cache, e.g. "Data"
+func parseCacheType(typ string) CacheType {
+	switch typ {
 	case "Data":
 		return CacheTypeData
 	case "Instruction":
@@ -174,15 +213,19 @@ func memoryCacheType(ctx context.Context, paths *ghwpath.Paths, nodeID int, lpID
 	}
 }
 
-func memoryCacheSharedCPUMap(ctx context.Context, paths *ghwpath.Paths, nodeID int, lpID int, cacheIndex int) string {
-	scpuPath := filepath.Join(
-		paths.NodeCPUCacheIndex(nodeID, lpID, cacheIndex),
-		"shared_cpu_map",
-	)
-	sharedCpuMap, err := os.ReadFile(scpuPath)
+// parseCacheSize parses the size of a cache, e.g. "48K" or "32M", returning
+// the size in bytes, or 0 if the size cannot be parsed
+func parseCacheSize(size string) uint64 {
+	multiplier := uint64(1)
+	switch {
+	case strings.HasSuffix(size, "K"):
+		multiplier = uint64(unit.KB)
+	case strings.HasSuffix(size, "M"):
+		multiplier = uint64(unit.MB)
+	}
+	val, err := strconv.ParseUint(strings.TrimRight(size, "KM"), 10, 64)
 	if err != nil {
-		ghwcontext.Warn(ctx, "%s", err)
-		return ""
+		return 0
 	}
-	return string(sharedCpuMap[:len(sharedCpuMap)-1])
+	return val * multiplier
 }
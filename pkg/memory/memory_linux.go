@@ -17,6 +17,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/go-hardware/ghw/pkg/cgroup"
 	ghwcontext "github.com/go-hardware/ghw/pkg/context"
 	ghwpath "github.com/go-hardware/ghw/pkg/path"
 	"github.com/go-hardware/ghw/pkg/unit"
@@ -67,6 +68,18 @@ func (i *Info) load(ctx context.Context) error {
 		i.TotalPhysicalBytes = usable
 	}
 	i.SupportedPageSizes, _ = memorySupportedPageSizes(paths.SysKernelMMHugepages)
+	limits, err := cgroup.MemoryLimits(ctx)
+	if err != nil {
+		ghwcontext.Warn(ctx, "failed to read cgroup memory limits: %s", err)
+	} else if limits != nil {
+		i.Cgroup = limits
+		i.EffectiveUsableBytes = usable
+		for _, limit := range []int64{limits.MaxBytes, limits.HighBytes} {
+			if limit != cgroup.Unlimited && limit < i.EffectiveUsableBytes {
+				i.EffectiveUsableBytes = limit
+			}
+		}
+	}
 	return nil
 }
 
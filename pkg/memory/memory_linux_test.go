@@ -8,6 +8,11 @@ package memory_test
 
 import (
 	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"testing"
 
 	ghwcontext "github.com/go-hardware/ghw/pkg/context"
@@ -35,3 +40,59 @@ func TestMemoryMarshalUnmarshal(t *testing.T) {
 		t.Fatalf("Expected no error unmarshaling memory.Info, but got %v", err)
 	}
 }
+
+func TestCachesForNode(t *testing.T) {
+	root := t.TempDir()
+	// two logical processors of node 0 with private L1 caches of different
+	// sizes and a shared L2 cache
+	for lpID := 0; lpID < 2; lpID++ {
+		cpuDir := filepath.Join(root, "sys", "devices", "system", "cpu", fmt.Sprintf("cpu%d", lpID))
+		indexes := []map[string]string{
+			{"level": "1", "type": "Data", "size": "48K", "shared_cpu_list": fmt.Sprint(lpID)},
+			{"level": "1", "type": "Instruction", "size": "32K", "shared_cpu_list": fmt.Sprint(lpID)},
+			{"level": "2", "type": "Unified", "size": "2M", "shared_cpu_list": "0-1"},
+		}
+		for idx, attrs := range indexes {
+			indexDir := filepath.Join(cpuDir, "cache", fmt.Sprintf("index%d", idx))
+			if err := os.MkdirAll(indexDir, 0755); err != nil {
+				t.Fatalf("Expected nil err, but got %v", err)
+			}
+			for name, val := range attrs {
+				_ = os.WriteFile(filepath.Join(indexDir, name), []byte(val+"\n"), 0644)
+			}
+		}
+		nodeDir := filepath.Join(root, "sys", "devices", "system", "node", "node0")
+		_ = os.MkdirAll(nodeDir, 0755)
+		if err := os.Symlink(cpuDir, filepath.Join(nodeDir, fmt.Sprintf("cpu%d", lpID))); err != nil {
+			t.Fatalf("Expected nil err, but got %v", err)
+		}
+	}
+	ctx := ghwcontext.New(ghwcontext.WithRootMountpoint(root))
+
+	lpCaches, err := memory.CachesForLogicalProcessors(ctx, []int{0, 1})
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	nodeCaches, err := memory.CachesForNode(ctx, 0)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	sort.Sort(memory.SortByCacheLevelTypeFirstProcessor(nodeCaches))
+	// both functions describe the caches of a node the same way
+	if !reflect.DeepEqual(lpCaches, nodeCaches) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", lpCaches, nodeCaches)
+	}
+	if len(nodeCaches) != 5 {
+		t.Fatalf("Expected 5 caches but got %d", len(nodeCaches))
+	}
+	for _, c := range nodeCaches {
+		expectedSize := map[memory.CacheType]uint64{
+			memory.CacheTypeInstruction: 32 * 1024,
+			memory.CacheTypeData:        48 * 1024,
+			memory.CacheTypeUnified:     2 * 1024 * 1024,
+		}[c.Type]
+		if c.SizeBytes != expectedSize {
+			t.Fatalf("Expected %d bytes for %s but got %d", expectedSize, c, c.SizeBytes)
+		}
+	}
+}
@@ -0,0 +1,712 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package net
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"path"
+	"runtime"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+)
+
+const (
+	// SIOCETHTOOL, see include/uapi/linux/sockios.h
+	siocEthtool = 0x8946
+	ifNameSize  = 16
+
+	// ethtool commands, see include/uapi/linux/ethtool.h
+	ethtoolGDrvInfo      = 0x00000003
+	ethtoolGStrings      = 0x0000001b
+	ethtoolGPermAddr     = 0x00000020
+	ethtoolGSSetInfo     = 0x00000037
+	ethtoolGFeatures     = 0x0000003a
+	ethtoolGChannels     = 0x0000003c
+	ethtoolGLinkSettings = 0x0000004c
+
+	// ETH_SS_FEATURES, the string set holding the names of the netdev
+	// features
+	ethtoolSSFeatures = 4
+	// ETH_GSTRING_LEN
+	ethtoolStringLen = 32
+
+	// Sizes of the fixed part of the ethtool command structures
+	ethtoolLinkSettingsLen   = 48
+	ethtoolSSetInfoLen       = 16
+	ethtoolGStringsLen       = 12
+	ethtoolGFeaturesLen      = 8
+	ethtoolFeaturesBlockLen  = 16
+	ethtoolDrvInfoLen        = 196
+	ethtoolChannelsLen       = 36
+	ethtoolDrvInfoStringLen  = 32
+	ethtoolDrvInfoDriverOff  = 4
+	ethtoolDrvInfoVersionOff = 36
+	ethtoolDrvInfoFWOff      = 68
+	ethtoolDrvInfoBusOff     = 100
+	ethtoolPermAddrLen       = 8
+	// MAX_ADDR_LEN
+	ethtoolMaxAddrLen = 32
+
+	ethtoolSpeedUnknown = 0xFFFFFFFF
+	ethtoolDuplexHalf   = 0x00
+	ethtoolDuplexFull   = 0x01
+	ethtoolAutonegOn    = 0x01
+)
+
+// Bits of the ethtool link mode masks that do not describe link modes, see
+// `enum ethtool_link_mode_bit_indices` in include/uapi/linux/ethtool.h
+const (
+	ethtoolLinkModeAutoneg   = 6
+	ethtoolLinkModeTP        = 7
+	ethtoolLinkModeAUI       = 8
+	ethtoolLinkModeMII       = 9
+	ethtoolLinkModeFIBRE     = 10
+	ethtoolLinkModeBNC       = 11
+	ethtoolLinkModePause     = 13
+	ethtoolLinkModeAsymPause = 14
+	ethtoolLinkModeBackplane = 16
+	ethtoolLinkModeFECNone   = 49
+	ethtoolLinkModeFECRS     = 50
+	ethtoolLinkModeFECBaseR  = 51
+	ethtoolLinkModeFECLLRS   = 74
+)
+
+// ethtoolLinkModeNames contains the names, as printed by the ethtool program,
+// of the link modes indexed by their bit in the ethtool link mode masks.
+// Bits that do not describe a link mode have an empty name.
+var ethtoolLinkModeNames = []string{
+	"10baseT/Half",
+	"10baseT/Full",
+	"100baseT/Half",
+	"100baseT/Full",
+	"1000baseT/Half",
+	"1000baseT/Full",
+	"", // Autoneg
+	"", // TP
+	"", // AUI
+	"", // MII
+	"", // FIBRE
+	"", // BNC
+	"10000baseT/Full",
+	"", // Pause
+	"", // Asym_Pause
+	"2500baseX/Full",
+	"", // Backplane
+	"1000baseKX/Full",
+	"10000baseKX4/Full",
+	"10000baseKR/Full",
+	"10000baseR_FEC",
+	"20000baseMLD2/Full",
+	"20000baseKR2/Full",
+	"40000baseKR4/Full",
+	"40000baseCR4/Full",
+	"40000baseSR4/Full",
+	"40000baseLR4/Full",
+	"56000baseKR4/Full",
+	"56000baseCR4/Full",
+	"56000baseSR4/Full",
+	"56000baseLR4/Full",
+	"25000baseCR/Full",
+	"25000baseKR/Full",
+	"25000baseSR/Full",
+	"50000baseCR2/Full",
+	"50000baseKR2/Full",
+	"100000baseKR4/Full",
+	"100000baseSR4/Full",
+	"100000baseCR4/Full",
+	"100000baseLR4_ER4/Full",
+	"50000baseSR2/Full",
+	"1000baseX/Full",
+	"10000baseCR/Full",
+	"10000baseSR/Full",
+	"10000baseLR/Full",
+	"10000baseLRM/Full",
+	"10000baseER/Full",
+	"2500baseT/Full",
+	"5000baseT/Full",
+	"", // FEC_NONE
+	"", // FEC_RS
+	"", // FEC_BASER
+	"50000baseKR/Full",
+	"50000baseSR/Full",
+	"50000baseCR/Full",
+	"50000baseLR_ER_FR/Full",
+	"50000baseDR/Full",
+	"100000baseKR2/Full",
+	"100000baseSR2/Full",
+	"100000baseCR2/Full",
+	"100000baseLR2_ER2_FR2/Full",
+	"100000baseDR2/Full",
+	"200000baseKR4/Full",
+	"200000baseSR4/Full",
+	"200000baseLR4_ER4_FR4/Full",
+	"200000baseDR4/Full",
+	"200000baseCR4/Full",
+	"100baseT1/Full",
+	"1000baseT1/Full",
+	"400000baseKR8/Full",
+	"400000baseSR8/Full",
+	"400000baseLR8_ER8_FR8/Full",
+	"400000baseDR8/Full",
+	"400000baseCR8/Full",
+	"", // FEC_LLRS
+	"100000baseKR/Full",
+	"100000baseSR/Full",
+	"100000baseLR_ER_FR/Full",
+	"100000baseCR/Full",
+	"100000baseDR/Full",
+	"200000baseKR2/Full",
+	"200000baseSR2/Full",
+	"200000baseLR2_ER2_FR2/Full",
+	"200000baseDR2/Full",
+	"200000baseCR2/Full",
+	"400000baseKR4/Full",
+	"400000baseSR4/Full",
+	"400000baseLR4_ER4_FR4/Full",
+	"400000baseDR4/Full",
+	"400000baseCR4/Full",
+	"100baseFX/Half",
+	"100baseFX/Full",
+	"10baseT1L/Full",
+	"800000baseCR8/Full",
+	"800000baseKR8/Full",
+	"800000baseDR8/Full",
+	"800000baseDR8_2/Full",
+	"800000baseSR8/Full",
+	"800000baseVR8/Full",
+	"10baseT1S/Full",
+	"10baseT1S/Half",
+	"10baseT1S_P2MP/Half",
+}
+
+// ethtoolPorts contains the names of the port types, in the order the
+// ethtool program prints them
+var ethtoolPorts = []struct {
+	bit  int
+	name string
+}{
+	{ethtoolLinkModeTP, "TP"},
+	{ethtoolLinkModeAUI, "AUI"},
+	{ethtoolLinkModeBNC, "BNC"},
+	{ethtoolLinkModeMII, "MII"},
+	{ethtoolLinkModeFIBRE, "FIBRE"},
+	{ethtoolLinkModeBackplane, "Backplane"},
+}
+
+// ethtoolFECModes contains the names of the FEC modes, in the order the
+// ethtool program prints them
+var ethtoolFECModes = []struct {
+	bit  int
+	name string
+}{
+	{ethtoolLinkModeFECNone, "None"},
+	{ethtoolLinkModeFECBaseR, "BaseR"},
+	{ethtoolLinkModeFECRS, "RS"},
+	{ethtoolLinkModeFECLLRS, "LLRS"},
+}
+
+// ethtoolLegacyFeatures contains the feature names that `ethtool -k` prints
+// in place of the kernel feature names matching the associated pattern. This
+// keeps the capabilities we report natively consistent with the ones we
+// parse from the ethtool output.
+var ethtoolLegacyFeatures = []struct {
+	name    string
+	pattern string
+}{
+	{"rx-checksumming", "rx-checksum"},
+	{"tx-checksumming", "tx-checksum-*"},
+	{"scatter-gather", "tx-scatter-gather*"},
+	{"tcp-segmentation-offload", "tx-tcp*-segmentation"},
+	{"udp-fragmentation-offload", "tx-udp-fragmentation"},
+	{"generic-segmentation-offload", "tx-generic-segmentation"},
+	{"generic-receive-offload", "rx-gro"},
+	{"large-receive-offload", "rx-lro"},
+	{"rx-vlan-offload", "rx-vlan-hw-parse"},
+	{"tx-vlan-offload", "tx-vlan-hw-insert"},
+	{"ntuple-filters", "rx-ntuple-filter"},
+	{"receive-hashing", "rx-hashing"},
+}
+
+// nativeEndian is the byte order of the ethtool command structures, which
+// the kernel reads and writes in host byte order
+var nativeEndian binary.ByteOrder = binary.LittleEndian
+
+func init() {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 0 {
+		nativeEndian = binary.BigEndian
+	}
+}
+
+// ethtoolRequester issues ethtool requests for a single network interface.
+// The supplied buffer holds an ethtool command structure, starting with the
+// ETHTOOL_* command, and receives the response of the kernel.
+type ethtoolRequester interface {
+	request(buf []byte) error
+}
+
+// ifreqData mirrors `struct ifreq` from include/uapi/linux/if.h, using the
+// ifr_data member of the ifr_ifru union
+type ifreqData struct {
+	name [ifNameSize]byte
+	data unsafe.Pointer
+	_    [24]byte // rest of the ifr_ifru union
+}
+
+// ethtoolIoctl issues ethtool requests using the SIOCETHTOOL ioctl on the
+// supplied socket
+type ethtoolIoctl struct {
+	fd  int
+	dev string
+}
+
+func (e *ethtoolIoctl) request(buf []byte) error {
+	ifr := ifreqData{data: unsafe.Pointer(&buf[0])}
+	copy(ifr.name[:ifNameSize-1], e.dev)
+	_, _, errno := syscall.Syscall(
+		syscall.SYS_IOCTL, uintptr(e.fd), siocEthtool, uintptr(unsafe.Pointer(&ifr)),
+	)
+	runtime.KeepAlive(buf)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// ethtoolSocket returns a socket suitable to issue SIOCETHTOOL ioctls
+func ethtoolSocket() (int, error) {
+	return syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, 0)
+}
+
+// ethtoolInfo contains the information about a network interface returned
+// by the ethtool requests
+type ethtoolInfo struct {
+	// linkSettings is nil if the driver does not report link settings, as is
+	// the case for most virtual interfaces
+	linkSettings *ethtoolLinkSettings
+	features     []*NICCapability
+	// drvInfo is nil if the driver information could not be read
+	drvInfo *ethtoolDrvInfo
+	// permAddr is empty if the device has no permanent address
+	permAddr string
+	// channels is nil if the driver does not report its channels
+	channels *Channels
+}
+
+// ethtoolLinkSettings contains the fields of `struct ethtool_link_settings`
+// we are interested in
+type ethtoolLinkSettings struct {
+	speed         uint32
+	duplex        uint8
+	autoneg       uint8
+	supported     []uint32
+	advertising   []uint32
+	lpAdvertising []uint32
+}
+
+// ethtoolDrvInfo contains the fields of `struct ethtool_drvinfo` we are
+// interested in
+type ethtoolDrvInfo struct {
+	driver    string
+	version   string
+	fwVersion string
+	busInfo   string
+}
+
+// ethtoolQuery issues the ETHTOOL_GFEATURES, ETHTOOL_GLINKSETTINGS,
+// ETHTOOL_GDRVINFO and ETHTOOL_GPERMADDR requests. Only the features are
+// mandatory, since the kernel reports them for every network interface:
+// failing to get the other information is not an error.
+func ethtoolQuery(req ethtoolRequester) (*ethtoolInfo, error) {
+	features, err := ethtoolFeatures(req)
+	if err != nil {
+		return nil, err
+	}
+	info := &ethtoolInfo{
+		features: features,
+	}
+	if ls, err := ethtoolGetLinkSettings(req); err == nil {
+		info.linkSettings = ls
+	}
+	if di, err := ethtoolGetDrvInfo(req); err == nil {
+		info.drvInfo = di
+	}
+	if addr, err := ethtoolGetPermAddr(req); err == nil {
+		info.permAddr = addr
+	}
+	if ch, err := ethtoolGetChannels(req); err == nil {
+		info.channels = ch
+	}
+	return info, nil
+}
+
+// ethtoolGetLinkSettings issues the ETHTOOL_GLINKSETTINGS request. The kernel
+// first needs to tell us how many 32-bit words it uses for each link mode
+// mask: when we send a request with link_mode_masks_nwords set to 0, it
+// replies with the negated number of words it needs.
+func ethtoolGetLinkSettings(req ethtoolRequester) (*ethtoolLinkSettings, error) {
+	buf := make([]byte, ethtoolLinkSettingsLen)
+	nativeEndian.PutUint32(buf, ethtoolGLinkSettings)
+	if err := req.request(buf); err != nil {
+		return nil, err
+	}
+	nwords := -int8(buf[15])
+	if nwords <= 0 {
+		return nil, fmt.Errorf("unexpected link mode masks size %d", nwords)
+	}
+	buf = make([]byte, ethtoolLinkSettingsLen+3*4*int(nwords))
+	nativeEndian.PutUint32(buf, ethtoolGLinkSettings)
+	buf[15] = byte(nwords)
+	if err := req.request(buf); err != nil {
+		return nil, err
+	}
+	return parseEthtoolLinkSettings(buf)
+}
+
+// parseEthtoolLinkSettings parses the `struct ethtool_link_settings` in the
+// supplied buffer, which is followed by the supported, advertised and link
+// partner advertised link mode masks.
+func parseEthtoolLinkSettings(buf []byte) (*ethtoolLinkSettings, error) {
+	if len(buf) < ethtoolLinkSettingsLen {
+		return nil, fmt.Errorf("link settings too short: %d bytes", len(buf))
+	}
+	nwords := int(int8(buf[15]))
+	if nwords <= 0 || len(buf) < ethtoolLinkSettingsLen+3*4*nwords {
+		return nil, fmt.Errorf("invalid link mode masks size %d", nwords)
+	}
+	masks := make([]uint32, 3*nwords)
+	for x := range masks {
+		masks[x] = nativeEndian.Uint32(buf[ethtoolLinkSettingsLen+4*x:])
+	}
+	return &ethtoolLinkSettings{
+		speed:         nativeEndian.Uint32(buf[4:]),
+		duplex:        buf[8],
+		autoneg:       buf[11],
+		supported:     masks[:nwords],
+		advertising:   masks[nwords : 2*nwords],
+		lpAdvertising: masks[2*nwords:],
+	}, nil
+}
+
+// ethtoolFeatures returns the netdev features of the network interface as
+// NICCapability structs. The names of the features are returned by
+// ETHTOOL_GSTRINGS and their state by ETHTOOL_GFEATURES.
+func ethtoolFeatures(req ethtoolRequester) ([]*NICCapability, error) {
+	buf := make([]byte, ethtoolSSetInfoLen+4)
+	nativeEndian.PutUint32(buf, ethtoolGSSetInfo)
+	nativeEndian.PutUint64(buf[8:], 1<<ethtoolSSFeatures)
+	if err := req.request(buf); err != nil {
+		return nil, err
+	}
+	if nativeEndian.Uint64(buf[8:])&(1<<ethtoolSSFeatures) == 0 {
+		return nil, errors.New("features string set not supported")
+	}
+	count := int(nativeEndian.Uint32(buf[ethtoolSSetInfoLen:]))
+
+	buf = make([]byte, ethtoolGStringsLen+count*ethtoolStringLen)
+	nativeEndian.PutUint32(buf, ethtoolGStrings)
+	nativeEndian.PutUint32(buf[4:], ethtoolSSFeatures)
+	nativeEndian.PutUint32(buf[8:], uint32(count))
+	if err := req.request(buf); err != nil {
+		return nil, err
+	}
+	names := parseEthtoolStrings(buf)
+
+	nblocks := (len(names) + 31) / 32
+	buf = make([]byte, ethtoolGFeaturesLen+nblocks*ethtoolFeaturesBlockLen)
+	nativeEndian.PutUint32(buf, ethtoolGFeatures)
+	nativeEndian.PutUint32(buf[4:], uint32(nblocks))
+	if err := req.request(buf); err != nil {
+		return nil, err
+	}
+	return parseEthtoolFeatures(names, buf)
+}
+
+// parseEthtoolStrings returns the strings from the `struct ethtool_gstrings`
+// in the supplied buffer.
+func parseEthtoolStrings(buf []byte) []string {
+	if len(buf) < ethtoolGStringsLen {
+		return nil
+	}
+	count := int(nativeEndian.Uint32(buf[8:]))
+	strs := make([]string, 0, count)
+	for x := 0; x < count; x++ {
+		off := ethtoolGStringsLen + x*ethtoolStringLen
+		if off+ethtoolStringLen > len(buf) {
+			break
+		}
+		strs = append(strs, cString(buf[off:off+ethtoolStringLen]))
+	}
+	return strs
+}
+
+// parseEthtoolFeatures parses the `struct ethtool_gfeatures` in the supplied
+// buffer and returns a NICCapability for each of the named features. The
+// features are reported like `ethtool -k` does: the legacy feature groups
+// first, followed by the individual features which are not part of a group.
+func parseEthtoolFeatures(names []string, buf []byte) ([]*NICCapability, error) {
+	if len(buf) < ethtoolGFeaturesLen {
+		return nil, fmt.Errorf("features too short: %d bytes", len(buf))
+	}
+	nblocks := int(nativeEndian.Uint32(buf[4:]))
+	if len(buf) < ethtoolGFeaturesLen+nblocks*ethtoolFeaturesBlockLen {
+		return nil, fmt.Errorf("features truncated: %d blocks in %d bytes", nblocks, len(buf))
+	}
+	featureBit := func(field int, x int) bool {
+		block := x / 32
+		if block >= nblocks {
+			return false
+		}
+		off := ethtoolGFeaturesLen + block*ethtoolFeaturesBlockLen + field*4
+		return nativeEndian.Uint32(buf[off:])&(1<<uint(x%32)) != 0
+	}
+
+	features := make([]*NICCapability, len(names))
+	for x, name := range names {
+		// A feature is [fixed] when it is not available for changing or has
+		// never been changed from its fixed state
+		available := featureBit(0, x)
+		neverChanged := featureBit(3, x)
+		features[x] = &NICCapability{
+			Name:      name,
+			IsEnabled: featureBit(2, x),
+			CanEnable: available && !neverChanged,
+		}
+	}
+
+	caps := []*NICCapability{}
+	grouped := make([]bool, len(features))
+	for _, legacy := range ethtoolLegacyFeatures {
+		members := []*NICCapability{}
+		for x, f := range features {
+			if matched, _ := path.Match(legacy.pattern, f.Name); matched {
+				grouped[x] = true
+				members = append(members, f)
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+		group := &NICCapability{Name: legacy.name}
+		for _, m := range members {
+			group.IsEnabled = group.IsEnabled || m.IsEnabled
+			group.CanEnable = group.CanEnable || m.CanEnable
+		}
+		caps = append(caps, group)
+		// Like ethtool, only list the members of a group if there are more
+		// than one
+		if len(members) > 1 {
+			caps = append(caps, members...)
+		}
+	}
+	for x, f := range features {
+		if !grouped[x] && f.Name != "" {
+			caps = append(caps, f)
+		}
+	}
+	return caps, nil
+}
+
+// ethtoolGetDrvInfo issues the ETHTOOL_GDRVINFO request.
+func ethtoolGetDrvInfo(req ethtoolRequester) (*ethtoolDrvInfo, error) {
+	buf := make([]byte, ethtoolDrvInfoLen)
+	nativeEndian.PutUint32(buf, ethtoolGDrvInfo)
+	if err := req.request(buf); err != nil {
+		return nil, err
+	}
+	return parseEthtoolDrvInfo(buf)
+}
+
+// parseEthtoolDrvInfo parses the `struct ethtool_drvinfo` in the supplied
+// buffer.
+func parseEthtoolDrvInfo(buf []byte) (*ethtoolDrvInfo, error) {
+	if len(buf) < ethtoolDrvInfoLen {
+		return nil, fmt.Errorf("driver information too short: %d bytes", len(buf))
+	}
+	field := func(off int) string {
+		return cString(buf[off : off+ethtoolDrvInfoStringLen])
+	}
+	return &ethtoolDrvInfo{
+		driver:    field(ethtoolDrvInfoDriverOff),
+		version:   field(ethtoolDrvInfoVersionOff),
+		fwVersion: field(ethtoolDrvInfoFWOff),
+		busInfo:   field(ethtoolDrvInfoBusOff),
+	}, nil
+}
+
+// ethtoolGetChannels issues the ETHTOOL_GCHANNELS request
+func ethtoolGetChannels(req ethtoolRequester) (*Channels, error) {
+	buf := make([]byte, ethtoolChannelsLen)
+	nativeEndian.PutUint32(buf, ethtoolGChannels)
+	if err := req.request(buf); err != nil {
+		return nil, err
+	}
+	return parseEthtoolChannels(buf)
+}
+
+// parseEthtoolChannels parses the `struct ethtool_channels` in the supplied
+// buffer, which holds the command followed by the maximum and the current
+// numbers of receive, transmit, other and combined channels.
+func parseEthtoolChannels(buf []byte) (*Channels, error) {
+	if len(buf) < ethtoolChannelsLen {
+		return nil, fmt.Errorf("channels too short: %d bytes", len(buf))
+	}
+	field := func(idx int) int {
+		return int(nativeEndian.Uint32(buf[4+4*idx:]))
+	}
+	return &Channels{
+		MaxRx:       field(0),
+		MaxTx:       field(1),
+		MaxOther:    field(2),
+		MaxCombined: field(3),
+		Rx:          field(4),
+		Tx:          field(5),
+		Other:       field(6),
+		Combined:    field(7),
+	}, nil
+}
+
+// ethtoolGetPermAddr issues the ETHTOOL_GPERMADDR request and returns the
+// permanent hardware address of the device.
+func ethtoolGetPermAddr(req ethtoolRequester) (string, error) {
+	buf := make([]byte, ethtoolPermAddrLen+ethtoolMaxAddrLen)
+	nativeEndian.PutUint32(buf, ethtoolGPermAddr)
+	nativeEndian.PutUint32(buf[4:], ethtoolMaxAddrLen)
+	if err := req.request(buf); err != nil {
+		return "", err
+	}
+	return parseEthtoolPermAddr(buf)
+}
+
+// parseEthtoolPermAddr parses the `struct ethtool_perm_addr` in the supplied
+// buffer and returns the address in the usual colon-separated hexadecimal
+// notation, or "" if the device has no permanent address.
+func parseEthtoolPermAddr(buf []byte) (string, error) {
+	if len(buf) < ethtoolPermAddrLen {
+		return "", fmt.Errorf("permanent address too short: %d bytes", len(buf))
+	}
+	size := int(nativeEndian.Uint32(buf[4:]))
+	if len(buf) < ethtoolPermAddrLen+size {
+		return "", fmt.Errorf("permanent address truncated: %d bytes in %d bytes", size, len(buf))
+	}
+	octets := make([]string, size)
+	for x := range octets {
+		octets[x] = fmt.Sprintf("%02x", buf[ethtoolPermAddrLen+x])
+	}
+	return permanentMACAddress(strings.Join(octets, ":")), nil
+}
+
+// permanentMACAddress returns the supplied address, or "" if it is made of
+// zeroes only, which is what devices without a permanent address report
+func permanentMACAddress(addr string) string {
+	if strings.Trim(addr, "0:") == "" {
+		return ""
+	}
+	return addr
+}
+
+// cString returns the NUL-terminated string at the start of the supplied
+// buffer
+func cString(buf []byte) string {
+	if idx := bytes.IndexByte(buf, 0); idx >= 0 {
+		buf = buf[:idx]
+	}
+	return string(buf)
+}
+
+// linkModeBit returns true if the supplied bit is set in the link mode mask
+func linkModeBit(mask []uint32, bit int) bool {
+	if bit/32 >= len(mask) {
+		return false
+	}
+	return mask[bit/32]&(1<<uint(bit%32)) != 0
+}
+
+// linkModes returns the names of the link modes set in the link mode mask
+func linkModes(mask []uint32) []string {
+	var modes []string
+	for bit, name := range ethtoolLinkModeNames {
+		if name != "" && linkModeBit(mask, bit) {
+			modes = append(modes, name)
+		}
+	}
+	return modes
+}
+
+// linkModePorts returns the names of the port types set in the link mode
+// mask
+func linkModePorts(mask []uint32) []string {
+	var ports []string
+	for _, port := range ethtoolPorts {
+		if linkModeBit(mask, port.bit) {
+			ports = append(ports, port.name)
+		}
+	}
+	return ports
+}
+
+// linkModeFECModes returns the names of the FEC modes set in the link mode
+// mask
+func linkModeFECModes(mask []uint32) []string {
+	var modes []string
+	for _, fec := range ethtoolFECModes {
+		if linkModeBit(mask, fec.bit) {
+			modes = append(modes, fec.name)
+		}
+	}
+	return modes
+}
+
+// setNicAttrEthtoolInfo sets the NIC fields from the information returned by
+// the ethtool requests. When the link settings could not be queried, the
+// speed and duplex are read from sysfs instead.
+func (nic *NIC) setNicAttrEthtoolInfo(paths *ghwpath.Paths, dev string, info *ethtoolInfo) {
+	autoNegotiation := &NICCapability{Name: "auto-negotiation"}
+	pauseFrameUse := &NICCapability{Name: "pause-frame-use"}
+	if ls := info.linkSettings; ls != nil {
+		autoNegotiation.IsEnabled = ls.autoneg == ethtoolAutonegOn &&
+			linkModeBit(ls.advertising, ethtoolLinkModeAutoneg)
+		autoNegotiation.CanEnable = linkModeBit(ls.supported, ethtoolLinkModeAutoneg)
+		pauseFrameUse.IsEnabled = linkModeBit(ls.advertising, ethtoolLinkModePause) ||
+			linkModeBit(ls.advertising, ethtoolLinkModeAsymPause)
+		pauseFrameUse.CanEnable = linkModeBit(ls.supported, ethtoolLinkModePause) ||
+			linkModeBit(ls.supported, ethtoolLinkModeAsymPause)
+
+		if ls.speed != ethtoolSpeedUnknown && ls.speed != 0 {
+			nic.Speed = fmt.Sprintf("%dMb/s", ls.speed)
+		}
+		switch ls.duplex {
+		case ethtoolDuplexHalf:
+			nic.Duplex = "Half"
+		case ethtoolDuplexFull:
+			nic.Duplex = "Full"
+		}
+		nic.SupportedLinkModes = linkModes(ls.supported)
+		nic.SupportedPorts = linkModePorts(ls.supported)
+		nic.SupportedFECModes = linkModeFECModes(ls.supported)
+		nic.AdvertisedLinkModes = linkModes(ls.advertising)
+		nic.AdvertisedFECModes = linkModeFECModes(ls.advertising)
+	} else {
+		nic.setNicAttrSysFs(paths, dev)
+	}
+	nic.Capabilities = append(nic.Capabilities, autoNegotiation, pauseFrameUse)
+	nic.Capabilities = append(nic.Capabilities, info.features...)
+
+	nic.PermanentMACAddress = info.permAddr
+	nic.Channels = info.channels
+	if di := info.drvInfo; di != nil {
+		nic.Driver = di.driver
+		nic.DriverVersion = di.version
+		nic.FirmwareVersion = di.fwVersion
+		nic.BusInfo = di.busInfo
+	}
+}
@@ -0,0 +1,269 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+//go:build linux
+// +build linux
+
+package net
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"testing"
+
+	ghwcontext "github.com/go-hardware/ghw/pkg/context"
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+	"github.com/go-hardware/ghw/testdata"
+)
+
+// ethtoolExchange is a recorded ethtool request and the response of the
+// kernel
+type ethtoolExchange struct {
+	Request  []byte `json:"request"`
+	Response []byte `json:"response"`
+}
+
+// ethtoolReplay replays recorded ethtool exchanges, failing any request which
+// does not match the next recorded one
+type ethtoolReplay struct {
+	t         *testing.T
+	exchanges []ethtoolExchange
+}
+
+func (r *ethtoolReplay) request(buf []byte) error {
+	if len(r.exchanges) == 0 {
+		return syscall.EOPNOTSUPP
+	}
+	ex := r.exchanges[0]
+	r.exchanges = r.exchanges[1:]
+	if !bytes.Equal(buf, ex.Request) {
+		r.t.Fatalf("Expected request %v but got %v", ex.Request, buf)
+	}
+	copy(buf, ex.Response)
+	return nil
+}
+
+func TestEthtoolQueryReplay(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+	if nativeEndian != binary.LittleEndian {
+		t.Skip("Skipping replay of ethtool responses recorded on a little-endian host.")
+	}
+
+	testdataPath, err := testdata.SamplesDirectory()
+	if err != nil {
+		t.Fatalf("Expected nil err when detecting the samples directory, but got %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(testdataPath, "ethtool-virtio-net.json"))
+	if err != nil {
+		t.Fatalf("Expected nil err when reading the sample data, but got %v", err)
+	}
+	replay := &ethtoolReplay{t: t}
+	if err := json.Unmarshal(data, &replay.exchanges); err != nil {
+		t.Fatalf("Expected nil err when decoding the sample data, but got %v", err)
+	}
+
+	info, err := ethtoolQuery(replay)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if len(replay.exchanges) != 0 {
+		t.Fatalf("Expected all the recorded requests to be issued, but %d are left", len(replay.exchanges))
+	}
+
+	expectedDrvInfo := &ethtoolDrvInfo{
+		driver:  "virtio_net",
+		version: "1.0.0",
+		busInfo: "0000:00:04.0",
+	}
+	if !reflect.DeepEqual(expectedDrvInfo, info.drvInfo) {
+		t.Fatalf("Expected driver information %+v but got %+v", expectedDrvInfo, info.drvInfo)
+	}
+
+	paths := ghwpath.New(ghwcontext.New(ghwcontext.WithRootMountpoint(t.TempDir())))
+	nic := &NIC{}
+	nic.setNicAttrEthtoolInfo(paths, "eth0", info)
+	if nic.Driver != "virtio_net" || nic.BusInfo != "0000:00:04.0" || nic.FirmwareVersion != "" {
+		t.Fatalf("Unexpected driver information %+v", nic)
+	}
+	if nic.PermanentMACAddress != "02:fc:00:00:00:01" {
+		t.Fatalf("Expected permanent MAC address 02:fc:00:00:00:01 but got %q", nic.PermanentMACAddress)
+	}
+	expectedChannels := &Channels{MaxCombined: 1, Combined: 1}
+	if !reflect.DeepEqual(expectedChannels, nic.Channels) {
+		t.Fatalf("Expected channels %+v but got %+v", expectedChannels, nic.Channels)
+	}
+	// virtio_net does not know the link speed and duplex unless they are
+	// configured by the hypervisor
+	if nic.Speed != "" || nic.Duplex != "" || nic.SupportedLinkModes != nil {
+		t.Fatalf("Expected no link settings but got %+v", nic)
+	}
+
+	caps := make(map[string]*NICCapability)
+	for _, c := range nic.Capabilities {
+		caps[c.Name] = c
+	}
+	expectedCaps := []*NICCapability{
+		{Name: "auto-negotiation", IsEnabled: false, CanEnable: false},
+		{Name: "rx-checksumming", IsEnabled: true, CanEnable: false},
+		{Name: "tx-checksumming", IsEnabled: true, CanEnable: true},
+		{Name: "tx-checksum-ip-generic", IsEnabled: true, CanEnable: true},
+		{Name: "generic-receive-offload", IsEnabled: true, CanEnable: true},
+		{Name: "highdma", IsEnabled: true, CanEnable: false},
+		{Name: "rx-vlan-filter", IsEnabled: false, CanEnable: false},
+	}
+	for _, expected := range expectedCaps {
+		if !reflect.DeepEqual(expected, caps[expected.Name]) {
+			t.Fatalf("Expected capability %+v but got %+v", expected, caps[expected.Name])
+		}
+	}
+	// Kernel features reported under their legacy ethtool name are not
+	// listed on their own unless the legacy name covers several features
+	for _, name := range []string{"rx-checksum", "rx-gro"} {
+		if _, ok := caps[name]; ok {
+			t.Fatalf("Expected %s to be reported under its legacy name", name)
+		}
+	}
+}
+
+func TestEthtoolQueryUnsupported(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+
+	if _, err := ethtoolQuery(&ethtoolReplay{t: t}); err == nil {
+		t.Fatalf("Expected an error when the features cannot be queried, but got nil")
+	}
+}
+
+func TestParseEthtoolLinkSettings(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+
+	// A 1000BASE-T NIC with a 25G FEC capable mode, which negotiated 1Gb/s
+	// full duplex with its link partner
+	const nwords = 3
+	buf := make([]byte, ethtoolLinkSettingsLen+3*4*nwords)
+	nativeEndian.PutUint32(buf, ethtoolGLinkSettings)
+	nativeEndian.PutUint32(buf[4:], 1000)
+	buf[8] = ethtoolDuplexFull
+	buf[11] = ethtoolAutonegOn
+	buf[15] = nwords
+	setBits := func(mask int, bits ...int) {
+		for _, bit := range bits {
+			off := ethtoolLinkSettingsLen + 4*(mask*nwords+bit/32)
+			nativeEndian.PutUint32(buf[off:], nativeEndian.Uint32(buf[off:])|1<<uint(bit%32))
+		}
+	}
+	setBits(0, 0, 1, 2, 3, 5, 31, ethtoolLinkModeAutoneg, ethtoolLinkModeTP,
+		ethtoolLinkModeFIBRE, ethtoolLinkModePause, ethtoolLinkModeFECNone,
+		ethtoolLinkModeFECRS)
+	setBits(1, 3, 5, ethtoolLinkModeAutoneg, ethtoolLinkModeTP, ethtoolLinkModeFECRS)
+	setBits(2, 5, ethtoolLinkModeAutoneg)
+
+	ls, err := parseEthtoolLinkSettings(buf)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	paths := ghwpath.New(ghwcontext.New(ghwcontext.WithRootMountpoint(t.TempDir())))
+	nic := &NIC{}
+	nic.setNicAttrEthtoolInfo(paths, "eth0", &ethtoolInfo{linkSettings: ls})
+
+	expected := &NIC{
+		Speed:  "1000Mb/s",
+		Duplex: "Full",
+		SupportedLinkModes: []string{
+			"10baseT/Half",
+			"10baseT/Full",
+			"100baseT/Half",
+			"100baseT/Full",
+			"1000baseT/Full",
+			"25000baseCR/Full",
+		},
+		SupportedPorts:    []string{"TP", "FIBRE"},
+		SupportedFECModes: []string{"None", "RS"},
+		AdvertisedLinkModes: []string{
+			"100baseT/Full",
+			"1000baseT/Full",
+		},
+		AdvertisedFECModes: []string{"RS"},
+		Capabilities: []*NICCapability{
+			{
+				Name:      "auto-negotiation",
+				IsEnabled: true,
+				CanEnable: true,
+			},
+			{
+				Name:      "pause-frame-use",
+				IsEnabled: false,
+				CanEnable: true,
+			},
+		},
+	}
+	if !reflect.DeepEqual(expected, nic) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", *expected, *nic)
+	}
+
+	if _, err := parseEthtoolLinkSettings(buf[:ethtoolLinkSettingsLen]); err == nil {
+		t.Fatalf("Expected an error parsing truncated link settings, but got nil")
+	}
+}
+
+func TestParseEthtoolPermAddr(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+
+	buf := make([]byte, ethtoolPermAddrLen+ethtoolMaxAddrLen)
+	nativeEndian.PutUint32(buf, ethtoolGPermAddr)
+	nativeEndian.PutUint32(buf[4:], 6)
+	copy(buf[ethtoolPermAddrLen:], []byte{0x3c, 0xec, 0xef, 0x12, 0x34, 0x56})
+	addr, err := parseEthtoolPermAddr(buf)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if addr != "3c:ec:ef:12:34:56" {
+		t.Fatalf("Expected permanent address 3c:ec:ef:12:34:56 but got %q", addr)
+	}
+
+	// Virtual devices have no permanent address and report zeroes
+	copy(buf[ethtoolPermAddrLen:], make([]byte, 6))
+	if addr, err = parseEthtoolPermAddr(buf); err != nil || addr != "" {
+		t.Fatalf("Expected no permanent address but got %q (%v)", addr, err)
+	}
+
+	nativeEndian.PutUint32(buf[4:], ethtoolMaxAddrLen+1)
+	if _, err = parseEthtoolPermAddr(buf); err == nil {
+		t.Fatalf("Expected an error parsing a truncated permanent address, but got nil")
+	}
+}
+
+func TestEthtoolInfoWithoutLinkSettings(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+	paths := ghwpath.New(ghwcontext.New(ghwcontext.WithRootMountpoint(t.TempDir())))
+	devPath := filepath.Join(paths.SysClassNet, "eth0")
+	_ = os.MkdirAll(devPath, 0755)
+	_ = os.WriteFile(filepath.Join(devPath, "speed"), []byte("10000\n"), 0644)
+	_ = os.WriteFile(filepath.Join(devPath, "duplex"), []byte("full\n"), 0644)
+
+	// ETHTOOL_GLINKSETTINGS failed but the other requests succeeded
+	nic := &NIC{}
+	nic.setNicAttrEthtoolInfo(paths, "eth0", &ethtoolInfo{
+		features: []*NICCapability{{Name: "rx-checksumming", IsEnabled: true}},
+	})
+	if nic.Speed != "10000" || nic.Duplex != "full" {
+		t.Fatalf("Expected the speed and duplex from sysfs but got %q and %q", nic.Speed, nic.Duplex)
+	}
+}
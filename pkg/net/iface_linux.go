@@ -0,0 +1,260 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package net
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+	"github.com/go-hardware/ghw/pkg/util"
+)
+
+const (
+	// IFF_TAP from include/uapi/linux/if_tun.h
+	tunFlagTap = 0x0002
+	// ARPHRD_ETHER from include/uapi/linux/if_arp.h
+	arphrdEther = "1"
+)
+
+var (
+	// interfaceTypeByDevType maps the DEVTYPE the kernel reports in the
+	// uevent of some kinds of interfaces to the interface type
+	interfaceTypeByDevType = map[string]InterfaceType{
+		"bond":   InterfaceTypeBond,
+		"bridge": InterfaceTypeBridge,
+		"vlan":   InterfaceTypeVLAN,
+		"vxlan":  InterfaceTypeVXLAN,
+	}
+
+	// interfaceTypeByDriver maps the name the driver of a virtual interface
+	// reports about itself to the interface type. This is the only way to
+	// tell apart the kinds of interfaces which leave no trace in sysfs, i.e.
+	// team and Open vSwitch interfaces, and the driver is only known when
+	// ethtool could be asked for it.
+	interfaceTypeByDriver = map[string]InterfaceType{
+		"bonding":             InterfaceTypeBond,
+		"bridge":              InterfaceTypeBridge,
+		"802.1Q VLAN Support": InterfaceTypeVLAN,
+		"macvlan":             InterfaceTypeMACVLAN,
+		"macvtap":             InterfaceTypeMACVLAN,
+		"veth":                InterfaceTypeVeth,
+		"vxlan":               InterfaceTypeVXLAN,
+		"team":                InterfaceTypeTeam,
+		"openvswitch":         InterfaceTypeOVS,
+	}
+)
+
+// setNicAttrTopologySysFs sets the kind of the NIC along with its relations
+// with the NICs it is stacked on or enslaved to. The driver of the NIC, if
+// known, must be set already.
+func (nic *NIC) setNicAttrTopologySysFs(paths *ghwpath.Paths, dev string, vlans map[string]*VLANInfo) {
+	devPath := filepath.Join(paths.SysClassNet, dev)
+
+	if dest, err := os.Readlink(filepath.Join(devPath, "master")); err == nil {
+		nic.Master = filepath.Base(dest)
+	}
+	nic.LowerDevices = linkedDevices(devPath, "lower_")
+	nic.UpperDevices = linkedDevices(devPath, "upper_")
+
+	nic.Type = netDeviceType(devPath, nic.Driver, nic.IsVirtual, vlans[dev] != nil)
+	switch nic.Type {
+	case InterfaceTypeBond:
+		nic.Bond = netDeviceBond(devPath)
+	case InterfaceTypeBridge:
+		nic.Bridge = netDeviceBridge(devPath)
+	case InterfaceTypeVLAN:
+		nic.VLAN = vlans[dev]
+		if nic.VLAN == nil {
+			nic.VLAN = &VLANInfo{ID: -1}
+			if len(nic.LowerDevices) > 0 {
+				nic.VLAN.Parent = nic.LowerDevices[0]
+			}
+		}
+	}
+}
+
+// netDeviceType returns the kind of the interface, looking first at the
+// sysfs entries specific to some kinds of interfaces, then at the device
+// type and the driver of the interface and finally at the interface the
+// NIC is linked to.
+func netDeviceType(devPath string, driver string, isVirtual bool, isVLAN bool) InterfaceType {
+	if _, err := os.Stat(filepath.Join(devPath, "bonding")); err == nil {
+		return InterfaceTypeBond
+	}
+	if _, err := os.Stat(filepath.Join(devPath, "bridge")); err == nil {
+		return InterfaceTypeBridge
+	}
+	if isVLAN {
+		return InterfaceTypeVLAN
+	}
+	if flags := util.ReadTrimmedFile(filepath.Join(devPath, "tun_flags")); flags != "" {
+		val, err := strconv.ParseUint(flags, 0, 32)
+		if err == nil && val&tunFlagTap != 0 {
+			return InterfaceTypeTap
+		}
+		return InterfaceTypeTun
+	}
+	if it, ok := interfaceTypeByDevType[ueventValue(filepath.Join(devPath, "uevent"), "DEVTYPE")]; ok {
+		return it
+	}
+	if !isVirtual {
+		return InterfaceTypePhysical
+	}
+	if it, ok := interfaceTypeByDriver[driver]; ok {
+		return it
+	}
+	return netDeviceLinkType(devPath)
+}
+
+// netDeviceLinkType returns the kind of a virtual Ethernet interface linked
+// to another interface, i.e. whose iflink differs from its own ifindex. A
+// veth is linked to its peer, which it is not stacked on. A macvlan is linked
+// to the interface it is stacked on and, unlike an ipvlan, has a MAC address
+// of its own, either random or set when it was created.
+func netDeviceLinkType(devPath string) InterfaceType {
+	// tunnels use iflink for their underlying interface as well
+	if util.ReadTrimmedFile(filepath.Join(devPath, "type")) != arphrdEther {
+		return InterfaceTypeUnknown
+	}
+	ifindex := util.ReadTrimmedFile(filepath.Join(devPath, "ifindex"))
+	iflink := util.ReadTrimmedFile(filepath.Join(devPath, "iflink"))
+	if ifindex == "" || iflink == "" || iflink == "0" || iflink == ifindex {
+		return InterfaceTypeUnknown
+	}
+	if len(linkedDevices(devPath, "lower_")) == 0 {
+		return InterfaceTypeVeth
+	}
+	// addr_assign_type holds one of the NET_ADDR_* values from
+	// include/uapi/linux/netdevice.h
+	switch util.ReadTrimmedFile(filepath.Join(devPath, "addr_assign_type")) {
+	case "1", "3":
+		return InterfaceTypeMACVLAN
+	}
+	return InterfaceTypeUnknown
+}
+
+// netDeviceBond returns the configuration of the bond found in the supplied
+// interface sysfs directory
+func netDeviceBond(devPath string) *BondInfo {
+	bondPath := filepath.Join(devPath, "bonding")
+	// Most settings read like "802.3ad 4", the name followed by the value
+	firstField := func(name string) string {
+		fields := strings.Fields(util.ReadTrimmedFile(filepath.Join(bondPath, name)))
+		if len(fields) == 0 {
+			return ""
+		}
+		return fields[0]
+	}
+	bond := &BondInfo{
+		Mode:               firstField("mode"),
+		Slaves:             strings.Fields(util.ReadTrimmedFile(filepath.Join(bondPath, "slaves"))),
+		ActiveSlave:        util.ReadTrimmedFile(filepath.Join(bondPath, "active_slave")),
+		TransmitHashPolicy: firstField("xmit_hash_policy"),
+		MIIMonitorInterval: readIntFile(filepath.Join(bondPath, "miimon")),
+	}
+	if bond.Mode == "802.3ad" {
+		bond.LACPRate = firstField("lacp_rate")
+	}
+	if bond.MIIMonitorInterval < 0 {
+		bond.MIIMonitorInterval = 0
+	}
+	return bond
+}
+
+// netDeviceBridge returns the configuration of the bridge found in the
+// supplied interface sysfs directory
+func netDeviceBridge(devPath string) *BridgeInfo {
+	bridge := &BridgeInfo{
+		Ports:         []string{},
+		STPEnabled:    readIntFile(filepath.Join(devPath, "bridge", "stp_state")) > 0,
+		VLANFiltering: readIntFile(filepath.Join(devPath, "bridge", "vlan_filtering")) > 0,
+	}
+	entries, err := os.ReadDir(filepath.Join(devPath, "brif"))
+	if err != nil {
+		return bridge
+	}
+	for _, entry := range entries {
+		bridge.Ports = append(bridge.Ports, entry.Name())
+	}
+	return bridge
+}
+
+// linkedDevices returns the sorted names of the interfaces linked from the
+// supplied interface sysfs directory with the supplied prefix, e.g. "lower_"
+func linkedDevices(devPath string, prefix string) []string {
+	links, err := filepath.Glob(filepath.Join(devPath, prefix+"*"))
+	if err != nil || len(links) == 0 {
+		return nil
+	}
+	devs := make([]string, 0, len(links))
+	for _, link := range links {
+		devs = append(devs, strings.TrimPrefix(filepath.Base(link), prefix))
+	}
+	sort.Strings(devs)
+	return devs
+}
+
+// ueventValue returns the value of the supplied key in a uevent file, or an
+// empty string if the key is not found
+func ueventValue(path string, key string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		k, v, found := strings.Cut(scanner.Text(), "=")
+		if found && k == key {
+			return v
+		}
+	}
+	return ""
+}
+
+// netVLANs returns the VLAN interfaces listed in /proc/net/vlan/config,
+// keyed by interface name. The file is only present when the 8021q module is
+// loaded.
+func netVLANs(paths *ghwpath.Paths) map[string]*VLANInfo {
+	vlans := make(map[string]*VLANInfo)
+	f, err := os.Open(paths.ProcNetVLANConfig)
+	if err != nil {
+		return vlans
+	}
+	defer f.Close()
+	return parseVLANConfig(bufio.NewScanner(f))
+}
+
+// parseVLANConfig parses /proc/net/vlan/config which looks like this:
+//
+// VLAN Dev name	 | VLAN ID
+// Name-Type: VLAN_NAME_TYPE_RAW_PLUS_VID_NO_PAD
+// eth0.100       | 100  | eth0
+func parseVLANConfig(scanner *bufio.Scanner) map[string]*VLANInfo {
+	vlans := make(map[string]*VLANInfo)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "|")
+		if len(fields) != 3 {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimSpace(fields[1]))
+		if err != nil {
+			// the header line
+			continue
+		}
+		vlans[strings.TrimSpace(fields[0])] = &VLANInfo{
+			ID:     id,
+			Parent: strings.TrimSpace(fields[2]),
+		}
+	}
+	return vlans
+}
@@ -0,0 +1,216 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+//go:build linux
+// +build linux
+
+package net
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	ghwcontext "github.com/go-hardware/ghw/pkg/context"
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+)
+
+func TestNICTopologySysFs(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+	baseDir, _ := os.MkdirTemp("", "test")
+	defer os.RemoveAll(baseDir)
+	ctx := ghwcontext.New(ghwcontext.WithRootMountpoint(baseDir))
+	paths := ghwpath.New(ctx)
+
+	// bond0 aggregates the two ports of a ConnectX NIC in 802.3ad mode, is
+	// attached to the br0 bridge and carries the bond0.100 VLAN
+	pciDir := filepath.Join(baseDir, "sys", "devices", "pci0000:00", "0000:00:03.0")
+	virtualDir := filepath.Join(baseDir, "sys", "devices", "virtual", "net")
+	_ = os.MkdirAll(paths.SysClassNet, 0755)
+	ifaceDirs := map[string]string{
+		"ens1f0np0": filepath.Join(pciDir, "0000:3b:00.0", "net", "ens1f0np0"),
+		"ens1f1np1": filepath.Join(pciDir, "0000:3b:00.1", "net", "ens1f1np1"),
+		"bond0":     filepath.Join(virtualDir, "bond0"),
+		"br0":       filepath.Join(virtualDir, "br0"),
+		"bond0.100": filepath.Join(virtualDir, "bond0.100"),
+		"tap0":      filepath.Join(virtualDir, "tap0"),
+		"veth0":     filepath.Join(virtualDir, "veth0"),
+		"macvlan0":  filepath.Join(virtualDir, "macvlan0"),
+		"ipvlan0":   filepath.Join(virtualDir, "ipvlan0"),
+		"team0":     filepath.Join(virtualDir, "team0"),
+	}
+	for name, dir := range ifaceDirs {
+		_ = os.MkdirAll(dir, 0755)
+		_ = os.Symlink(dir, filepath.Join(paths.SysClassNet, name))
+	}
+	link := func(from, to, name string) {
+		_ = os.Symlink(ifaceDirs[to], filepath.Join(ifaceDirs[from], name))
+	}
+	for _, slave := range []string{"ens1f0np0", "ens1f1np1"} {
+		link(slave, "bond0", "master")
+		link(slave, "bond0", "upper_bond0")
+		link("bond0", slave, "lower_"+slave)
+	}
+	link("bond0", "br0", "master")
+	link("bond0", "br0", "upper_br0")
+	link("br0", "bond0", "lower_bond0")
+	link("bond0", "bond0.100", "upper_bond0.100")
+	link("bond0.100", "bond0", "lower_bond0")
+
+	bondDir := filepath.Join(ifaceDirs["bond0"], "bonding")
+	_ = os.MkdirAll(bondDir, 0755)
+	_ = os.WriteFile(filepath.Join(bondDir, "mode"), []byte("802.3ad 4\n"), 0644)
+	_ = os.WriteFile(filepath.Join(bondDir, "slaves"), []byte("ens1f0np0 ens1f1np1\n"), 0644)
+	_ = os.WriteFile(filepath.Join(bondDir, "active_slave"), []byte("\n"), 0644)
+	_ = os.WriteFile(filepath.Join(bondDir, "xmit_hash_policy"), []byte("layer3+4 1\n"), 0644)
+	_ = os.WriteFile(filepath.Join(bondDir, "lacp_rate"), []byte("fast 1\n"), 0644)
+	_ = os.WriteFile(filepath.Join(bondDir, "miimon"), []byte("100\n"), 0644)
+
+	_ = os.MkdirAll(filepath.Join(ifaceDirs["br0"], "bridge"), 0755)
+	_ = os.MkdirAll(filepath.Join(ifaceDirs["br0"], "brif", "bond0"), 0755)
+	_ = os.WriteFile(filepath.Join(ifaceDirs["br0"], "bridge", "stp_state"), []byte("1\n"), 0644)
+	_ = os.WriteFile(filepath.Join(ifaceDirs["br0"], "bridge", "vlan_filtering"), []byte("0\n"), 0644)
+	_ = os.WriteFile(filepath.Join(ifaceDirs["br0"], "uevent"), []byte("DEVTYPE=bridge\nINTERFACE=br0\nIFINDEX=7\n"), 0644)
+
+	_ = os.WriteFile(filepath.Join(ifaceDirs["tap0"], "tun_flags"), []byte("0x1002\n"), 0644)
+
+	// veth0, macvlan0 and ipvlan0 are linked to another interface. The peer
+	// of veth0 lives in another network namespace, while macvlan0 and
+	// ipvlan0 are stacked on ens1f1np1. Nothing in sysfs tells team0 apart.
+	link("ens1f1np1", "macvlan0", "upper_macvlan0")
+	link("macvlan0", "ens1f1np1", "lower_ens1f1np1")
+	link("ens1f1np1", "ipvlan0", "upper_ipvlan0")
+	link("ipvlan0", "ens1f1np1", "lower_ens1f1np1")
+	for name, attrs := range map[string][3]string{
+		// ifindex, iflink, addr_assign_type
+		"ens1f1np1": {"3", "3", "0"},
+		"veth0":     {"8", "4", "1"},
+		"macvlan0":  {"9", "3", "1"},
+		"ipvlan0":   {"10", "3", "0"},
+		"team0":     {"11", "11", "3"},
+	} {
+		_ = os.WriteFile(filepath.Join(ifaceDirs[name], "type"), []byte("1\n"), 0644)
+		_ = os.WriteFile(filepath.Join(ifaceDirs[name], "ifindex"), []byte(attrs[0]+"\n"), 0644)
+		_ = os.WriteFile(filepath.Join(ifaceDirs[name], "iflink"), []byte(attrs[1]+"\n"), 0644)
+		_ = os.WriteFile(filepath.Join(ifaceDirs[name], "addr_assign_type"), []byte(attrs[2]+"\n"), 0644)
+	}
+
+	_ = os.MkdirAll(filepath.Dir(paths.ProcNetVLANConfig), 0755)
+	_ = os.WriteFile(paths.ProcNetVLANConfig, []byte(
+		"VLAN Dev name	 | VLAN ID\n"+
+			"Name-Type: VLAN_NAME_TYPE_RAW_PLUS_VID_NO_PAD\n"+
+			"bond0.100      | 100  | bond0\n",
+	), 0644)
+	vlans := netVLANs(paths)
+
+	nics := make(map[string]*NIC)
+	for name := range ifaceDirs {
+		// the driver of virtual NICs is only known from ethtool, which is
+		// not asked when reading a sysfs snapshot
+		nic := &NIC{Name: name, IsVirtual: strings.HasPrefix(ifaceDirs[name], virtualDir)}
+		nic.setNicAttrTopologySysFs(paths, name, vlans)
+		nics[name] = nic
+	}
+
+	expected := map[string]*NIC{
+		"ens1f0np0": {
+			Type:         InterfaceTypePhysical,
+			Master:       "bond0",
+			UpperDevices: []string{"bond0"},
+		},
+		"ens1f1np1": {
+			Type:         InterfaceTypePhysical,
+			Master:       "bond0",
+			UpperDevices: []string{"bond0", "ipvlan0", "macvlan0"},
+		},
+		"bond0": {
+			Type:         InterfaceTypeBond,
+			Master:       "br0",
+			LowerDevices: []string{"ens1f0np0", "ens1f1np1"},
+			UpperDevices: []string{"bond0.100", "br0"},
+			Bond: &BondInfo{
+				Mode:               "802.3ad",
+				Slaves:             []string{"ens1f0np0", "ens1f1np1"},
+				TransmitHashPolicy: "layer3+4",
+				LACPRate:           "fast",
+				MIIMonitorInterval: 100,
+			},
+		},
+		"br0": {
+			Type:         InterfaceTypeBridge,
+			LowerDevices: []string{"bond0"},
+			Bridge: &BridgeInfo{
+				Ports:      []string{"bond0"},
+				STPEnabled: true,
+			},
+		},
+		"bond0.100": {
+			Type:         InterfaceTypeVLAN,
+			LowerDevices: []string{"bond0"},
+			VLAN: &VLANInfo{
+				ID:     100,
+				Parent: "bond0",
+			},
+		},
+		"tap0": {
+			Type: InterfaceTypeTap,
+		},
+		"veth0": {
+			Type: InterfaceTypeVeth,
+		},
+		"macvlan0": {
+			Type:         InterfaceTypeMACVLAN,
+			LowerDevices: []string{"ens1f1np1"},
+		},
+		"ipvlan0": {
+			Type:         InterfaceTypeUnknown,
+			LowerDevices: []string{"ens1f1np1"},
+		},
+		"team0": {
+			Type: InterfaceTypeUnknown,
+		},
+	}
+	for name, exp := range expected {
+		nic := nics[name]
+		actual := &NIC{
+			Type:         nic.Type,
+			Master:       nic.Master,
+			LowerDevices: nic.LowerDevices,
+			UpperDevices: nic.UpperDevices,
+			Bond:         nic.Bond,
+			Bridge:       nic.Bridge,
+			VLAN:         nic.VLAN,
+		}
+		if !reflect.DeepEqual(exp, actual) {
+			t.Fatalf("Expected for %s:\n%+v\nActual:\n%+v\n", name, exp, actual)
+		}
+	}
+}
+
+func TestParseVLANConfig(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+
+	config := `VLAN Dev name	 | VLAN ID
+Name-Type: VLAN_NAME_TYPE_RAW_PLUS_VID_NO_PAD
+eth0.100       | 100  | eth0
+vlan4094       | 4094  | ens1f0np0
+`
+	expected := map[string]*VLANInfo{
+		"eth0.100": {ID: 100, Parent: "eth0"},
+		"vlan4094": {ID: 4094, Parent: "ens1f0np0"},
+	}
+	actual := parseVLANConfig(bufio.NewScanner(strings.NewReader(config)))
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %+v but got %+v", expected, actual)
+	}
+}
@@ -0,0 +1,197 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package net
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-hardware/ghw/pkg/cpuset"
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+	"github.com/go-hardware/ghw/pkg/util"
+)
+
+// irqQueuePattern matches the name of an interrupt handler servicing
+// specific queues, following the conventions of a family of drivers
+type irqQueuePattern struct {
+	re       *regexp.Regexp
+	prefixes []string
+}
+
+var (
+	// The drivers name their per-queue interrupt handlers after the device or
+	// interface and the queue index, e.g. "ens1f0-TxRx-3" for Intel and
+	// Broadcom NICs, "mlx5_comp3@pci:0000:3b:00.0" for Mellanox NICs,
+	// "ens1f0-fp-3" for QLogic NICs or "virtio3-input.0" for virtio.
+	irqQueuePatterns = []irqQueuePattern{
+		{regexp.MustCompile(`(?i)-txrx-(\d+)$`), []string{"rx-", "tx-"}},
+		{regexp.MustCompile(`(?i)-fp-(\d+)$`), []string{"rx-", "tx-"}},
+		{regexp.MustCompile(`^mlx\d+_comp(\d+)@`), []string{"rx-", "tx-"}},
+		{regexp.MustCompile(`(?i)-rx-(\d+)$`), []string{"rx-"}},
+		{regexp.MustCompile(`(?i)-tx-(\d+)$`), []string{"tx-"}},
+		{regexp.MustCompile(`-input\.(\d+)$`), []string{"rx-"}},
+		{regexp.MustCompile(`-output\.(\d+)$`), []string{"tx-"}},
+	}
+)
+
+// procInterrupt is an interrupt line listed in /proc/interrupts
+type procInterrupt struct {
+	name  string
+	count uint64
+}
+
+// netDeviceQueues returns the number of receive and transmit queues of the
+// NIC, listed in /sys/class/net/$DEVICE/queues as rx-<N> and tx-<N>
+func netDeviceQueues(paths *ghwpath.Paths, dev string) (int, int) {
+	entries, err := os.ReadDir(filepath.Join(paths.SysClassNet, dev, "queues"))
+	if err != nil {
+		return 0, 0
+	}
+	rx, tx := 0, 0
+	for _, entry := range entries {
+		switch {
+		case strings.HasPrefix(entry.Name(), "rx-"):
+			rx++
+		case strings.HasPrefix(entry.Name(), "tx-"):
+			tx++
+		}
+	}
+	return rx, tx
+}
+
+// netDeviceIRQs returns the interrupts used by the PCI device backing the
+// NIC: its MSI or MSI-X interrupts if it uses any, its legacy interrupt
+// otherwise.
+func netDeviceIRQs(paths *ghwpath.Paths, pciAddr *string, interrupts map[int]*procInterrupt) []*IRQ {
+	if pciAddr == nil {
+		return nil
+	}
+	devPath := filepath.Join(paths.SysBusPciDevices, *pciAddr)
+	var numbers []int
+	if entries, err := os.ReadDir(filepath.Join(devPath, "msi_irqs")); err == nil {
+		for _, entry := range entries {
+			if number, err := strconv.Atoi(entry.Name()); err == nil {
+				numbers = append(numbers, number)
+			}
+		}
+	}
+	if len(numbers) == 0 {
+		if number := readIntFile(filepath.Join(devPath, "irq")); number > 0 {
+			numbers = append(numbers, number)
+		}
+	}
+	sort.Ints(numbers)
+
+	irqs := make([]*IRQ, 0, len(numbers))
+	for _, number := range numbers {
+		irqPath := filepath.Join(paths.ProcIRQ, strconv.Itoa(number))
+		irq := &IRQ{
+			Number:                number,
+			AffinityCPUs:          parseCPUList(util.ReadTrimmedFile(filepath.Join(irqPath, "smp_affinity_list"))),
+			EffectiveAffinityCPUs: parseCPUList(util.ReadTrimmedFile(filepath.Join(irqPath, "effective_affinity_list"))),
+		}
+		if intr, ok := interrupts[number]; ok {
+			irq.Name = intr.name
+			irq.Count = intr.count
+			irq.Queues = irqQueues(intr.name)
+		}
+		irqs = append(irqs, irq)
+	}
+	return irqs
+}
+
+// irqQueues returns the names of the queues serviced by the interrupt
+// handler with the supplied name, if the name follows a known convention
+func irqQueues(name string) []string {
+	for _, pattern := range irqQueuePatterns {
+		match := pattern.re.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+		queues := make([]string, 0, len(pattern.prefixes))
+		for _, prefix := range pattern.prefixes {
+			queues = append(queues, prefix+match[1])
+		}
+		return queues
+	}
+	return nil
+}
+
+// netInterrupts returns the interrupt lines listed in /proc/interrupts, keyed
+// by interrupt number
+func netInterrupts(paths *ghwpath.Paths) map[int]*procInterrupt {
+	f, err := os.Open(paths.ProcInterrupts)
+	if err != nil {
+		return map[int]*procInterrupt{}
+	}
+	defer f.Close()
+	return parseProcInterrupts(bufio.NewScanner(f))
+}
+
+// parseProcInterrupts parses /proc/interrupts which looks like this:
+//
+//	           CPU0       CPU1
+//	 40:        180          0  PCI-MSIX-0000:00:04.0   1-edge      virtio3-input.0
+//	 41:        217         12  PCI-MSIX-0000:00:04.0   2-edge      virtio3-output.0
+//	NMI:          0          0   Non-maskable interrupts
+//
+// There is a column of counts for each online CPU, followed by the interrupt
+// chip, the hardware interrupt number and trigger, and the names of the
+// interrupt handlers.
+func parseProcInterrupts(scanner *bufio.Scanner) map[int]*procInterrupt {
+	interrupts := make(map[int]*procInterrupt)
+	if !scanner.Scan() {
+		return interrupts
+	}
+	numCPUs := len(strings.Fields(scanner.Text()))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < numCPUs+1 {
+			continue
+		}
+		number, err := strconv.Atoi(strings.TrimSuffix(fields[0], ":"))
+		if err != nil {
+			// architecture specific interrupts, e.g. NMI
+			continue
+		}
+		intr := &procInterrupt{}
+		for _, field := range fields[1 : numCPUs+1] {
+			count, _ := strconv.ParseUint(field, 10, 64)
+			intr.count += count
+		}
+		rest := fields[numCPUs+1:]
+		names := rest
+		for idx, field := range rest {
+			if strings.HasSuffix(field, "-edge") || strings.HasSuffix(field, "-level") ||
+				strings.HasSuffix(field, "-fasteoi") {
+				names = rest[idx+1:]
+			}
+		}
+		if len(names) == len(rest) && len(rest) > 0 {
+			// unknown trigger format, the name is the last field
+			names = rest[len(rest)-1:]
+		}
+		intr.name = strings.Join(names, " ")
+		interrupts[number] = intr
+	}
+	return interrupts
+}
+
+// parseCPUList parses a list of logical processors like "0-3,8,10-11",
+// returning nil if the list is empty or malformed
+func parseCPUList(list string) []int {
+	cpus, err := cpuset.Parse(list)
+	if err != nil || len(cpus) == 0 {
+		return nil
+	}
+	return cpus
+}
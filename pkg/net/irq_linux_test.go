@@ -0,0 +1,187 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+//go:build linux
+// +build linux
+
+package net
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	ghwcontext "github.com/go-hardware/ghw/pkg/context"
+	"github.com/go-hardware/ghw/pkg/cpu"
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+	"github.com/go-hardware/ghw/pkg/topology"
+)
+
+func TestParseProcInterrupts(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+
+	data := `           CPU0       CPU1       
+  0:         33          0   IO-APIC   2-edge      timer
+  9:          0          0   IO-APIC   9-fasteoi   acpi
+ 40:        180         20  PCI-MSIX-0000:00:04.0   1-edge      virtio3-input.0
+ 58:          7          1  IR-PCI-MSI 1572864-edge      ens1f0-TxRx-0
+ 59:          1          0  PCI-MSI-edge      eth1
+NMI:          0          0   Non-maskable interrupts
+`
+	expected := map[int]*procInterrupt{
+		0:  {name: "timer", count: 33},
+		9:  {name: "acpi", count: 0},
+		40: {name: "virtio3-input.0", count: 200},
+		58: {name: "ens1f0-TxRx-0", count: 8},
+		59: {name: "eth1", count: 1},
+	}
+	actual := parseProcInterrupts(bufio.NewScanner(strings.NewReader(data)))
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %+v but got %+v", expected, actual)
+	}
+}
+
+func TestIRQQueues(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+
+	tests := []struct {
+		name     string
+		expected []string
+	}{
+		{"ice-ens1f0-TxRx-12", []string{"rx-12", "tx-12"}},
+		{"ens1f0-TxRx-3", []string{"rx-3", "tx-3"}},
+		{"mlx5_comp7@pci:0000:3b:00.0", []string{"rx-7", "tx-7"}},
+		{"ens2f1-fp-2", []string{"rx-2", "tx-2"}},
+		{"eth0-rx-1", []string{"rx-1"}},
+		{"eth0-tx-1", []string{"tx-1"}},
+		{"virtio3-input.0", []string{"rx-0"}},
+		{"virtio3-output.0", []string{"tx-0"}},
+		{"virtio3-config", nil},
+		{"mlx5_async0@pci:0000:3b:00.0", nil},
+		{"ens1f0", nil},
+	}
+	for _, test := range tests {
+		if actual := irqQueues(test.name); !reflect.DeepEqual(test.expected, actual) {
+			t.Fatalf("Expected queues %v for %s but got %v", test.expected, test.name, actual)
+		}
+	}
+}
+
+func TestParseCPUList(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+
+	if actual := parseCPUList("0-3,8,10-11\n"); !reflect.DeepEqual([]int{0, 1, 2, 3, 8, 10, 11}, actual) {
+		t.Fatalf("Expected [0 1 2 3 8 10 11] but got %v", actual)
+	}
+	if actual := parseCPUList(""); actual != nil {
+		t.Fatalf("Expected no CPUs but got %v", actual)
+	}
+}
+
+func TestNetDeviceIRQs(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+	baseDir, _ := os.MkdirTemp("", "test")
+	defer os.RemoveAll(baseDir)
+	ctx := ghwcontext.New(ghwcontext.WithRootMountpoint(baseDir))
+	paths := ghwpath.New(ctx)
+
+	// ens1f0 is affined to NUMA node 0, and has a link interrupt along with
+	// two combined queues, the second of which is steered to node 1
+	pciAddr := "0000:3b:00.0"
+	devDir := filepath.Join(paths.SysBusPciDevices, pciAddr)
+	_ = os.MkdirAll(filepath.Join(devDir, "msi_irqs"), 0755)
+	_ = os.MkdirAll(filepath.Join(paths.SysClassNet, "ens1f0", "queues", "rx-0"), 0755)
+	_ = os.MkdirAll(filepath.Join(paths.SysClassNet, "ens1f0", "queues", "rx-1"), 0755)
+	_ = os.MkdirAll(filepath.Join(paths.SysClassNet, "ens1f0", "queues", "tx-0"), 0755)
+	_ = os.MkdirAll(filepath.Join(paths.SysClassNet, "ens1f0", "queues", "tx-1"), 0755)
+	_ = os.MkdirAll(filepath.Join(paths.SysClassNet, "ens1f0", "queues", "tx-2"), 0755)
+	affinities := map[string][2]string{
+		"57": {"0-3", "0"},
+		"58": {"0-1", "1"},
+		"59": {"4-5", "5"},
+	}
+	for number, affinity := range affinities {
+		irqDir := filepath.Join(paths.ProcIRQ, number)
+		_ = os.MkdirAll(irqDir, 0755)
+		_ = os.WriteFile(filepath.Join(devDir, "msi_irqs", number), []byte("msix\n"), 0644)
+		_ = os.WriteFile(filepath.Join(irqDir, "smp_affinity_list"), []byte(affinity[0]+"\n"), 0644)
+		_ = os.WriteFile(filepath.Join(irqDir, "effective_affinity_list"), []byte(affinity[1]+"\n"), 0644)
+	}
+	interrupts := map[int]*procInterrupt{
+		57: {name: "ens1f0", count: 2},
+		58: {name: "ens1f0-TxRx-0", count: 1000},
+		59: {name: "ens1f0-TxRx-1", count: 500},
+	}
+
+	nic := &NIC{Name: "ens1f0", NUMANodeID: 0}
+	nic.RxQueues, nic.TxQueues = netDeviceQueues(paths, "ens1f0")
+	if nic.RxQueues != 2 || nic.TxQueues != 3 {
+		t.Fatalf("Expected 2 receive and 3 transmit queues but got %d and %d", nic.RxQueues, nic.TxQueues)
+	}
+	nic.IRQs = netDeviceIRQs(paths, &pciAddr, interrupts)
+	nic.setIRQAffinityNodes([]*topology.Node{
+		{ID: 0, Cores: []*cpu.ProcessorCore{{ID: 0, LogicalProcessors: []int{0, 1}}, {ID: 1, LogicalProcessors: []int{2, 3}}}},
+		{ID: 1, Cores: []*cpu.ProcessorCore{{ID: 0, LogicalProcessors: []int{4, 5}}}},
+	})
+
+	expected := []*IRQ{
+		{
+			Number:                57,
+			Name:                  "ens1f0",
+			Count:                 2,
+			AffinityCPUs:          []int{0, 1, 2, 3},
+			EffectiveAffinityCPUs: []int{0},
+			AffinityNodeIDs:       []int{0},
+		},
+		{
+			Number:                58,
+			Name:                  "ens1f0-TxRx-0",
+			Queues:                []string{"rx-0", "tx-0"},
+			Count:                 1000,
+			AffinityCPUs:          []int{0, 1},
+			EffectiveAffinityCPUs: []int{1},
+			AffinityNodeIDs:       []int{0},
+		},
+		{
+			Number:                59,
+			Name:                  "ens1f0-TxRx-1",
+			Queues:                []string{"rx-1", "tx-1"},
+			Count:                 500,
+			AffinityCPUs:          []int{4, 5},
+			EffectiveAffinityCPUs: []int{5},
+			AffinityNodeIDs:       []int{1},
+		},
+	}
+	if !reflect.DeepEqual(expected, nic.IRQs) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", expected, nic.IRQs)
+	}
+	if remote := nic.RemoteIRQs(); len(remote) != 1 || remote[0].Number != 59 {
+		t.Fatalf("Expected IRQ 59 to be the only remote IRQ but got %+v", remote)
+	}
+
+	// A device without MSI interrupts uses its legacy interrupt
+	_ = os.RemoveAll(filepath.Join(devDir, "msi_irqs"))
+	_ = os.WriteFile(filepath.Join(devDir, "irq"), []byte("57\n"), 0644)
+	irqs := netDeviceIRQs(paths, &pciAddr, interrupts)
+	if len(irqs) != 1 || irqs[0].Number != 57 {
+		t.Fatalf("Expected the legacy IRQ 57 but got %+v", irqs)
+	}
+
+	if irqs := netDeviceIRQs(paths, nil, interrupts); irqs != nil {
+		t.Fatalf("Expected no IRQs for a NIC not backed by a PCI device but got %+v", irqs)
+	}
+}
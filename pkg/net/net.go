@@ -8,11 +8,240 @@ package net
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/go-hardware/ghw/pkg/marshal"
+	"github.com/go-hardware/ghw/pkg/pci"
+	"github.com/go-hardware/ghw/pkg/topology"
 )
 
+// AddressAssignType describes how the current MAC address of a NIC was
+// assigned
+type AddressAssignType int
+
+const (
+	// AddressAssignTypeUnknown means we could not determine how the MAC
+	// address was assigned
+	AddressAssignTypeUnknown AddressAssignType = iota
+	// AddressAssignTypePermanent indicates the MAC address is the permanent
+	// address of the device, e.g. burned into the NIC
+	AddressAssignTypePermanent
+	// AddressAssignTypeRandom indicates the MAC address was randomly
+	// generated, e.g. for virtual interfaces
+	AddressAssignTypeRandom
+	// AddressAssignTypeStolen indicates the MAC address was taken from
+	// another device, e.g. a bond using the address of its first slave
+	AddressAssignTypeStolen
+	// AddressAssignTypeSet indicates the MAC address was set from userspace
+	AddressAssignTypeSet
+)
+
+var (
+	addressAssignTypeString = map[AddressAssignType]string{
+		AddressAssignTypeUnknown:   "Unknown",
+		AddressAssignTypePermanent: "permanent",
+		AddressAssignTypeRandom:    "random",
+		AddressAssignTypeStolen:    "stolen",
+		AddressAssignTypeSet:       "set",
+	}
+
+	// NOTE: the keys are all lowercase and do not match the keys in the
+	// opposite table `addressAssignTypeString`. This is done because of the
+	// choice we made in AddressAssignType::MarshalJSON. We use this table
+	// only in UnmarshalJSON, so it should be OK.
+	stringAddressAssignType = map[string]AddressAssignType{
+		"unknown":   AddressAssignTypeUnknown,
+		"permanent": AddressAssignTypePermanent,
+		"random":    AddressAssignTypeRandom,
+		"stolen":    AddressAssignTypeStolen,
+		"set":       AddressAssignTypeSet,
+	}
+)
+
+func (at AddressAssignType) String() string {
+	return addressAssignTypeString[at]
+}
+
+// NOTE: since serialized output is as "official" as we're going to get,
+// let's lowercase the string output when serializing, in order to
+// "normalize" the expected serialized output
+func (at AddressAssignType) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strings.ToLower(at.String()))), nil
+}
+
+func (at *AddressAssignType) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	key := strings.ToLower(s)
+	val, ok := stringAddressAssignType[key]
+	if !ok {
+		return fmt.Errorf("unknown address assign type: %q", key)
+	}
+	*at = val
+	return nil
+}
+
+// InterfaceType describes the kind of network interface a NIC is
+type InterfaceType int
+
+const (
+	// InterfaceTypeUnknown means we could not determine the kind of the
+	// interface
+	InterfaceTypeUnknown InterfaceType = iota
+	// InterfaceTypePhysical indicates the interface is backed by a hardware
+	// device, e.g. a PCI NIC or an SR-IOV virtual function
+	InterfaceTypePhysical
+	// InterfaceTypeBond indicates a bonding (link aggregation) interface
+	InterfaceTypeBond
+	// InterfaceTypeBridge indicates a software bridge
+	InterfaceTypeBridge
+	// InterfaceTypeVLAN indicates an 802.1Q VLAN interface stacked on a
+	// parent interface
+	InterfaceTypeVLAN
+	// InterfaceTypeMACVLAN indicates a macvlan or macvtap interface stacked
+	// on a parent interface
+	InterfaceTypeMACVLAN
+	// InterfaceTypeVeth indicates one end of a virtual Ethernet pair
+	InterfaceTypeVeth
+	// InterfaceTypeTun indicates a layer 3 TUN interface
+	InterfaceTypeTun
+	// InterfaceTypeTap indicates a layer 2 TAP interface
+	InterfaceTypeTap
+	// InterfaceTypeVXLAN indicates a VXLAN tunnel endpoint
+	InterfaceTypeVXLAN
+	// InterfaceTypeTeam indicates a teaming (link aggregation) interface
+	InterfaceTypeTeam
+	// InterfaceTypeOVS indicates an Open vSwitch internal interface
+	InterfaceTypeOVS
+)
+
+var (
+	interfaceTypeString = map[InterfaceType]string{
+		InterfaceTypeUnknown:  "Unknown",
+		InterfaceTypePhysical: "physical",
+		InterfaceTypeBond:     "bond",
+		InterfaceTypeBridge:   "bridge",
+		InterfaceTypeVLAN:     "vlan",
+		InterfaceTypeMACVLAN:  "macvlan",
+		InterfaceTypeVeth:     "veth",
+		InterfaceTypeTun:      "tun",
+		InterfaceTypeTap:      "tap",
+		InterfaceTypeVXLAN:    "vxlan",
+		InterfaceTypeTeam:     "team",
+		InterfaceTypeOVS:      "openvswitch",
+	}
+
+	// NOTE: the keys are all lowercase and do not match the keys in the
+	// opposite table `interfaceTypeString`. This is done because of the
+	// choice we made in InterfaceType::MarshalJSON. We use this table only
+	// in UnmarshalJSON, so it should be OK.
+	stringInterfaceType = map[string]InterfaceType{
+		"unknown":     InterfaceTypeUnknown,
+		"physical":    InterfaceTypePhysical,
+		"bond":        InterfaceTypeBond,
+		"bridge":      InterfaceTypeBridge,
+		"vlan":        InterfaceTypeVLAN,
+		"macvlan":     InterfaceTypeMACVLAN,
+		"veth":        InterfaceTypeVeth,
+		"tun":         InterfaceTypeTun,
+		"tap":         InterfaceTypeTap,
+		"vxlan":       InterfaceTypeVXLAN,
+		"team":        InterfaceTypeTeam,
+		"openvswitch": InterfaceTypeOVS,
+	}
+)
+
+func (it InterfaceType) String() string {
+	return interfaceTypeString[it]
+}
+
+// NOTE: since serialized output is as "official" as we're going to get,
+// let's lowercase the string output when serializing, in order to
+// "normalize" the expected serialized output
+func (it InterfaceType) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strings.ToLower(it.String()))), nil
+}
+
+func (it *InterfaceType) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	key := strings.ToLower(s)
+	val, ok := stringInterfaceType[key]
+	if !ok {
+		return fmt.Errorf("unknown interface type: %q", key)
+	}
+	*it = val
+	return nil
+}
+
+// BondInfo describes the configuration of a bonding interface
+type BondInfo struct {
+	// Mode is the bonding mode, e.g. "802.3ad" or "active-backup".
+	Mode string `json:"mode"`
+	// Slaves contains the names of the NICs aggregated by the bond.
+	Slaves []string `json:"slaves"`
+	// ActiveSlave is the name of the slave currently carrying the traffic
+	// in the modes using a single active slave, e.g. "active-backup".
+	ActiveSlave string `json:"active_slave,omitempty"`
+	// TransmitHashPolicy is the policy selecting the slave to transmit
+	// each packet on in the balancing modes, e.g. "layer3+4".
+	TransmitHashPolicy string `json:"transmit_hash_policy,omitempty"`
+	// LACPRate is the rate at which LACPDUs are requested from the link
+	// partner in 802.3ad mode: "slow" or "fast".
+	LACPRate string `json:"lacp_rate,omitempty"`
+	// MIIMonitorInterval is the interval, in milliseconds, at which the link
+	// state of the slaves is checked, or 0 if link monitoring is disabled.
+	MIIMonitorInterval int `json:"mii_monitor_interval"`
+}
+
+// BridgeInfo describes the configuration of a software bridge
+type BridgeInfo struct {
+	// Ports contains the names of the NICs attached to the bridge.
+	Ports []string `json:"ports"`
+	// STPEnabled is true if the Spanning Tree Protocol is enabled on the
+	// bridge.
+	STPEnabled bool `json:"stp_enabled"`
+	// VLANFiltering is true if the bridge filters traffic by VLAN.
+	VLANFiltering bool `json:"vlan_filtering"`
+}
+
+// VLANInfo describes an 802.1Q VLAN interface
+type VLANInfo struct {
+	// ID is the VLAN identifier, or -1 if it could not be determined.
+	ID int `json:"id"`
+	// Parent is the name of the NIC the VLAN interface is stacked on.
+	Parent string `json:"parent"`
+}
+
+// IPAddress is an IPv4 or IPv6 address assigned to a NIC
+type IPAddress struct {
+	// Address is the string representation of the address, e.g.
+	// "192.0.2.10" or "2001:db8::10"
+	Address string `json:"address"`
+	// PrefixLength is the length, in bits, of the network prefix of the
+	// address, e.g. 24 for a 255.255.255.0 netmask
+	PrefixLength int `json:"prefix_length"`
+	// IsIPv6 is true if the address is an IPv6 address, false if it is an
+	// IPv4 address
+	IsIPv6 bool `json:"is_ipv6"`
+	// Scope is the scope in which the address is valid: "global", "link"
+	// (e.g. fe80::/10 or 169.254.0.0/16 addresses) or "host" (loopback
+	// addresses)
+	Scope string `json:"scope"`
+}
+
+// String returns the address in CIDR notation along with its scope.
+func (a *IPAddress) String() string {
+	return fmt.Sprintf("%s/%d (%s)", a.Address, a.PrefixLength, a.Scope)
+}
+
 // NICCapability is a feature/capability of a Network Interface Controller
 // (NIC)
 type NICCapability struct {
@@ -27,15 +256,174 @@ type NICCapability struct {
 	CanEnable bool `json:"can_enable"`
 }
 
+// Channels describes the channels of a NIC, as configured with
+// `ethtool -L`. A channel is an interrupt along with the queues it services:
+// receive-only, transmit-only, combined receive and transmit, or other (e.g.
+// link interrupts) channels.
+type Channels struct {
+	// MaxRx is the maximum number of receive-only channels.
+	MaxRx int `json:"max_rx"`
+	// MaxTx is the maximum number of transmit-only channels.
+	MaxTx int `json:"max_tx"`
+	// MaxOther is the maximum number of other channels.
+	MaxOther int `json:"max_other"`
+	// MaxCombined is the maximum number of combined channels.
+	MaxCombined int `json:"max_combined"`
+	// Rx is the number of receive-only channels.
+	Rx int `json:"rx"`
+	// Tx is the number of transmit-only channels.
+	Tx int `json:"tx"`
+	// Other is the number of other channels.
+	Other int `json:"other"`
+	// Combined is the number of combined channels.
+	Combined int `json:"combined"`
+}
+
+// IRQ describes an interrupt line used by a NIC
+type IRQ struct {
+	// Number is the number of the interrupt.
+	Number int `json:"number"`
+	// Name is the name the driver registered the interrupt handler with,
+	// e.g. "ens1f0-TxRx-3" or "virtio3-input.0".
+	Name string `json:"name"`
+	// Queues contains the names of the queues serviced by the interrupt,
+	// e.g. "rx-3" and "tx-3", as guessed from the name of the interrupt
+	// handler. Empty if the interrupt does not service specific queues, e.g.
+	// link state interrupts, or the name follows no known convention.
+	Queues []string `json:"queues,omitempty"`
+	// Count is the number of times the interrupt fired, on all CPUs.
+	Count uint64 `json:"count"`
+	// AffinityCPUs contains the logical processors the interrupt may be
+	// delivered to, as set in /proc/irq/<IRQ>/smp_affinity_list.
+	AffinityCPUs []int `json:"affinity_cpus"`
+	// EffectiveAffinityCPUs contains the logical processors the interrupt
+	// is actually delivered to, which is often a single processor out of
+	// AffinityCPUs. Empty if the kernel does not report it.
+	EffectiveAffinityCPUs []int `json:"effective_affinity_cpus,omitempty"`
+	// AffinityNodeIDs contains the IDs of the NUMA nodes the interrupt is
+	// delivered to, based on the effective affinity if known and on the
+	// affinity otherwise.
+	AffinityNodeIDs []int `json:"affinity_node_ids,omitempty"`
+}
+
+// SRIOVFunction describes a PCI function which takes part in SR-IOV (Single
+// Root I/O Virtualization) along with the one backing a NIC.
+type SRIOVFunction struct {
+	// PCIAddress is the PCI address of the function.
+	PCIAddress string `json:"pci_address"`
+	// NICName is the name of the NIC backed by the function, or empty if the
+	// function backs no NIC, e.g. a virtual function bound to vfio-pci to be
+	// assigned to a virtual machine.
+	NICName string `json:"nic_name,omitempty"`
+	// VFIndex is the index of the virtual function on its physical function,
+	// or -1 for physical functions.
+	VFIndex int `json:"vf_index"`
+}
+
+// SRIOVInfo describes the SR-IOV role of the PCI function backing a NIC. A
+// physical function (PF) exposes a number of virtual functions (VFs), each of
+// them backing a NIC of its own when bound to a network driver.
+type SRIOVInfo struct {
+	// TotalVFs is the maximum number of virtual functions the physical
+	// function supports. Zero for virtual functions.
+	TotalVFs int `json:"total_vfs,omitempty"`
+	// NumVFs is the number of virtual functions currently enabled on the
+	// physical function. Zero for virtual functions.
+	NumVFs int `json:"num_vfs,omitempty"`
+	// VirtualFunctions is a slice of pointers to `SRIOVFunction` structs
+	// describing the enabled virtual functions of the physical function,
+	// ordered by VF index.
+	VirtualFunctions []*SRIOVFunction `json:"virtual_functions,omitempty"`
+	// PhysicalFunction is a pointer to an `SRIOVFunction` struct describing
+	// the parent physical function of a virtual function, or nil for
+	// physical functions.
+	PhysicalFunction *SRIOVFunction `json:"physical_function,omitempty"`
+	// VFIndex is the index of the virtual function on its physical function,
+	// or -1 for physical functions.
+	VFIndex int `json:"vf_index"`
+}
+
+// IsPhysicalFunction returns true if the NIC is backed by an SR-IOV physical
+// function
+func (s *SRIOVInfo) IsPhysicalFunction() bool {
+	return s.PhysicalFunction == nil
+}
+
+// IsVirtualFunction returns true if the NIC is backed by an SR-IOV virtual
+// function
+func (s *SRIOVInfo) IsVirtualFunction() bool {
+	return s.PhysicalFunction != nil
+}
+
 // NIC contains information about a single Network Interface Controller (NIC).
 type NIC struct {
 	// Name is the string identifier the system gave this NIC.
 	Name string `json:"name"`
-	// MACAddress is the Media Access Control (MAC) address of this NIC.
+	// MACAddress is the current Media Access Control (MAC) address of this
+	// NIC.
 	MACAddress string `json:"mac_address"`
+	// MACAddressAssignType describes how the current MAC address of this NIC
+	// was assigned. Bonds and SR-IOV virtual functions commonly use stolen,
+	// random or userspace-set addresses.
+	MACAddressAssignType AddressAssignType `json:"mac_address_assign_type"`
+	// PermanentMACAddress is the permanent MAC address of the device backing
+	// this NIC, which differs from MACAddress when the address was changed,
+	// or empty if the device has no permanent address.
+	PermanentMACAddress string `json:"permanent_mac_address,omitempty"`
+	// Index is the interface index the kernel assigned to this NIC, or -1 if
+	// it could not be determined.
+	Index int `json:"index"`
+	// OperState is the RFC 2863 operational state of this NIC, e.g. "up",
+	// "down", "dormant" or "lowerlayerdown".
+	OperState string `json:"oper_state,omitempty"`
+	// HasCarrier is true if the physical link of this NIC is up.
+	HasCarrier bool `json:"carrier"`
+	// MTU is the Maximum Transmission Unit, in bytes, of this NIC, or -1 if
+	// it could not be determined.
+	MTU int `json:"mtu"`
+	// TxQueueLength is the length, in packets, of the transmit queue of this
+	// NIC, or -1 if it could not be determined.
+	TxQueueLength int `json:"tx_queue_length"`
+	// IPAddresses is a slice of pointers to `IPAddress` structs describing
+	// the IPv4 and IPv6 addresses assigned to this NIC.
+	IPAddresses []*IPAddress `json:"ip_addresses,omitempty"`
+	// Statistics is a pointer to a `Statistics` struct containing the
+	// traffic counters of this NIC at the time it was inspected.
+	Statistics *Statistics `json:"statistics,omitempty"`
+	// RxQueues is the number of receive queues of this NIC.
+	RxQueues int `json:"rx_queues"`
+	// TxQueues is the number of transmit queues of this NIC.
+	TxQueues int `json:"tx_queues"`
+	// Channels is a pointer to a `Channels` struct describing the channel
+	// configuration of this NIC, or nil if the driver does not report it.
+	Channels *Channels `json:"channels,omitempty"`
+	// IRQs is a slice of pointers to `IRQ` structs describing the interrupts
+	// used by the device backing this NIC.
+	IRQs []*IRQ `json:"irqs,omitempty"`
 	// IsVirtual is true if the NIC is entirely virtual/emulated, false
 	// otherwise.
 	IsVirtual bool `json:"is_virtual"`
+	// Type is the kind of network interface this NIC is, e.g. a bond, a
+	// bridge or a VLAN.
+	Type InterfaceType `json:"type"`
+	// Master is the name of the NIC this NIC is enslaved to, e.g. a bond or a
+	// bridge, or empty if the NIC is not enslaved.
+	Master string `json:"master,omitempty"`
+	// LowerDevices contains the names of the NICs this NIC is stacked on,
+	// e.g. the slaves of a bond or the parent of a VLAN interface.
+	LowerDevices []string `json:"lower_devices,omitempty"`
+	// UpperDevices contains the names of the NICs stacked on this NIC, e.g.
+	// the bond it is a slave of or its VLAN interfaces.
+	UpperDevices []string `json:"upper_devices,omitempty"`
+	// Bond is a pointer to a `BondInfo` struct describing the bonding
+	// configuration, or nil if this NIC is not a bond.
+	Bond *BondInfo `json:"bond,omitempty"`
+	// Bridge is a pointer to a `BridgeInfo` struct describing the bridge
+	// configuration, or nil if this NIC is not a bridge.
+	Bridge *BridgeInfo `json:"bridge,omitempty"`
+	// VLAN is a pointer to a `VLANInfo` struct describing the VLAN
+	// configuration, or nil if this NIC is not a VLAN interface.
+	VLAN *VLANInfo `json:"vlan,omitempty"`
 	// Capabilities is a slice of pointers to `NICCapability` structs
 	// describing a feature/capability of this NIC.
 	Capabilities []*NICCapability `json:"capabilities"`
@@ -64,6 +452,39 @@ type NIC struct {
 	// (during auto-negotiation) Forward Error Correction (FEC) modes for this
 	// NIC.
 	AdvertisedFECModes []string `json:"advertised_fec_modes,omitempty"`
+	// Driver is the name of the kernel driver bound to this NIC, e.g.
+	// "ixgbe", or empty if it could not be determined.
+	Driver string `json:"driver,omitempty"`
+	// DriverVersion is the version reported by the driver of this NIC. Most
+	// in-tree drivers report the version of the running kernel.
+	DriverVersion string `json:"driver_version,omitempty"`
+	// FirmwareVersion is the version of the firmware running on this NIC, as
+	// reported by its driver, or empty if the driver does not report one.
+	FirmwareVersion string `json:"firmware_version,omitempty"`
+	// BusInfo is the bus address of the device backing this NIC as reported
+	// by its driver, e.g. "0000:3b:00.0" for a PCI device.
+	BusInfo string `json:"bus_info,omitempty"`
+	// PCI is a pointer to a `pkg/pci.Device` struct describing the PCI device
+	// found at PCIAddress, or nil if the NIC is not backed by a PCI device.
+	PCI *pci.Device `json:"pci,omitempty"`
+	// NUMANodeID contains the numeric index (0-based) of the NUMA Node this
+	// NIC is affined to, or -1 if the host system is non-NUMA or the NIC is
+	// not backed by a physical device.
+	NUMANodeID int `json:"numa_node_id"`
+	// Node is a pointer to the `pkg/topology.Node` struct that the NIC is
+	// affined to. Will be nil if the architecture is not NUMA.
+	Node *topology.Node `json:"node,omitempty"`
+	// SRIOV is a pointer to an `SRIOVInfo` struct describing the SR-IOV
+	// physical or virtual function backing this NIC, or nil if the device
+	// backing this NIC is not SR-IOV capable.
+	SRIOV *SRIOVInfo `json:"sriov,omitempty"`
+	// RDMADevice is the name of the RDMA device backed by the same device as
+	// this NIC, e.g. "mlx5_0", or empty if the NIC is not RDMA capable.
+	RDMADevice string `json:"rdma_device,omitempty"`
+	// Wireless is a pointer to a `WirelessInfo` struct describing the
+	// wireless device backing this NIC, or nil if the NIC is not a wireless
+	// NIC.
+	Wireless *WirelessInfo `json:"wireless,omitempty"`
 	// TODO(fromani): add other hw addresses (USB) when we support them
 }
 
@@ -83,18 +504,46 @@ func (n *NIC) String() string {
 	if n.IsVirtual {
 		isVirtualStr = " (virtual)"
 	}
+	driverStr := ""
+	if n.Driver != "" {
+		driverStr = " driver=" + n.Driver
+	}
 	return fmt.Sprintf(
-		"%s%s",
+		"%s%s%s",
 		n.Name,
 		isVirtualStr,
+		driverStr,
 	)
 }
 
+// RemoteIRQs returns the IRQs of the NIC which are delivered to logical
+// processors outside of the NUMA node the NIC is affined to, which makes the
+// NIC pay for cross-node memory accesses. It returns nil if the NIC is not
+// affined to a NUMA node.
+func (n *NIC) RemoteIRQs() []*IRQ {
+	if n.NUMANodeID < 0 {
+		return nil
+	}
+	var remote []*IRQ
+	for _, irq := range n.IRQs {
+		for _, nodeID := range irq.AffinityNodeIDs {
+			if nodeID != n.NUMANodeID {
+				remote = append(remote, irq)
+				break
+			}
+		}
+	}
+	return remote
+}
+
 // Info describes all network interface controllers (NICs) in the host system.
 type Info struct {
 	// NICs is a slice of pointers to `NIC` structs describing the network
 	// interface controllers (NICs) on the host system.
 	NICs []*NIC `json:"nics"`
+	// RDMADevices is a slice of pointers to `RDMADevice` structs describing
+	// the RDMA devices, e.g. InfiniBand HCAs, on the host system.
+	RDMADevices []*RDMADevice `json:"rdma_devices,omitempty"`
 }
 
 // New returns a pointer to an Info struct that contains information about the
@@ -10,13 +10,19 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	gonet "net"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"syscall"
 
 	ghwcontext "github.com/go-hardware/ghw/pkg/context"
 	ghwpath "github.com/go-hardware/ghw/pkg/path"
+	"github.com/go-hardware/ghw/pkg/pci"
+	"github.com/go-hardware/ghw/pkg/topology"
 	"github.com/go-hardware/ghw/pkg/util"
 )
 
@@ -26,6 +32,10 @@ const (
 
 func (i *Info) load(ctx context.Context) error {
 	i.NICs = nics(ctx)
+	i.RDMADevices = rdmaDevices(ghwpath.New(ctx))
+	nicFillRDMADevice(i.NICs, i.RDMADevices)
+	fillPCIDevices(ctx, i.NICs, i.RDMADevices)
+	nicFillNUMANodes(ctx, i.NICs)
 	return nil
 }
 
@@ -38,15 +48,37 @@ func nics(ctx context.Context) []*NIC {
 		return nics
 	}
 
+	// Like the external tools, the SIOCETHTOOL ioctl and the IP address
+	// lookups report about the running system rather than the
+	// pseudo-filesystems we read (e.g. from a snapshot), so they are disabled
+	// along with the external tools.
 	opts := ghwcontext.OptionsFromContext(ctx)
-	etAvailable := opts.DisableExternalTools != nil && !*opts.DisableExternalTools
-	if etAvailable {
-		if etInstalled := ethtoolInstalled(); !etInstalled {
-			ghwcontext.Warn(ctx, warnEthtoolNotInstalled)
-			etAvailable = false
+	live := opts.DisableExternalTools != nil && !*opts.DisableExternalTools
+	etAvailable := live
+	etChecked := false
+
+	// We query the NICs natively with the SIOCETHTOOL ioctl and only fall
+	// back to running the ethtool program if that fails.
+	etFd := -1
+	if live {
+		if fd, err := ethtoolSocket(); err == nil {
+			etFd = fd
+			defer syscall.Close(etFd)
 		}
 	}
 
+	// Likewise, we only look for nl80211 once we find a wireless NIC
+	nlChecked := false
+	var nl *nl80211Conn
+	defer func() {
+		if nl != nil {
+			nl.close()
+		}
+	}()
+
+	vlans := netVLANs(paths)
+	interrupts := netInterrupts(paths)
+
 	for _, file := range files {
 		filename := file.Name()
 		// Ignore loopback...
@@ -62,13 +94,33 @@ func nics(ctx context.Context) []*NIC {
 		}
 
 		nic := &NIC{
-			Name:      filename,
-			IsVirtual: isVirtual,
+			Name:       filename,
+			IsVirtual:  isVirtual,
+			NUMANodeID: -1,
 		}
 
-		mac := netDeviceMacAddress(paths, filename)
-		nic.MACAddress = mac
-		if etAvailable {
+		nic.MACAddress, nic.MACAddressAssignType = netDeviceMacAddress(paths, filename)
+		nic.setNicAttrLinkSysFs(paths, filename)
+		nic.Statistics, _ = netDeviceStatistics(paths, filename)
+		if live {
+			nic.IPAddresses = netDeviceIPAddresses(filename)
+		}
+		var etInfo *ethtoolInfo
+		if etFd >= 0 {
+			etInfo, _ = ethtoolQuery(&ethtoolIoctl{fd: etFd, dev: filename})
+		}
+		if etAvailable && etInfo == nil && !etChecked {
+			// Only look for the ethtool program, and warn if it is missing,
+			// once we know we need it
+			etChecked = true
+			if etInstalled := ethtoolInstalled(); !etInstalled {
+				ghwcontext.Warn(ctx, warnEthtoolNotInstalled)
+				etAvailable = false
+			}
+		}
+		if etInfo != nil {
+			nic.setNicAttrEthtoolInfo(paths, filename, etInfo)
+		} else if etAvailable {
 			nic.netDeviceParseEthtool(ctx, filename)
 		} else {
 			nic.Capabilities = []*NICCapability{}
@@ -77,31 +129,97 @@ func nics(ctx context.Context) []*NIC {
 		}
 
 		nic.PCIAddress = netDevicePCIAddress(paths.SysClassNet, filename)
+		nic.setNicAttrDriverSysFs(paths, filename)
+		nic.setNicAttrTopologySysFs(paths, filename, vlans)
+		nic.NUMANodeID = netDeviceNUMANodeID(paths, nic.PCIAddress)
+		nic.SRIOV = netDeviceSRIOV(ctx, paths, nic.PCIAddress)
+		nic.RxQueues, nic.TxQueues = netDeviceQueues(paths, filename)
+		nic.IRQs = netDeviceIRQs(paths, nic.PCIAddress, interrupts)
+		nic.Wireless = netDeviceWireless(paths, filename)
+		if nic.Wireless != nil && live {
+			if !nlChecked {
+				nlChecked = true
+				nl, _ = nl80211Dial()
+			}
+			if nl != nil {
+				nic.Wireless.setWirelessAttrNl80211(nl)
+			}
+		}
 
 		nics = append(nics, nic)
 	}
 	return nics
 }
 
-func netDeviceMacAddress(paths *ghwpath.Paths, dev string) string {
-	// Instead of use udevadm, we can get the device's MAC address by examing
-	// the /sys/class/net/$DEVICE/address file in sysfs. However, for devices
-	// that have addr_assign_type != 0, return None since the MAC address is
-	// random.
-	aatPath := filepath.Join(paths.SysClassNet, dev, "addr_assign_type")
-	contents, err := os.ReadFile(aatPath)
-	if err != nil {
-		return ""
+// netDeviceMacAddress returns the current MAC address of the device along
+// with the way it was assigned. Instead of using udevadm, we can get the MAC
+// address by examining the /sys/class/net/$DEVICE/address file in sysfs.
+func netDeviceMacAddress(paths *ghwpath.Paths, dev string) (string, AddressAssignType) {
+	addr := util.ReadTrimmedFile(filepath.Join(paths.SysClassNet, dev, "address"))
+	// addr_assign_type holds one of the NET_ADDR_* values from
+	// include/uapi/linux/netdevice.h
+	switch util.ReadTrimmedFile(filepath.Join(paths.SysClassNet, dev, "addr_assign_type")) {
+	case "0":
+		return addr, AddressAssignTypePermanent
+	case "1":
+		return addr, AddressAssignTypeRandom
+	case "2":
+		return addr, AddressAssignTypeStolen
+	case "3":
+		return addr, AddressAssignTypeSet
 	}
-	if strings.TrimSpace(string(contents)) != "0" {
-		return ""
+	return addr, AddressAssignTypeUnknown
+}
+
+// setNicAttrLinkSysFs sets the interface index, operational state, carrier,
+// MTU and transmit queue length of the NIC from the data in sysfs
+func (nic *NIC) setNicAttrLinkSysFs(paths *ghwpath.Paths, dev string) {
+	devPath := filepath.Join(paths.SysClassNet, dev)
+	nic.Index = readIntFile(filepath.Join(devPath, "ifindex"))
+	nic.OperState = util.ReadTrimmedFile(filepath.Join(devPath, "operstate"))
+	// Reading carrier fails with EINVAL while the interface is down
+	nic.HasCarrier = util.ReadTrimmedFile(filepath.Join(devPath, "carrier")) == "1"
+	nic.MTU = readIntFile(filepath.Join(devPath, "mtu"))
+	nic.TxQueueLength = readIntFile(filepath.Join(devPath, "tx_queue_len"))
+}
+
+// netDeviceIPAddresses returns the IP addresses currently assigned to the
+// network interface
+func netDeviceIPAddresses(dev string) []*IPAddress {
+	iface, err := gonet.InterfaceByName(dev)
+	if err != nil {
+		return nil
 	}
-	addrPath := filepath.Join(paths.SysClassNet, dev, "address")
-	contents, err = os.ReadFile(addrPath)
+	addrs, err := iface.Addrs()
 	if err != nil {
-		return ""
+		return nil
+	}
+	return ipAddresses(addrs)
+}
+
+func ipAddresses(addrs []gonet.Addr) []*IPAddress {
+	var res []*IPAddress
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*gonet.IPNet)
+		if !ok {
+			continue
+		}
+		prefixLen, _ := ipNet.Mask.Size()
+		scope := "global"
+		switch {
+		case ipNet.IP.IsLoopback():
+			scope = "host"
+		case ipNet.IP.IsLinkLocalUnicast():
+			scope = "link"
+		}
+		res = append(res, &IPAddress{
+			Address:      ipNet.IP.String(),
+			PrefixLength: prefixLen,
+			IsIPv6:       ipNet.IP.To4() == nil,
+			Scope:        scope,
+		})
 	}
-	return strings.TrimSpace(string(contents))
+	return res
 }
 
 func ethtoolInstalled() bool {
@@ -175,6 +293,115 @@ func (n *NIC) netDeviceParseEthtool(ctx context.Context, dev string) {
 		ghwcontext.Warn(ctx, msg)
 	}
 
+	// Get the permanent MAC address from "ethtool -P", which prints a single
+	// line like "Permanent address: 3c:ec:ef:12:34:56"
+	var permOut bytes.Buffer
+	cmd = exec.CommandContext(ctx, path, "-P", dev)
+	cmd.Stdout = &permOut
+	if err = cmd.Run(); err == nil {
+		m := parseKeyValueEthtool(&permOut)
+		n.PermanentMACAddress = permanentMACAddress(m["Permanent address"])
+	}
+
+	// Get the driver information from "ethtool -i"
+	var drvOut bytes.Buffer
+	cmd = exec.CommandContext(ctx, path, "-i", dev)
+	cmd.Stdout = &drvOut
+	if err = cmd.Run(); err == nil {
+		m := parseKeyValueEthtool(&drvOut)
+		n.Driver = m["driver"]
+		n.DriverVersion = m["version"]
+		n.FirmwareVersion = m["firmware-version"]
+		n.BusInfo = m["bus-info"]
+	}
+
+	// Get the channels from "ethtool -l"
+	var chOut bytes.Buffer
+	cmd = exec.CommandContext(ctx, path, "-l", dev)
+	cmd.Stdout = &chOut
+	if err = cmd.Run(); err == nil {
+		n.Channels = parseChannelsEthtool(&chOut)
+	}
+}
+
+// parseChannelsEthtool parses the output of "ethtool -l", which looks like
+// this:
+//
+// Channel parameters for ens1f0:
+// Pre-set maximums:
+// RX:		n/a
+// TX:		n/a
+// Other:		1
+// Combined:	63
+// Current hardware settings:
+// RX:		n/a
+// TX:		n/a
+// Other:		1
+// Combined:	8
+func parseChannelsEthtool(out *bytes.Buffer) *Channels {
+	ch := &Channels{}
+	maxs := map[string]*int{
+		"RX":       &ch.MaxRx,
+		"TX":       &ch.MaxTx,
+		"Other":    &ch.MaxOther,
+		"Combined": &ch.MaxCombined,
+	}
+	cur := map[string]*int{
+		"RX":       &ch.Rx,
+		"TX":       &ch.Tx,
+		"Other":    &ch.Other,
+		"Combined": &ch.Combined,
+	}
+	var fields map[string]*int
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "Pre-set maximums"):
+			fields = maxs
+			continue
+		case strings.HasPrefix(line, "Current hardware settings"):
+			fields = cur
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if fields == nil || len(parts) != 2 {
+			continue
+		}
+		field, ok := fields[strings.TrimSpace(parts[0])]
+		if !ok {
+			continue
+		}
+		// "n/a" means the driver has no such channels
+		if val, err := strconv.Atoi(strings.TrimSpace(parts[1])); err == nil {
+			*field = val
+		}
+	}
+	return ch
+}
+
+// parseKeyValueEthtool parses the "key: value" lines printed by `ethtool -i`
+// and `ethtool -P` into a map keyed by the field names. The output of
+// `ethtool -i` looks like the following:
+//
+// driver: e1000e
+// version: 6.5.0-14-generic
+// firmware-version: 0.13-4
+// expansion-rom-version:
+// bus-info: 0000:00:1f.6
+// supports-statistics: yes
+// < snipped >
+func parseKeyValueEthtool(out *bytes.Buffer) map[string]string {
+	m := make(map[string]string)
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		m[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return m
 }
 
 // netParseEthtoolFeature parses a line from the ethtool -k output and returns
@@ -239,6 +466,15 @@ func netDevicePCIAddress(netDevDir, netDevName string) *string {
 		// bail out with empty value
 		return nil
 	}
+	// virtio devices sit below the virtio PCI function which transports them,
+	// e.g. "/sys/devices/pci0000:00/0000:00:04.0/virtio3"
+	if strings.HasSuffix(dest, "/bus/virtio") {
+		devPath = filepath.Dir(devPath)
+		dest, err = os.Readlink(filepath.Join(devPath, "subsystem"))
+		if err != nil {
+			return nil
+		}
+	}
 	// ok, this is hacky, but since we need the last *two* path components and we know we
 	// are running on linux...
 	if !strings.HasSuffix(dest, "/bus/pci") {
@@ -250,18 +486,139 @@ func netDevicePCIAddress(netDevDir, netDevName string) *string {
 	return &pciAddr
 }
 
+// setNicAttrDriverSysFs sets the driver of the NIC from the driver bound to
+// the device backing it, which is more reliable than the name the driver
+// reports about itself. The driver version, if not known yet, is the version
+// of the kernel module implementing the driver.
+func (nic *NIC) setNicAttrDriverSysFs(paths *ghwpath.Paths, dev string) {
+	driverPath := filepath.Join(paths.SysClassNet, dev, "device", "driver")
+	if dest, err := os.Readlink(driverPath); err == nil {
+		nic.Driver = filepath.Base(dest)
+	}
+	if nic.DriverVersion == "" {
+		nic.DriverVersion = util.ReadTrimmedFile(filepath.Join(driverPath, "module", "version"))
+	}
+	if nic.BusInfo == "" && nic.PCIAddress != nil {
+		nic.BusInfo = *nic.PCIAddress
+	}
+}
+
+// netDeviceNUMANodeID returns the NUMA node the PCI device backing the NIC is
+// affined to, or -1 if the NIC is not backed by a PCI device or the host
+// system is non-NUMA.
+func netDeviceNUMANodeID(paths *ghwpath.Paths, pciAddr *string) int {
+	if pciAddr == nil {
+		return -1
+	}
+	nodePath := filepath.Join(paths.SysBusPciDevices, *pciAddr, "numa_node")
+	nodeID, err := strconv.Atoi(util.ReadTrimmedFile(nodePath))
+	if err != nil {
+		return -1
+	}
+	return nodeID
+}
+
+// fillPCIDevices loops through each NIC and RDMA device struct and attempts to
+// fill the PCI attribute with PCI device information
+func fillPCIDevices(ctx context.Context, nics []*NIC, rdmaDevs []*RDMADevice) {
+	var pciInfo *pci.Info
+	pciFailed := false
+	getDevice := func(addr string) *pci.Device {
+		if pciFailed {
+			return nil
+		}
+		if pciInfo == nil {
+			info, err := pci.New(ctx)
+			if err != nil {
+				ghwcontext.Warn(ctx, "failed to get PCI information for NICs: %s\n", err)
+				pciFailed = true
+				return nil
+			}
+			pciInfo = info
+		}
+		return pciInfo.GetDevice(ctx, addr)
+	}
+	for _, nic := range nics {
+		if nic.PCIAddress != nil {
+			nic.PCI = getDevice(*nic.PCIAddress)
+		}
+	}
+	for _, dev := range rdmaDevs {
+		if dev.PCIAddress != nil {
+			dev.PCI = getDevice(*dev.PCIAddress)
+		}
+	}
+}
+
+// nicFillNUMANodes loops through each NIC struct and sets the NIC.Node field
+// to the topology node matching the NIC.NUMANodeID, along with the nodes its
+// IRQs are delivered to. If the host system is not a NUMA system, the Node
+// field will be left nil.
+func nicFillNUMANodes(ctx context.Context, nics []*NIC) {
+	var topo *topology.Info
+	for _, nic := range nics {
+		if nic.NUMANodeID < 0 && len(nic.IRQs) == 0 {
+			continue
+		}
+		if topo == nil {
+			info, err := topology.New(ctx)
+			if err != nil {
+				ghwcontext.Warn(ctx, "failed to get topology information for NICs: %s\n", err)
+				return
+			}
+			topo = info
+		}
+		for _, node := range topo.Nodes {
+			if nic.NUMANodeID == node.ID {
+				nic.Node = node
+			}
+		}
+		nic.setIRQAffinityNodes(topo.Nodes)
+	}
+}
+
+// setIRQAffinityNodes sets the IDs of the NUMA nodes each IRQ of the NIC is
+// delivered to, from the logical processors of the supplied nodes.
+func (nic *NIC) setIRQAffinityNodes(nodes []*topology.Node) {
+	cpuNodes := make(map[int]int)
+	for _, node := range nodes {
+		for _, core := range node.Cores {
+			for _, lp := range core.LogicalProcessors {
+				cpuNodes[lp] = node.ID
+			}
+		}
+	}
+	for _, irq := range nic.IRQs {
+		cpus := irq.EffectiveAffinityCPUs
+		if len(cpus) == 0 {
+			cpus = irq.AffinityCPUs
+		}
+		seen := make(map[int]bool)
+		irq.AffinityNodeIDs = nil
+		for _, cpu := range cpus {
+			nodeID, ok := cpuNodes[cpu]
+			if !ok || seen[nodeID] {
+				continue
+			}
+			seen[nodeID] = true
+			irq.AffinityNodeIDs = append(irq.AffinityNodeIDs, nodeID)
+		}
+		sort.Ints(irq.AffinityNodeIDs)
+	}
+}
+
 func (nic *NIC) setNicAttrSysFs(paths *ghwpath.Paths, dev string) {
 	// Get speed and duplex from /sys/class/net/$DEVICE/ directory
-	nic.Speed = readFile(filepath.Join(paths.SysClassNet, dev, "speed"))
-	nic.Duplex = readFile(filepath.Join(paths.SysClassNet, dev, "duplex"))
+	nic.Speed = util.ReadTrimmedFile(filepath.Join(paths.SysClassNet, dev, "speed"))
+	nic.Duplex = util.ReadTrimmedFile(filepath.Join(paths.SysClassNet, dev, "duplex"))
 }
 
-func readFile(path string) string {
-	contents, err := os.ReadFile(path)
+func readIntFile(path string) int {
+	val, err := strconv.Atoi(util.ReadTrimmedFile(path))
 	if err != nil {
-		return ""
+		return -1
 	}
-	return strings.TrimSpace(string(contents))
+	return val
 }
 
 func autoNegCap(m map[string][]string) *NICCapability {
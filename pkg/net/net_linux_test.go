@@ -11,10 +11,16 @@ package net
 
 import (
 	"bytes"
+	gonet "net"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	ghwcontext "github.com/go-hardware/ghw/pkg/context"
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
 )
 
 func TestParseEthtoolFeature(t *testing.T) {
@@ -148,3 +154,261 @@ func TestParseNicAttrEthtool(t *testing.T) {
 		}
 	}
 }
+
+func TestParseKeyValueEthtool(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+
+	m := parseKeyValueEthtool(bytes.NewBufferString(`driver: e1000e
+version: 6.5.0-14-generic
+firmware-version: 0.13-4
+expansion-rom-version:
+bus-info: 0000:00:1f.6
+supports-statistics: yes
+supports-test: yes
+supports-eeprom-access: yes
+supports-register-dump: yes
+supports-priv-flags: yes
+`))
+	expected := map[string]string{
+		"driver":           "e1000e",
+		"version":          "6.5.0-14-generic",
+		"firmware-version": "0.13-4",
+		"bus-info":         "0000:00:1f.6",
+	}
+	for key, val := range expected {
+		if m[key] != val {
+			t.Fatalf("Expected %s to be %q but got %q", key, val, m[key])
+		}
+	}
+}
+
+func TestNICDriverAndNUMANode(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+	baseDir, _ := os.MkdirTemp("", "test")
+	defer os.RemoveAll(baseDir)
+	ctx := ghwcontext.New(ghwcontext.WithRootMountpoint(baseDir))
+	paths := ghwpath.New(ctx)
+	sysDir := filepath.Join(baseDir, "sys")
+
+	// eth0 is a virtio NIC transported by the PCI function 0000:00:04.0,
+	// which is affined to NUMA node 1, and veth0 is a virtual NIC
+	pciDir := filepath.Join(sysDir, "devices", "pci0000:00", "0000:00:04.0")
+	virtioDir := filepath.Join(pciDir, "virtio3")
+	_ = os.MkdirAll(filepath.Join(virtioDir, "net", "eth0"), 0755)
+	_ = os.MkdirAll(filepath.Join(sysDir, "devices", "virtual", "net", "veth0"), 0755)
+	_ = os.MkdirAll(filepath.Join(sysDir, "bus", "virtio", "drivers", "virtio_net"), 0755)
+	_ = os.MkdirAll(filepath.Join(sysDir, "module", "virtio_net"), 0755)
+	_ = os.MkdirAll(paths.SysClassNet, 0755)
+	_ = os.MkdirAll(paths.SysBusPciDevices, 0755)
+	_ = os.WriteFile(filepath.Join(pciDir, "numa_node"), []byte("1\n"), 0644)
+	_ = os.WriteFile(filepath.Join(sysDir, "module", "virtio_net", "version"), []byte("1.0.0\n"), 0644)
+	_ = os.Symlink("../../../bus/pci", filepath.Join(pciDir, "subsystem"))
+	_ = os.Symlink("../../../../bus/virtio", filepath.Join(virtioDir, "subsystem"))
+	_ = os.Symlink("../../../../bus/virtio/drivers/virtio_net", filepath.Join(virtioDir, "driver"))
+	_ = os.Symlink("../../../../module/virtio_net", filepath.Join(sysDir, "bus", "virtio", "drivers", "virtio_net", "module"))
+	_ = os.Symlink("../../../virtio3", filepath.Join(virtioDir, "net", "eth0", "device"))
+	_ = os.Symlink("../../devices/pci0000:00/0000:00:04.0/virtio3/net/eth0", filepath.Join(paths.SysClassNet, "eth0"))
+	_ = os.Symlink("../../devices/virtual/net/veth0", filepath.Join(paths.SysClassNet, "veth0"))
+	_ = os.Symlink("../../../devices/pci0000:00/0000:00:04.0", filepath.Join(paths.SysBusPciDevices, "0000:00:04.0"))
+
+	eth0 := &NIC{Name: "eth0"}
+	eth0.PCIAddress = netDevicePCIAddress(paths.SysClassNet, "eth0")
+	if eth0.PCIAddress == nil || *eth0.PCIAddress != "0000:00:04.0" {
+		t.Fatalf("Expected PCI address 0000:00:04.0 for eth0, but got %v", eth0.PCIAddress)
+	}
+	eth0.setNicAttrDriverSysFs(paths, "eth0")
+	if eth0.Driver != "virtio_net" || eth0.DriverVersion != "1.0.0" || eth0.BusInfo != "0000:00:04.0" {
+		t.Fatalf("Unexpected driver information for eth0: %+v", eth0)
+	}
+	if nodeID := netDeviceNUMANodeID(paths, eth0.PCIAddress); nodeID != 1 {
+		t.Fatalf("Expected NUMA node 1 for eth0, but got %d", nodeID)
+	}
+
+	// The driver reported by ethtool is replaced by the bound driver, but its
+	// version is kept
+	eth0 = &NIC{Name: "eth0", Driver: "virtio", DriverVersion: "6.8.0"}
+	eth0.setNicAttrDriverSysFs(paths, "eth0")
+	if eth0.Driver != "virtio_net" || eth0.DriverVersion != "6.8.0" {
+		t.Fatalf("Unexpected driver information for eth0: %+v", eth0)
+	}
+
+	veth0 := &NIC{Name: "veth0"}
+	veth0.PCIAddress = netDevicePCIAddress(paths.SysClassNet, "veth0")
+	if veth0.PCIAddress != nil {
+		t.Fatalf("Expected no PCI address for veth0, but got %s", *veth0.PCIAddress)
+	}
+	veth0.setNicAttrDriverSysFs(paths, "veth0")
+	if veth0.Driver != "" || veth0.BusInfo != "" {
+		t.Fatalf("Unexpected driver information for veth0: %+v", veth0)
+	}
+	if nodeID := netDeviceNUMANodeID(paths, veth0.PCIAddress); nodeID != -1 {
+		t.Fatalf("Expected NUMA node -1 for veth0, but got %d", nodeID)
+	}
+}
+
+func TestNICLinkSysFs(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+	baseDir, _ := os.MkdirTemp("", "test")
+	defer os.RemoveAll(baseDir)
+	ctx := ghwcontext.New(ghwcontext.WithRootMountpoint(baseDir))
+	paths := ghwpath.New(ctx)
+
+	// bond0 is up and uses the address of its first slave, while eth1 is down
+	files := map[string]map[string]string{
+		"bond0": {
+			"address":          "3c:ec:ef:12:34:56",
+			"addr_assign_type": "2",
+			"ifindex":          "7",
+			"operstate":        "up",
+			"carrier":          "1",
+			"mtu":              "9000",
+			"tx_queue_len":     "1000",
+		},
+		"eth1": {
+			"address":          "3c:ec:ef:12:34:57",
+			"addr_assign_type": "0",
+			"ifindex":          "3",
+			"operstate":        "down",
+			"mtu":              "1500",
+		},
+	}
+	for dev, entries := range files {
+		_ = os.MkdirAll(filepath.Join(paths.SysClassNet, dev), 0755)
+		for name, val := range entries {
+			_ = os.WriteFile(filepath.Join(paths.SysClassNet, dev, name), []byte(val+"\n"), 0644)
+		}
+	}
+
+	tests := []struct {
+		dev        string
+		mac        string
+		assignType AddressAssignType
+		index      int
+		operState  string
+		carrier    bool
+		mtu        int
+		txQueueLen int
+	}{
+		{"bond0", "3c:ec:ef:12:34:56", AddressAssignTypeStolen, 7, "up", true, 9000, 1000},
+		{"eth1", "3c:ec:ef:12:34:57", AddressAssignTypePermanent, 3, "down", false, 1500, -1},
+	}
+	for _, test := range tests {
+		nic := &NIC{Name: test.dev}
+		nic.MACAddress, nic.MACAddressAssignType = netDeviceMacAddress(paths, test.dev)
+		nic.setNicAttrLinkSysFs(paths, test.dev)
+		if nic.MACAddress != test.mac || nic.MACAddressAssignType != test.assignType {
+			t.Fatalf("Expected MAC address %s (%s) for %s, but got %s (%s)", test.mac, test.assignType, test.dev, nic.MACAddress, nic.MACAddressAssignType)
+		}
+		if nic.Index != test.index || nic.OperState != test.operState || nic.HasCarrier != test.carrier {
+			t.Fatalf("Unexpected link state for %s: %+v", test.dev, nic)
+		}
+		if nic.MTU != test.mtu || nic.TxQueueLength != test.txQueueLen {
+			t.Fatalf("Unexpected MTU or transmit queue length for %s: %+v", test.dev, nic)
+		}
+	}
+}
+
+func TestIPAddresses(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+
+	addrs := []gonet.Addr{}
+	for _, cidr := range []string{"192.0.2.10/24", "2001:db8::10/64", "fe80::1/64", "127.0.0.1/8"} {
+		ip, ipNet, _ := gonet.ParseCIDR(cidr)
+		ipNet.IP = ip
+		addrs = append(addrs, ipNet)
+	}
+	expected := []*IPAddress{
+		{Address: "192.0.2.10", PrefixLength: 24, IsIPv6: false, Scope: "global"},
+		{Address: "2001:db8::10", PrefixLength: 64, IsIPv6: true, Scope: "global"},
+		{Address: "fe80::1", PrefixLength: 64, IsIPv6: true, Scope: "link"},
+		{Address: "127.0.0.1", PrefixLength: 8, IsIPv6: false, Scope: "host"},
+	}
+	actual := ipAddresses(addrs)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected:\n%v\nActual:\n%v\n", expected, actual)
+	}
+}
+
+func TestNetDeviceStatistics(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+	baseDir, _ := os.MkdirTemp("", "test")
+	defer os.RemoveAll(baseDir)
+	ctx := ghwcontext.New(ghwcontext.WithRootMountpoint(baseDir))
+	paths := ghwpath.New(ctx)
+
+	statsDir := filepath.Join(paths.SysClassNet, "eth0", "statistics")
+	_ = os.MkdirAll(statsDir, 0755)
+	counters := map[string]string{
+		"rx_bytes":       "1844674407370955",
+		"rx_packets":     "123456",
+		"rx_crc_errors":  "3",
+		"rx_fifo_errors": "2",
+		"tx_bytes":       "98765",
+		"tx_dropped":     "1",
+	}
+	for name, val := range counters {
+		_ = os.WriteFile(filepath.Join(statsDir, name), []byte(val+"\n"), 0644)
+	}
+
+	stats, err := netDeviceStatistics(paths, "eth0")
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if stats.Time.IsZero() {
+		t.Fatalf("Expected the time the counters were read at to be set")
+	}
+	stats.Time = time.Time{}
+	expected := &Statistics{
+		RxBytes:      1844674407370955,
+		RxPackets:    123456,
+		RxCRCErrors:  3,
+		RxFIFOErrors: 2,
+		TxBytes:      98765,
+		TxDropped:    1,
+	}
+	if !reflect.DeepEqual(expected, stats) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", expected, stats)
+	}
+
+	if _, err := netDeviceStatistics(paths, "eth1"); err == nil {
+		t.Fatalf("Expected an error reading the statistics of a missing NIC, but got nil")
+	}
+}
+
+func TestParseChannelsEthtool(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+
+	out := bytes.NewBufferString(`Channel parameters for ens1f0:
+Pre-set maximums:
+RX:		n/a
+TX:		n/a
+Other:		1
+Combined:	63
+Current hardware settings:
+RX:		n/a
+TX:		n/a
+Other:		1
+Combined:	8
+`)
+	expected := &Channels{
+		MaxOther:    1,
+		MaxCombined: 63,
+		Other:       1,
+		Combined:    8,
+	}
+	if actual := parseChannelsEthtool(out); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %+v but got %+v", expected, actual)
+	}
+}
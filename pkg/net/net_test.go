@@ -8,8 +8,10 @@ package net_test
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/go-hardware/ghw/pkg/net"
 )
@@ -36,3 +38,104 @@ func TestNet(t *testing.T) {
 		}
 	}
 }
+
+func TestNetMarshalUnmarshal(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+
+	info, err := net.New(context.TODO())
+	if err != nil {
+		t.Fatalf("Expected no error creating net.Info, but got %v", err)
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatalf("Expected no error marshaling net.Info, but got %v", err)
+	}
+
+	var ni *net.Info
+	err = json.Unmarshal(data, &ni)
+	if err != nil {
+		t.Fatalf("Expected no error unmarshaling net.Info, but got %v", err)
+	}
+	if len(ni.NICs) != len(info.NICs) {
+		t.Fatalf("Expected %d NICs but got %d", len(info.NICs), len(ni.NICs))
+	}
+	for x, nic := range ni.NICs {
+		if nic.MACAddressAssignType != info.NICs[x].MACAddressAssignType {
+			t.Fatalf("Expected address assign type %s for %s but got %s", info.NICs[x].MACAddressAssignType, nic.Name, nic.MACAddressAssignType)
+		}
+		if nic.Type != info.NICs[x].Type {
+			t.Fatalf("Expected interface type %s for %s but got %s", info.NICs[x].Type, nic.Name, nic.Type)
+		}
+	}
+}
+
+func TestStatisticsRates(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	prev := &net.Statistics{
+		Time:      start,
+		RxBytes:   1000,
+		RxPackets: 10,
+		TxBytes:   4000,
+		TxErrors:  5,
+	}
+	cur := &net.Statistics{
+		Time:      start.Add(2 * time.Second),
+		RxBytes:   3000,
+		RxPackets: 30,
+		TxBytes:   4000,
+		// the counter was reset, e.g. by re-creating the NIC
+		TxErrors: 1,
+	}
+	expected := net.Rates{
+		Interval:           2 * time.Second,
+		RxBytesPerSecond:   1000,
+		RxPacketsPerSecond: 10,
+	}
+	if rates := cur.Rates(prev); *rates != expected {
+		t.Fatalf("Expected %+v but got %+v", expected, *rates)
+	}
+
+	// samples taken at the same time have no meaningful rates
+	if rates := prev.Rates(prev); *rates != (net.Rates{}) {
+		t.Fatalf("Expected zero rates but got %+v", *rates)
+	}
+	// nor do samples without an earlier one
+	if rates := cur.Rates(nil); *rates != (net.Rates{}) {
+		t.Fatalf("Expected zero rates but got %+v", *rates)
+	}
+}
+
+func TestSampleRates(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+
+	info, err := net.New(context.TODO())
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if len(info.NICs) == 0 || info.NICs[0].Statistics == nil {
+		t.Skip("Skipping sampling rates, no NIC statistics found.")
+	}
+
+	rates, err := net.SampleRates(context.TODO(), info.NICs[0].Name, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	if rates.Interval < 10*time.Millisecond {
+		t.Fatalf("Expected an interval of at least 10ms but got %v", rates.Interval)
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	if _, err := net.SampleRates(ctx, info.NICs[0].Name, time.Hour); err == nil {
+		t.Fatalf("Expected an error sampling rates with a cancelled context, but got nil")
+	}
+}
@@ -44,10 +44,17 @@ func nics(win32NetDescriptions []win32NetworkAdapter) []*NIC {
 	nics := make([]*NIC, 0)
 	for _, nicDescription := range win32NetDescriptions {
 		nic := &NIC{
-			Name:         netDeviceName(nicDescription),
-			MACAddress:   *nicDescription.MACAddress,
-			IsVirtual:    netIsVirtual(nicDescription),
-			Capabilities: []*NICCapability{},
+			Name:          netDeviceName(nicDescription),
+			MACAddress:    *nicDescription.MACAddress,
+			IsVirtual:     netIsVirtual(nicDescription),
+			Capabilities:  []*NICCapability{},
+			NUMANodeID:    -1,
+			Index:         netDeviceIndex(nicDescription),
+			MTU:           -1,
+			TxQueueLength: -1,
+		}
+		if !nic.IsVirtual {
+			nic.Type = InterfaceTypePhysical
 		}
 		nics = append(nics, nic)
 	}
@@ -72,3 +79,10 @@ func netIsVirtual(description win32NetworkAdapter) bool {
 
 	return !(*description.PhysicalAdapter)
 }
+
+func netDeviceIndex(description win32NetworkAdapter) int {
+	if description.InterfaceIndex == nil {
+		return -1
+	}
+	return int(*description.InterfaceIndex)
+}
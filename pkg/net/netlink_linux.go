@@ -0,0 +1,175 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package net
+
+import (
+	"errors"
+	"syscall"
+)
+
+const (
+	// NETLINK_GENERIC, see include/uapi/linux/netlink.h
+	netlinkGeneric = 16
+
+	// Sizes of `struct nlmsghdr`, `struct genlmsghdr` and `struct nlattr`
+	nlmsgHdrLen  = 16
+	genlHdrLen   = 4
+	nlAttrHdrLen = 4
+
+	// NLA_TYPE_MASK, which clears the NLA_F_NESTED and NLA_F_NET_BYTEORDER
+	// flags
+	nlAttrTypeMask = 0x3fff
+
+	// The generic netlink controller, see include/uapi/linux/genetlink.h
+	genlIDCtrl             = 0x10
+	genlCtrlCmdGetFamily   = 3
+	genlCtrlAttrFamilyID   = 1
+	genlCtrlAttrFamilyName = 2
+
+	// genlRecvBufferLen is large enough for the messages of a netlink dump
+	genlRecvBufferLen = 32768
+)
+
+// nlAttr is a netlink attribute
+type nlAttr struct {
+	typ  uint16
+	data []byte
+}
+
+// parseNlAttrs parses the netlink attributes in the supplied buffer,
+// stopping at the first malformed one
+func parseNlAttrs(buf []byte) []nlAttr {
+	var attrs []nlAttr
+	for len(buf) >= nlAttrHdrLen {
+		length := int(nativeEndian.Uint16(buf[0:2]))
+		if length < nlAttrHdrLen || length > len(buf) {
+			break
+		}
+		attrs = append(attrs, nlAttr{
+			typ:  nativeEndian.Uint16(buf[2:4]) & nlAttrTypeMask,
+			data: buf[nlAttrHdrLen:length],
+		})
+		aligned := nlAlign(length)
+		if aligned >= len(buf) {
+			break
+		}
+		buf = buf[aligned:]
+	}
+	return attrs
+}
+
+// appendNlAttr appends a netlink attribute with the supplied type and
+// payload to the buffer
+func appendNlAttr(buf []byte, typ uint16, data []byte) []byte {
+	length := nlAttrHdrLen + len(data)
+	hdr := make([]byte, nlAttrHdrLen)
+	nativeEndian.PutUint16(hdr[0:2], uint16(length))
+	nativeEndian.PutUint16(hdr[2:4], typ)
+	buf = append(buf, hdr...)
+	buf = append(buf, data...)
+	return append(buf, make([]byte, nlAlign(length)-length)...)
+}
+
+// nlAlign rounds up the supplied length to the 4 bytes netlink messages and
+// attributes are aligned to
+func nlAlign(length int) int {
+	return (length + 3) &^ 3
+}
+
+// genlConn is a generic netlink socket
+type genlConn struct {
+	fd  int
+	seq uint32
+}
+
+// genlDial opens a generic netlink socket
+func genlDial() (*genlConn, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, netlinkGeneric)
+	if err != nil {
+		return nil, err
+	}
+	return &genlConn{fd: fd}, nil
+}
+
+func (c *genlConn) close() {
+	syscall.Close(c.fd)
+}
+
+// execute sends a generic netlink request with the supplied command and
+// attributes to the family with the supplied ID, and returns the attributes
+// of the replies: all the replies until the end of the dump for dump
+// requests, the only reply otherwise.
+func (c *genlConn) execute(family uint16, cmd uint8, flags uint16, attrs []byte) ([][]byte, error) {
+	c.seq++
+	msg := make([]byte, nlmsgHdrLen+genlHdrLen, nlmsgHdrLen+genlHdrLen+len(attrs))
+	msg = append(msg, attrs...)
+	nativeEndian.PutUint32(msg[0:4], uint32(len(msg)))
+	nativeEndian.PutUint16(msg[4:6], family)
+	nativeEndian.PutUint16(msg[6:8], syscall.NLM_F_REQUEST|flags)
+	nativeEndian.PutUint32(msg[8:12], c.seq)
+	msg[nlmsgHdrLen] = cmd
+	msg[nlmsgHdrLen+1] = 1 // version
+	if err := syscall.Sendto(c.fd, msg, 0, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		return nil, err
+	}
+
+	var replies [][]byte
+	buf := make([]byte, genlRecvBufferLen)
+	for {
+		n, _, err := syscall.Recvfrom(c.fd, buf, 0)
+		if err != nil {
+			return nil, err
+		}
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range msgs {
+			if m.Header.Seq != c.seq {
+				continue
+			}
+			switch m.Header.Type {
+			case syscall.NLMSG_DONE:
+				return replies, nil
+			case syscall.NLMSG_ERROR:
+				if len(m.Data) < 4 {
+					return nil, errors.New("truncated netlink error message")
+				}
+				if errno := int32(nativeEndian.Uint32(m.Data[0:4])); errno != 0 {
+					return nil, syscall.Errno(-errno)
+				}
+				return replies, nil
+			}
+			if len(m.Data) < genlHdrLen {
+				continue
+			}
+			// the buffer is reused for the next replies
+			replies = append(replies, append([]byte(nil), m.Data[genlHdrLen:]...))
+			if flags&syscall.NLM_F_DUMP == 0 {
+				return replies, nil
+			}
+		}
+	}
+}
+
+// familyID returns the ID of the generic netlink family with the supplied
+// name, e.g. "nl80211"
+func (c *genlConn) familyID(name string) (uint16, error) {
+	attrs := appendNlAttr(nil, genlCtrlAttrFamilyName, append([]byte(name), 0))
+	replies, err := c.execute(genlIDCtrl, genlCtrlCmdGetFamily, 0, attrs)
+	if err != nil {
+		return 0, err
+	}
+	for _, reply := range replies {
+		for _, attr := range parseNlAttrs(reply) {
+			if attr.typ == genlCtrlAttrFamilyID && len(attr.data) >= 2 {
+				return nativeEndian.Uint16(attr.data), nil
+			}
+		}
+	}
+	return 0, errors.New("generic netlink family " + name + " not found")
+}
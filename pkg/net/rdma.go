@@ -0,0 +1,108 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package net
+
+import (
+	"fmt"
+
+	"github.com/go-hardware/ghw/pkg/pci"
+)
+
+// RDMAGID describes an entry of the GID table of an RDMA port.
+type RDMAGID struct {
+	// Index is the index of the entry in the GID table, which RoCE
+	// applications use to select the GID, and hence the RoCE version, they
+	// communicate with.
+	Index int `json:"index"`
+	// GID is the global identifier, formatted like an IPv6 address, e.g.
+	// "fe80:0000:0000:0000:b859:9f03:00d4:3a1c".
+	GID string `json:"gid"`
+	// Type is the type of the GID, e.g. "IB/RoCE v1" or "RoCE v2".
+	Type string `json:"type,omitempty"`
+	// NetDevice is the name of the network interface the GID is derived
+	// from, for RoCE ports.
+	NetDevice string `json:"net_device,omitempty"`
+}
+
+// RDMAPort describes a port of an RDMA device.
+type RDMAPort struct {
+	// Number is the port number, starting at 1.
+	Number int `json:"number"`
+	// State is the logical state of the port, e.g. "ACTIVE" or "DOWN".
+	State string `json:"state"`
+	// PhysicalState is the physical state of the port, e.g. "LinkUp" or
+	// "Polling".
+	PhysicalState string `json:"physical_state"`
+	// Rate is the link rate of the port as reported by the kernel, e.g.
+	// "100 Gb/sec (4X EDR)".
+	Rate string `json:"rate"`
+	// LinkLayer is the link layer of the port, either "InfiniBand" or
+	// "Ethernet" for RoCE.
+	LinkLayer string `json:"link_layer"`
+	// LID is the local identifier assigned to the port by the subnet
+	// manager. It is zero for Ethernet ports and ports not yet configured.
+	LID int `json:"lid"`
+	// SMLID is the local identifier of the subnet manager the port is managed
+	// by.
+	SMLID int `json:"sm_lid"`
+	// GIDs contains the valid entries of the GID table of the port.
+	GIDs []*RDMAGID `json:"gids,omitempty"`
+	// NetDevice is the name of the network interface associated with the
+	// port: the Ethernet interface of a RoCE port or the IPoIB interface of an
+	// InfiniBand port. It is empty if there is no such interface.
+	NetDevice string `json:"net_device,omitempty"`
+}
+
+// RDMADevice describes an RDMA device, e.g. an InfiniBand HCA or a RoCE
+// capable Ethernet NIC.
+type RDMADevice struct {
+	// Name is the name of the device, e.g. "mlx5_0".
+	Name string `json:"name"`
+	// NodeType is the type of the node, e.g. "CA" for a channel adapter.
+	NodeType string `json:"node_type"`
+	// NodeGUID is the globally unique identifier of the device, e.g.
+	// "b859:9f03:00d4:3a1c".
+	NodeGUID string `json:"node_guid"`
+	// SystemImageGUID is the identifier shared by the devices forming a
+	// single system, e.g. the ports of the same HCA.
+	SystemImageGUID string `json:"system_image_guid,omitempty"`
+	// FirmwareVersion is the version of the firmware of the device.
+	FirmwareVersion string `json:"firmware_version"`
+	// HCAType is the model of the device as reported by its driver, e.g.
+	// "MT4123".
+	HCAType string `json:"hca_type,omitempty"`
+	// BoardID is the identifier of the board of the device, e.g.
+	// "MT_0000000223".
+	BoardID string `json:"board_id,omitempty"`
+	// PCIAddress is a pointer to the address of the PCI device backing the
+	// RDMA device, or nil if it is not backed by a PCI device, e.g. for Soft
+	// RoCE.
+	PCIAddress *string `json:"pci_address,omitempty"`
+	// PCI is a pointer to a `pkg/pci.Device` struct describing the PCI device
+	// found at PCIAddress.
+	PCI *pci.Device `json:"pci,omitempty"`
+	// NetDevices contains the sorted names of the network interfaces backed
+	// by the same device as the RDMA device.
+	NetDevices []string `json:"net_devices,omitempty"`
+	// Ports contains pointers to `RDMAPort` structs describing the ports of
+	// the device, ordered by port number.
+	Ports []*RDMAPort `json:"ports"`
+}
+
+// String returns a short string with information about the RDMA device.
+func (d *RDMADevice) String() string {
+	fwStr := ""
+	if d.FirmwareVersion != "" {
+		fwStr = " fw=" + d.FirmwareVersion
+	}
+	return fmt.Sprintf(
+		"%s (%d ports)%s",
+		d.Name,
+		len(d.Ports),
+		fwStr,
+	)
+}
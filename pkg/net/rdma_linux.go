@@ -0,0 +1,190 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package net
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+	"github.com/go-hardware/ghw/pkg/util"
+)
+
+// rdmaDevices returns the RDMA devices listed in /sys/class/infiniband, which
+// holds the InfiniBand HCAs as well as the RoCE and iWARP capable NICs.
+func rdmaDevices(paths *ghwpath.Paths) []*RDMADevice {
+	entries, err := os.ReadDir(paths.SysClassInfiniband)
+	if err != nil {
+		return nil
+	}
+	devs := make([]*RDMADevice, 0, len(entries))
+	for _, entry := range entries {
+		devs = append(devs, rdmaDevice(paths, entry.Name()))
+	}
+	return devs
+}
+
+// rdmaDevice returns the RDMA device with the supplied name
+func rdmaDevice(paths *ghwpath.Paths, name string) *RDMADevice {
+	devPath := filepath.Join(paths.SysClassInfiniband, name)
+	dev := &RDMADevice{
+		Name:            name,
+		NodeType:        stateName(util.ReadTrimmedFile(filepath.Join(devPath, "node_type"))),
+		NodeGUID:        util.ReadTrimmedFile(filepath.Join(devPath, "node_guid")),
+		SystemImageGUID: util.ReadTrimmedFile(filepath.Join(devPath, "sys_image_guid")),
+		FirmwareVersion: util.ReadTrimmedFile(filepath.Join(devPath, "fw_ver")),
+		HCAType:         util.ReadTrimmedFile(filepath.Join(devPath, "hca_type")),
+		BoardID:         util.ReadTrimmedFile(filepath.Join(devPath, "board_id")),
+		PCIAddress:      rdmaDevicePCIAddress(paths, name),
+		Ports:           []*RDMAPort{},
+	}
+	if entries, err := os.ReadDir(filepath.Join(devPath, "device", "net")); err == nil {
+		for _, entry := range entries {
+			dev.NetDevices = append(dev.NetDevices, entry.Name())
+		}
+		sort.Strings(dev.NetDevices)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(devPath, "ports"))
+	if err != nil {
+		return dev
+	}
+	for _, entry := range entries {
+		number, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		dev.Ports = append(dev.Ports, rdmaPort(paths, devPath, number, dev.NetDevices))
+	}
+	sort.Slice(dev.Ports, func(i, j int) bool {
+		return dev.Ports[i].Number < dev.Ports[j].Number
+	})
+	return dev
+}
+
+// rdmaPort returns the port with the supplied number of the RDMA device found
+// in the supplied sysfs directory
+func rdmaPort(paths *ghwpath.Paths, devPath string, number int, netDevs []string) *RDMAPort {
+	portPath := filepath.Join(devPath, "ports", strconv.Itoa(number))
+	port := &RDMAPort{
+		Number:        number,
+		State:         stateName(util.ReadTrimmedFile(filepath.Join(portPath, "state"))),
+		PhysicalState: stateName(util.ReadTrimmedFile(filepath.Join(portPath, "phys_state"))),
+		Rate:          util.ReadTrimmedFile(filepath.Join(portPath, "rate")),
+		LinkLayer:     util.ReadTrimmedFile(filepath.Join(portPath, "link_layer")),
+		LID:           readHexFile(filepath.Join(portPath, "lid")),
+		SMLID:         readHexFile(filepath.Join(portPath, "sm_lid")),
+		GIDs:          rdmaPortGIDs(portPath),
+	}
+
+	// The GIDs of a RoCE port are derived from the addresses of its
+	// Ethernet interface, which is listed along with the GID. An InfiniBand
+	// port has no such GID, but the IPoIB interfaces of the device tell the
+	// port they use apart with their dev_port, counting from zero.
+	port.NetDevice = util.ReadTrimmedFile(filepath.Join(portPath, "gid_attrs", "ndevs", "0"))
+	if port.NetDevice == "" {
+		for _, netDev := range netDevs {
+			devPort := readIntFile(filepath.Join(paths.SysClassNet, netDev, "dev_port"))
+			if devPort == number-1 {
+				port.NetDevice = netDev
+				break
+			}
+		}
+	}
+	return port
+}
+
+// rdmaPortGIDs returns the valid entries of the GID table of the RDMA port
+// found in the supplied sysfs directory, ordered by index. The table has a
+// fixed size and its unused entries read as all zeroes.
+func rdmaPortGIDs(portPath string) []*RDMAGID {
+	entries, err := os.ReadDir(filepath.Join(portPath, "gids"))
+	if err != nil {
+		return nil
+	}
+	var gids []*RDMAGID
+	for _, entry := range entries {
+		index, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		gid := util.ReadTrimmedFile(filepath.Join(portPath, "gids", entry.Name()))
+		if strings.Trim(gid, "0:") == "" {
+			continue
+		}
+		// Reading the attributes of an unused entry fails with EINVAL
+		gids = append(gids, &RDMAGID{
+			Index:     index,
+			GID:       gid,
+			Type:      util.ReadTrimmedFile(filepath.Join(portPath, "gid_attrs", "types", entry.Name())),
+			NetDevice: util.ReadTrimmedFile(filepath.Join(portPath, "gid_attrs", "ndevs", entry.Name())),
+		})
+	}
+	sort.Slice(gids, func(i, j int) bool {
+		return gids[i].Index < gids[j].Index
+	})
+	return gids
+}
+
+// rdmaDevicePCIAddress returns the address of the PCI device backing the RDMA
+// device, or nil if the RDMA device is not backed by a PCI device
+func rdmaDevicePCIAddress(paths *ghwpath.Paths, name string) *string {
+	// The links are relative to the device directory the class entry links
+	// to, e.g. "../../devices/pci0000:00/0000:00:03.0/0000:3b:00.0/infiniband/mlx5_0"
+	dest, err := os.Readlink(filepath.Join(paths.SysClassInfiniband, name))
+	if err != nil {
+		return nil
+	}
+	devPath := filepath.Clean(filepath.Join(paths.SysClassInfiniband, dest))
+	dest, err = os.Readlink(filepath.Join(devPath, "device"))
+	if err != nil {
+		return nil
+	}
+	pciPath := filepath.Clean(filepath.Join(devPath, dest))
+	subsystem, err := os.Readlink(filepath.Join(pciPath, "subsystem"))
+	if err != nil || !strings.HasSuffix(subsystem, "/bus/pci") {
+		return nil
+	}
+	addr := filepath.Base(pciPath)
+	return &addr
+}
+
+// stateName returns the name from a state the kernel reports along with its
+// numeric value, e.g. "ACTIVE" from "4: ACTIVE"
+func stateName(state string) string {
+	if _, name, found := strings.Cut(state, ": "); found {
+		return name
+	}
+	return state
+}
+
+// readHexFile returns the value of a file holding a hexadecimal number like
+// "0x1a", or zero if the file cannot be read
+func readHexFile(path string) int {
+	val, err := strconv.ParseInt(util.ReadTrimmedFile(path), 0, 32)
+	if err != nil {
+		return 0
+	}
+	return int(val)
+}
+
+// nicFillRDMADevice sets the RDMADevice field of the NICs backed by the same
+// device as an RDMA device
+func nicFillRDMADevice(nics []*NIC, rdmaDevs []*RDMADevice) {
+	byNetDev := make(map[string]string)
+	for _, dev := range rdmaDevs {
+		for _, netDev := range dev.NetDevices {
+			byNetDev[netDev] = dev.Name
+		}
+	}
+	for _, nic := range nics {
+		nic.RDMADevice = byNetDev[nic.Name]
+	}
+}
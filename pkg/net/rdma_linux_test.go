@@ -0,0 +1,215 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+//go:build linux
+// +build linux
+
+package net
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	ghwcontext "github.com/go-hardware/ghw/pkg/context"
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+)
+
+func TestRDMADevices(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+	baseDir, _ := os.MkdirTemp("", "test")
+	defer os.RemoveAll(baseDir)
+	ctx := ghwcontext.New(ghwcontext.WithRootMountpoint(baseDir))
+	paths := ghwpath.New(ctx)
+
+	// mlx5_0 is a RoCE port of a ConnectX NIC backing ens1f0np0, mlx5_1 is
+	// an InfiniBand HCA backing the ib0 IPoIB interface and rxe0 is a Soft
+	// RoCE device on top of eth0
+	bridgeDir := filepath.Join(baseDir, "sys", "devices", "pci0000:00", "0000:00:03.0")
+	busDir := filepath.Join(baseDir, "sys", "bus", "pci")
+	_ = os.MkdirAll(busDir, 0755)
+	_ = os.MkdirAll(paths.SysClassInfiniband, 0755)
+	_ = os.MkdirAll(paths.SysClassNet, 0755)
+	devDirs := map[string]string{
+		"mlx5_0": filepath.Join(bridgeDir, "0000:3b:00.0"),
+		"mlx5_1": filepath.Join(bridgeDir, "0000:5e:00.0"),
+	}
+	netDevs := map[string]string{
+		"mlx5_0": "ens1f0np0",
+		"mlx5_1": "ib0",
+	}
+	for name, pciDir := range devDirs {
+		ibDir := filepath.Join(pciDir, "infiniband", name)
+		netDir := filepath.Join(pciDir, "net", netDevs[name])
+		_ = os.MkdirAll(filepath.Join(ibDir, "ports", "1", "gids"), 0755)
+		_ = os.MkdirAll(filepath.Join(ibDir, "ports", "1", "gid_attrs", "types"), 0755)
+		_ = os.MkdirAll(filepath.Join(ibDir, "ports", "1", "gid_attrs", "ndevs"), 0755)
+		_ = os.MkdirAll(netDir, 0755)
+		_ = os.Symlink(busDir, filepath.Join(pciDir, "subsystem"))
+		_ = os.Symlink("../..", filepath.Join(ibDir, "device"))
+		// the links of the class are relative, like in sysfs
+		ibLink, _ := filepath.Rel(paths.SysClassInfiniband, ibDir)
+		_ = os.Symlink(ibLink, filepath.Join(paths.SysClassInfiniband, name))
+		_ = os.Symlink(netDir, filepath.Join(paths.SysClassNet, netDevs[name]))
+		_ = os.WriteFile(filepath.Join(netDir, "dev_port"), []byte("0\n"), 0644)
+		_ = os.WriteFile(filepath.Join(ibDir, "node_type"), []byte("1: CA\n"), 0644)
+		_ = os.WriteFile(filepath.Join(ibDir, "fw_ver"), []byte("20.31.1014\n"), 0644)
+		_ = os.WriteFile(filepath.Join(ibDir, "hca_type"), []byte("MT4123\n"), 0644)
+	}
+	writePort := func(name string, file string, content string) {
+		_ = os.WriteFile(filepath.Join(devDirs[name], "infiniband", name, "ports", "1", file), []byte(content+"\n"), 0644)
+	}
+
+	writePort("mlx5_0", "state", "4: ACTIVE")
+	writePort("mlx5_0", "phys_state", "5: LinkUp")
+	writePort("mlx5_0", "rate", "100 Gb/sec (2X HDR)")
+	writePort("mlx5_0", "link_layer", "Ethernet")
+	writePort("mlx5_0", "lid", "0x0")
+	writePort("mlx5_0", "sm_lid", "0x0")
+	writePort("mlx5_0", "gids/0", "fe80:0000:0000:0000:ba59:9fff:fed4:3a1c")
+	writePort("mlx5_0", "gids/1", "fe80:0000:0000:0000:ba59:9fff:fed4:3a1c")
+	writePort("mlx5_0", "gids/2", "0000:0000:0000:0000:0000:0000:0000:0000")
+	writePort("mlx5_0", "gid_attrs/types/0", "IB/RoCE v1")
+	writePort("mlx5_0", "gid_attrs/types/1", "RoCE v2")
+	writePort("mlx5_0", "gid_attrs/ndevs/0", "ens1f0np0")
+	writePort("mlx5_0", "gid_attrs/ndevs/1", "ens1f0np0")
+
+	writePort("mlx5_1", "state", "4: ACTIVE")
+	writePort("mlx5_1", "phys_state", "5: LinkUp")
+	writePort("mlx5_1", "rate", "200 Gb/sec (4X HDR)")
+	writePort("mlx5_1", "link_layer", "InfiniBand")
+	writePort("mlx5_1", "lid", "0x1a")
+	writePort("mlx5_1", "sm_lid", "0x1")
+	writePort("mlx5_1", "gids/0", "fe80:0000:0000:0000:b859:9f03:00d4:3a1d")
+	writePort("mlx5_1", "gids/1", "0000:0000:0000:0000:0000:0000:0000:0000")
+	_ = os.WriteFile(filepath.Join(devDirs["mlx5_1"], "infiniband", "mlx5_1", "node_guid"), []byte("b859:9f03:00d4:3a1d\n"), 0644)
+
+	rxeDir := filepath.Join(baseDir, "sys", "devices", "virtual", "infiniband", "rxe0")
+	_ = os.MkdirAll(filepath.Join(rxeDir, "ports", "1"), 0755)
+	rxeLink, _ := filepath.Rel(paths.SysClassInfiniband, rxeDir)
+	_ = os.Symlink(rxeLink, filepath.Join(paths.SysClassInfiniband, "rxe0"))
+	_ = os.WriteFile(filepath.Join(rxeDir, "node_type"), []byte("1: CA\n"), 0644)
+	_ = os.WriteFile(filepath.Join(rxeDir, "ports", "1", "state"), []byte("1: DOWN\n"), 0644)
+	_ = os.WriteFile(filepath.Join(rxeDir, "ports", "1", "phys_state"), []byte("3: Disabled\n"), 0644)
+	_ = os.WriteFile(filepath.Join(rxeDir, "ports", "1", "link_layer"), []byte("Ethernet\n"), 0644)
+
+	mlx50Addr := "0000:3b:00.0"
+	mlx51Addr := "0000:5e:00.0"
+	expected := []*RDMADevice{
+		{
+			Name:            "mlx5_0",
+			NodeType:        "CA",
+			FirmwareVersion: "20.31.1014",
+			HCAType:         "MT4123",
+			PCIAddress:      &mlx50Addr,
+			NetDevices:      []string{"ens1f0np0"},
+			Ports: []*RDMAPort{
+				{
+					Number:        1,
+					State:         "ACTIVE",
+					PhysicalState: "LinkUp",
+					Rate:          "100 Gb/sec (2X HDR)",
+					LinkLayer:     "Ethernet",
+					GIDs: []*RDMAGID{
+						{Index: 0, GID: "fe80:0000:0000:0000:ba59:9fff:fed4:3a1c", Type: "IB/RoCE v1", NetDevice: "ens1f0np0"},
+						{Index: 1, GID: "fe80:0000:0000:0000:ba59:9fff:fed4:3a1c", Type: "RoCE v2", NetDevice: "ens1f0np0"},
+					},
+					NetDevice: "ens1f0np0",
+				},
+			},
+		},
+		{
+			Name:            "mlx5_1",
+			NodeType:        "CA",
+			NodeGUID:        "b859:9f03:00d4:3a1d",
+			FirmwareVersion: "20.31.1014",
+			HCAType:         "MT4123",
+			PCIAddress:      &mlx51Addr,
+			NetDevices:      []string{"ib0"},
+			Ports: []*RDMAPort{
+				{
+					Number:        1,
+					State:         "ACTIVE",
+					PhysicalState: "LinkUp",
+					Rate:          "200 Gb/sec (4X HDR)",
+					LinkLayer:     "InfiniBand",
+					LID:           0x1a,
+					SMLID:         0x1,
+					GIDs: []*RDMAGID{
+						{Index: 0, GID: "fe80:0000:0000:0000:b859:9f03:00d4:3a1d"},
+					},
+					NetDevice: "ib0",
+				},
+			},
+		},
+		{
+			Name:     "rxe0",
+			NodeType: "CA",
+			Ports: []*RDMAPort{
+				{
+					Number:        1,
+					State:         "DOWN",
+					PhysicalState: "Disabled",
+					LinkLayer:     "Ethernet",
+				},
+			},
+		},
+	}
+	actual := rdmaDevices(paths)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", expected, actual)
+	}
+
+	nics := []*NIC{{Name: "ens1f0np0"}, {Name: "ib0"}, {Name: "eth0"}}
+	nicFillRDMADevice(nics, actual)
+	for idx, name := range []string{"mlx5_0", "mlx5_1", ""} {
+		if nics[idx].RDMADevice != name {
+			t.Fatalf("Expected RDMA device %q for %s but got %q", name, nics[idx].Name, nics[idx].RDMADevice)
+		}
+	}
+}
+
+func TestFillPCIDevicesFailure(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+	// no PCI IDs database can be found in an empty root, so the PCI
+	// information cannot be loaded
+	ctx := ghwcontext.New(ghwcontext.WithRootMountpoint(t.TempDir()))
+	addrs := []string{"0000:3b:00.0", "0000:3b:00.1", "0000:5e:00.0"}
+	nics := []*NIC{}
+	rdmaDevs := []*RDMADevice{}
+	for x := range addrs {
+		nics = append(nics, &NIC{PCIAddress: &addrs[x]})
+		rdmaDevs = append(rdmaDevs, &RDMADevice{PCIAddress: &addrs[x]})
+	}
+
+	stderr := os.Stderr
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Expected nil err, but got %v", err)
+	}
+	os.Stderr = w
+	fillPCIDevices(ctx, nics, rdmaDevs)
+	os.Stderr = stderr
+	w.Close()
+	out, _ := io.ReadAll(r)
+
+	// the failure is reported once, not once per device
+	if n := strings.Count(string(out), "failed to get PCI information"); n != 1 {
+		t.Fatalf("Expected a single warning, but got %d:\n%s", n, out)
+	}
+	for _, nic := range nics {
+		if nic.PCI != nil {
+			t.Fatalf("Expected no PCI device, but got %+v", nic.PCI)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package net
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+	"github.com/go-hardware/ghw/pkg/pci"
+)
+
+// netDeviceSRIOV returns the SR-IOV role of the PCI function backing the NIC,
+// or nil if the NIC is not backed by a PCI device or the device is not SR-IOV
+// capable. The PCI functions taking part in SR-IOV are those found by the pci
+// package, along with the NICs they back.
+func netDeviceSRIOV(ctx context.Context, paths *ghwpath.Paths, pciAddr *string) *SRIOVInfo {
+	if pciAddr == nil {
+		return nil
+	}
+	pciSRIOV := pci.DeviceSRIOV(ctx, *pciAddr)
+	if pciSRIOV == nil {
+		return nil
+	}
+	info := &SRIOVInfo{
+		TotalVFs: pciSRIOV.TotalVFs,
+		NumVFs:   pciSRIOV.NumVFs,
+		VFIndex:  pciSRIOV.VFIndex,
+	}
+	if pciSRIOV.IsVirtualFunction() {
+		info.PhysicalFunction = sriovFunction(paths, pciSRIOV.PhysicalFunction, -1)
+	}
+	for idx, vfAddr := range pciSRIOV.VirtualFunctions {
+		info.VirtualFunctions = append(info.VirtualFunctions, sriovFunction(paths, vfAddr, idx))
+	}
+	return info
+}
+
+// sriovFunction describes the PCI function at the supplied address along with
+// the name of the NIC it backs, if any
+func sriovFunction(paths *ghwpath.Paths, pciAddr string, vfIndex int) *SRIOVFunction {
+	fn := &SRIOVFunction{
+		PCIAddress: pciAddr,
+		VFIndex:    vfIndex,
+	}
+	entries, err := os.ReadDir(filepath.Join(paths.SysBusPciDevices, pciAddr, "net"))
+	if err == nil && len(entries) > 0 {
+		fn.NICName = entries[0].Name()
+	}
+	return fn
+}
@@ -0,0 +1,84 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+//go:build linux
+// +build linux
+
+package net
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	ghwcontext "github.com/go-hardware/ghw/pkg/context"
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+)
+
+func TestNetDeviceSRIOV(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+	baseDir, _ := os.MkdirTemp("", "test")
+	defer os.RemoveAll(baseDir)
+	ctx := ghwcontext.New(ghwcontext.WithRootMountpoint(baseDir))
+	paths := ghwpath.New(ctx)
+
+	// ens1f0 is backed by a physical function with two of its eight virtual
+	// functions enabled: the first one backs ens1f0v0, the second one is
+	// bound to vfio-pci and backs no NIC
+	bridgeDir := filepath.Join(baseDir, "sys", "devices", "pci0000:00", "0000:00:03.0")
+	pfAddr := "0000:3b:00.0"
+	vfAddrs := []string{"0000:3b:02.0", "0000:3b:02.1"}
+	_ = os.MkdirAll(paths.SysBusPciDevices, 0755)
+	for _, addr := range append([]string{pfAddr}, vfAddrs...) {
+		_ = os.MkdirAll(filepath.Join(bridgeDir, addr), 0755)
+		_ = os.Symlink(filepath.Join(bridgeDir, addr), filepath.Join(paths.SysBusPciDevices, addr))
+	}
+	_ = os.MkdirAll(filepath.Join(bridgeDir, pfAddr, "net", "ens1f0"), 0755)
+	_ = os.MkdirAll(filepath.Join(bridgeDir, vfAddrs[0], "net", "ens1f0v0"), 0755)
+	_ = os.WriteFile(filepath.Join(bridgeDir, pfAddr, "sriov_totalvfs"), []byte("8\n"), 0644)
+	_ = os.WriteFile(filepath.Join(bridgeDir, pfAddr, "sriov_numvfs"), []byte("2\n"), 0644)
+	for idx, vfAddr := range vfAddrs {
+		_ = os.Symlink(filepath.Join("..", vfAddr), filepath.Join(bridgeDir, pfAddr, "virtfn"+strconv.Itoa(idx)))
+		_ = os.Symlink(filepath.Join("..", pfAddr), filepath.Join(bridgeDir, vfAddr, "physfn"))
+	}
+
+	pf := netDeviceSRIOV(ctx, paths, &pfAddr)
+	expectedPF := &SRIOVInfo{
+		TotalVFs: 8,
+		NumVFs:   2,
+		VirtualFunctions: []*SRIOVFunction{
+			{PCIAddress: vfAddrs[0], NICName: "ens1f0v0", VFIndex: 0},
+			{PCIAddress: vfAddrs[1], VFIndex: 1},
+		},
+		VFIndex: -1,
+	}
+	if !reflect.DeepEqual(expectedPF, pf) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", expectedPF, pf)
+	}
+	if !pf.IsPhysicalFunction() {
+		t.Fatalf("Expected ens1f0 to be backed by a physical function")
+	}
+
+	vf := netDeviceSRIOV(ctx, paths, &vfAddrs[0])
+	expectedVF := &SRIOVInfo{
+		PhysicalFunction: &SRIOVFunction{PCIAddress: pfAddr, NICName: "ens1f0", VFIndex: -1},
+		VFIndex:          0,
+	}
+	if !reflect.DeepEqual(expectedVF, vf) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", expectedVF, vf)
+	}
+	if !vf.IsVirtualFunction() {
+		t.Fatalf("Expected ens1f0v0 to be backed by a virtual function")
+	}
+
+	if info := netDeviceSRIOV(ctx, paths, nil); info != nil {
+		t.Fatalf("Expected nil SR-IOV info for a NIC not backed by a PCI device, but got %+v", info)
+	}
+}
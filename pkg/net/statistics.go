@@ -0,0 +1,142 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package net
+
+import (
+	"context"
+	"time"
+)
+
+// Statistics contains the traffic counters of a NIC, which count from the
+// time the NIC was registered with the kernel.
+type Statistics struct {
+	// Time is the time the counters were read at.
+	Time time.Time `json:"time"`
+	// RxBytes is the number of bytes received.
+	RxBytes uint64 `json:"rx_bytes"`
+	// RxPackets is the number of packets received.
+	RxPackets uint64 `json:"rx_packets"`
+	// RxErrors is the number of receive errors of any kind.
+	RxErrors uint64 `json:"rx_errors"`
+	// RxDropped is the number of received packets dropped before reaching
+	// the network stack, e.g. for lack of buffers.
+	RxDropped uint64 `json:"rx_dropped"`
+	// RxFIFOErrors is the number of receive FIFO overruns.
+	RxFIFOErrors uint64 `json:"rx_fifo_errors"`
+	// RxCRCErrors is the number of packets received with a bad CRC.
+	RxCRCErrors uint64 `json:"rx_crc_errors"`
+	// RxFrameErrors is the number of packets received with a framing error.
+	RxFrameErrors uint64 `json:"rx_frame_errors"`
+	// RxLengthErrors is the number of packets received with a bad length.
+	RxLengthErrors uint64 `json:"rx_length_errors"`
+	// RxMissedErrors is the number of packets missed by the device for lack
+	// of receive buffers.
+	RxMissedErrors uint64 `json:"rx_missed_errors"`
+	// RxOverErrors is the number of receive ring buffer overflows.
+	RxOverErrors uint64 `json:"rx_over_errors"`
+	// RxNoHandler is the number of packets received for which no protocol
+	// handler was found.
+	RxNoHandler uint64 `json:"rx_nohandler"`
+	// Multicast is the number of multicast packets received.
+	Multicast uint64 `json:"multicast"`
+	// TxBytes is the number of bytes transmitted.
+	TxBytes uint64 `json:"tx_bytes"`
+	// TxPackets is the number of packets transmitted.
+	TxPackets uint64 `json:"tx_packets"`
+	// TxErrors is the number of transmit errors of any kind.
+	TxErrors uint64 `json:"tx_errors"`
+	// TxDropped is the number of packets dropped on transmission.
+	TxDropped uint64 `json:"tx_dropped"`
+	// TxFIFOErrors is the number of transmit FIFO underruns.
+	TxFIFOErrors uint64 `json:"tx_fifo_errors"`
+	// TxCarrierErrors is the number of transmissions which failed because
+	// of the loss of the carrier.
+	TxCarrierErrors uint64 `json:"tx_carrier_errors"`
+	// TxAbortedErrors is the number of transmissions aborted by the device.
+	TxAbortedErrors uint64 `json:"tx_aborted_errors"`
+	// Collisions is the number of collisions on half duplex links.
+	Collisions uint64 `json:"collisions"`
+}
+
+// Rates contains the per second rates of the traffic counters of a NIC over
+// a sampling interval.
+type Rates struct {
+	// Interval is the time elapsed between the two samples.
+	Interval time.Duration `json:"interval"`
+	// RxBytesPerSecond is the number of bytes received per second.
+	RxBytesPerSecond float64 `json:"rx_bytes_per_second"`
+	// RxPacketsPerSecond is the number of packets received per second.
+	RxPacketsPerSecond float64 `json:"rx_packets_per_second"`
+	// RxErrorsPerSecond is the number of receive errors per second.
+	RxErrorsPerSecond float64 `json:"rx_errors_per_second"`
+	// RxDroppedPerSecond is the number of received packets dropped per
+	// second.
+	RxDroppedPerSecond float64 `json:"rx_dropped_per_second"`
+	// TxBytesPerSecond is the number of bytes transmitted per second.
+	TxBytesPerSecond float64 `json:"tx_bytes_per_second"`
+	// TxPacketsPerSecond is the number of packets transmitted per second.
+	TxPacketsPerSecond float64 `json:"tx_packets_per_second"`
+	// TxErrorsPerSecond is the number of transmit errors per second.
+	TxErrorsPerSecond float64 `json:"tx_errors_per_second"`
+	// TxDroppedPerSecond is the number of packets dropped on transmission
+	// per second.
+	TxDroppedPerSecond float64 `json:"tx_dropped_per_second"`
+}
+
+// Rates returns the per second rates of the counters between the supplied
+// earlier sample and this one. A counter which decreased between the samples,
+// because it wrapped around or the NIC was re-created, has a zero rate.
+// Without an earlier sample, all rates are zero.
+func (s *Statistics) Rates(prev *Statistics) *Rates {
+	if prev == nil {
+		return &Rates{}
+	}
+	interval := s.Time.Sub(prev.Time)
+	rates := &Rates{
+		Interval: interval,
+	}
+	if interval <= 0 {
+		return rates
+	}
+	rate := func(cur, prev uint64) float64 {
+		if cur < prev {
+			return 0
+		}
+		return float64(cur-prev) / interval.Seconds()
+	}
+	rates.RxBytesPerSecond = rate(s.RxBytes, prev.RxBytes)
+	rates.RxPacketsPerSecond = rate(s.RxPackets, prev.RxPackets)
+	rates.RxErrorsPerSecond = rate(s.RxErrors, prev.RxErrors)
+	rates.RxDroppedPerSecond = rate(s.RxDropped, prev.RxDropped)
+	rates.TxBytesPerSecond = rate(s.TxBytes, prev.TxBytes)
+	rates.TxPacketsPerSecond = rate(s.TxPackets, prev.TxPackets)
+	rates.TxErrorsPerSecond = rate(s.TxErrors, prev.TxErrors)
+	rates.TxDroppedPerSecond = rate(s.TxDropped, prev.TxDropped)
+	return rates
+}
+
+// SampleRates reads the traffic counters of the NIC with the supplied name
+// twice, the supplied interval apart, and returns their per second rates.
+// It returns early with an error if the context is done.
+func SampleRates(ctx context.Context, name string, interval time.Duration) (*Rates, error) {
+	prev, err := ReadStatistics(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+	}
+	cur, err := ReadStatistics(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return cur.Rates(prev), nil
+}
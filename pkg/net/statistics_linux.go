@@ -0,0 +1,60 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package net
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+	"github.com/go-hardware/ghw/pkg/util"
+)
+
+// ReadStatistics returns the current traffic counters of the NIC with the
+// supplied name. Unlike New, it only reads the counters, so it is cheap
+// enough to be called periodically.
+func ReadStatistics(ctx context.Context, name string) (*Statistics, error) {
+	return netDeviceStatistics(ghwpath.New(ctx), name)
+}
+
+// netDeviceStatistics reads the traffic counters of the NIC from
+// /sys/class/net/$DEVICE/statistics. Counters missing on older kernels are
+// left zero.
+func netDeviceStatistics(paths *ghwpath.Paths, dev string) (*Statistics, error) {
+	statsPath := filepath.Join(paths.SysClassNet, dev, "statistics")
+	if _, err := os.Stat(statsPath); err != nil {
+		return nil, err
+	}
+	counter := func(name string) uint64 {
+		return util.ReadUint64File(filepath.Join(statsPath, name))
+	}
+	return &Statistics{
+		Time:            time.Now(),
+		RxBytes:         counter("rx_bytes"),
+		RxPackets:       counter("rx_packets"),
+		RxErrors:        counter("rx_errors"),
+		RxDropped:       counter("rx_dropped"),
+		RxFIFOErrors:    counter("rx_fifo_errors"),
+		RxCRCErrors:     counter("rx_crc_errors"),
+		RxFrameErrors:   counter("rx_frame_errors"),
+		RxLengthErrors:  counter("rx_length_errors"),
+		RxMissedErrors:  counter("rx_missed_errors"),
+		RxOverErrors:    counter("rx_over_errors"),
+		RxNoHandler:     counter("rx_nohandler"),
+		Multicast:       counter("multicast"),
+		TxBytes:         counter("tx_bytes"),
+		TxPackets:       counter("tx_packets"),
+		TxErrors:        counter("tx_errors"),
+		TxDropped:       counter("tx_dropped"),
+		TxFIFOErrors:    counter("tx_fifo_errors"),
+		TxCarrierErrors: counter("tx_carrier_errors"),
+		TxAbortedErrors: counter("tx_aborted_errors"),
+		Collisions:      counter("collisions"),
+	}, nil
+}
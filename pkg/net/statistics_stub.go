@@ -0,0 +1,23 @@
+//go:build !linux
+// +build !linux
+
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package net
+
+import (
+	"context"
+	"runtime"
+
+	"github.com/pkg/errors"
+)
+
+// ReadStatistics returns the current traffic counters of the NIC with the
+// supplied name. Unlike New, it only reads the counters, so it is cheap
+// enough to be called periodically.
+func ReadStatistics(_ context.Context, _ string) (*Statistics, error) {
+	return nil, errors.New("ReadStatistics not implemented on " + runtime.GOOS)
+}
@@ -0,0 +1,55 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package net
+
+// WirelessFrequency describes a frequency, or channel, of a wireless band.
+type WirelessFrequency struct {
+	// MHz is the center frequency of the channel in MHz, e.g. 2412.
+	MHz int `json:"mhz"`
+	// Channel is the IEEE 802.11 channel number, e.g. 1.
+	Channel int `json:"channel"`
+	// Disabled is true if the channel cannot be used, e.g. because the
+	// regulatory domain does not allow it.
+	Disabled bool `json:"disabled"`
+	// NoIR is true if the device may not initiate radiation on the channel,
+	// i.e. may not send beacons or probe requests on it.
+	NoIR bool `json:"no_ir"`
+	// Radar is true if the channel requires radar detection (DFS).
+	Radar bool `json:"radar"`
+	// MaxTxPowerDBm is the maximum transmission power allowed on the
+	// channel, in dBm.
+	MaxTxPowerDBm float64 `json:"max_tx_power_dbm"`
+}
+
+// WirelessBand describes a frequency band supported by a wireless device.
+type WirelessBand struct {
+	// Name is the name of the band, e.g. "2.4GHz", "5GHz", "6GHz" or
+	// "60GHz".
+	Name string `json:"name"`
+	// Frequencies contains pointers to `WirelessFrequency` structs describing
+	// the channels of the band supported by the device.
+	Frequencies []*WirelessFrequency `json:"frequencies"`
+}
+
+// WirelessInfo describes the wireless device, or "phy", backing a wireless
+// NIC.
+type WirelessInfo struct {
+	// PhyName is the name of the wireless device, e.g. "phy0".
+	PhyName string `json:"phy_name"`
+	// PhyIndex is the index of the wireless device, or -1 if it is unknown.
+	PhyIndex int `json:"phy_index"`
+	// Bands contains pointers to `WirelessBand` structs describing the
+	// frequency bands supported by the device.
+	Bands []*WirelessBand `json:"bands,omitempty"`
+	// InterfaceModes contains the modes the interfaces of the device can
+	// operate in, e.g. "station", "ap" or "monitor".
+	InterfaceModes []string `json:"interface_modes,omitempty"`
+	// RegulatoryDomain is the ISO 3166-1 alpha-2 code of the country whose
+	// regulations the device follows, e.g. "US", or "00" for the world
+	// regulatory domain.
+	RegulatoryDomain string `json:"regulatory_domain,omitempty"`
+}
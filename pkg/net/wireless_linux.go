@@ -0,0 +1,258 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package net
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+)
+
+const (
+	// nl80211 commands and attributes, see include/uapi/linux/nl80211.h
+	nl80211CmdGetWiphy = 1
+	nl80211CmdGetReg   = 31
+
+	nl80211AttrWiphy             = 1
+	nl80211AttrWiphyBands        = 22
+	nl80211AttrSupportedIftypes  = 32
+	nl80211AttrRegAlpha2         = 33
+	nl80211AttrSplitWiphyDump    = 174
+	nl80211BandAttrFreqs         = 1
+	nl80211FrequencyAttrFreq     = 1
+	nl80211FrequencyAttrDisabled = 2
+	nl80211FrequencyAttrNoIR     = 3
+	nl80211FrequencyAttrRadar    = 5
+	nl80211FrequencyAttrMaxTxPow = 6
+)
+
+var (
+	// wirelessBandNames maps the `enum nl80211_band` values to band names
+	wirelessBandNames = map[uint16]string{
+		0: "2.4GHz",
+		1: "5GHz",
+		2: "60GHz",
+		3: "6GHz",
+		4: "900MHz",
+		5: "LC",
+	}
+
+	// wirelessModeNames contains the names of the interface modes indexed by
+	// their `enum nl80211_iftype` value
+	wirelessModeNames = []string{
+		"", // NL80211_IFTYPE_UNSPECIFIED
+		"adhoc",
+		"station",
+		"ap",
+		"ap-vlan",
+		"wds",
+		"monitor",
+		"mesh-point",
+		"p2p-client",
+		"p2p-go",
+		"p2p-device",
+		"ocb",
+		"nan",
+	}
+)
+
+// netDeviceWireless returns the wireless device backing the NIC, or nil if
+// the NIC is not a wireless NIC. Only the name and index of the device are
+// found in sysfs.
+func netDeviceWireless(paths *ghwpath.Paths, dev string) *WirelessInfo {
+	devPath := filepath.Join(paths.SysClassNet, dev)
+	info := &WirelessInfo{PhyIndex: -1}
+	if dest, err := os.Readlink(filepath.Join(devPath, "phy80211")); err == nil {
+		info.PhyName = filepath.Base(dest)
+		info.PhyIndex = readIntFile(filepath.Join(devPath, "phy80211", "index"))
+		return info
+	}
+	// cfg80211 exposes the wireless extensions compatibility directory, and
+	// the interfaces of drivers not using cfg80211 are still tagged as WLAN
+	if _, err := os.Stat(filepath.Join(devPath, "wireless")); err == nil {
+		return info
+	}
+	if ueventValue(filepath.Join(devPath, "uevent"), "DEVTYPE") == "wlan" {
+		return info
+	}
+	return nil
+}
+
+// nl80211Conn issues nl80211 requests over a generic netlink socket
+type nl80211Conn struct {
+	conn   *genlConn
+	family uint16
+}
+
+// nl80211Dial opens a generic netlink socket and resolves the ID of the
+// nl80211 family, which is only registered when cfg80211 is loaded
+func nl80211Dial() (*nl80211Conn, error) {
+	conn, err := genlDial()
+	if err != nil {
+		return nil, err
+	}
+	family, err := conn.familyID("nl80211")
+	if err != nil {
+		conn.close()
+		return nil, err
+	}
+	return &nl80211Conn{conn: conn, family: family}, nil
+}
+
+func (c *nl80211Conn) close() {
+	c.conn.close()
+}
+
+// setWirelessAttrNl80211 sets the bands, interface modes and regulatory
+// domain of the wireless device from the data reported by nl80211
+func (w *WirelessInfo) setWirelessAttrNl80211(c *nl80211Conn) {
+	if w.PhyIndex < 0 {
+		return
+	}
+	index := make([]byte, 4)
+	nativeEndian.PutUint32(index, uint32(w.PhyIndex))
+	attrs := appendNlAttr(nil, nl80211AttrWiphy, index)
+
+	// Without the split dump, the kernel truncates the description of
+	// devices supporting many channels to fit in a single message
+	wiphyAttrs := appendNlAttr(attrs, nl80211AttrSplitWiphyDump, nil)
+	if replies, err := c.conn.execute(c.family, nl80211CmdGetWiphy, syscall.NLM_F_DUMP, wiphyAttrs); err == nil {
+		w.Bands, w.InterfaceModes = parseNl80211Wiphy(replies)
+	}
+	// The device follows its own regulatory domain if it manages it itself,
+	// the global one otherwise
+	if replies, err := c.conn.execute(c.family, nl80211CmdGetReg, 0, attrs); err == nil {
+		w.RegulatoryDomain = parseNl80211Reg(replies)
+	}
+}
+
+// parseNl80211Wiphy parses the replies to a split NL80211_CMD_GET_WIPHY dump,
+// which spreads the description of the device, and even of a single band,
+// over several messages.
+func parseNl80211Wiphy(replies [][]byte) ([]*WirelessBand, []string) {
+	bands := make(map[uint16]*WirelessBand)
+	modes := make(map[uint16]bool)
+	for _, reply := range replies {
+		for _, attr := range parseNlAttrs(reply) {
+			switch attr.typ {
+			case nl80211AttrSupportedIftypes:
+				// each interface mode is a flag attribute
+				for _, mode := range parseNlAttrs(attr.data) {
+					modes[mode.typ] = true
+				}
+			case nl80211AttrWiphyBands:
+				for _, bandAttr := range parseNlAttrs(attr.data) {
+					band, ok := bands[bandAttr.typ]
+					if !ok {
+						band = &WirelessBand{
+							Name:        wirelessBandNames[bandAttr.typ],
+							Frequencies: []*WirelessFrequency{},
+						}
+						bands[bandAttr.typ] = band
+					}
+					for _, battr := range parseNlAttrs(bandAttr.data) {
+						if battr.typ != nl80211BandAttrFreqs {
+							continue
+						}
+						for _, freqAttr := range parseNlAttrs(battr.data) {
+							if freq := parseNl80211Frequency(freqAttr.data); freq != nil {
+								band.Frequencies = append(band.Frequencies, freq)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	ids := make([]int, 0, len(bands))
+	for id := range bands {
+		ids = append(ids, int(id))
+	}
+	sort.Ints(ids)
+	var res []*WirelessBand
+	for _, id := range ids {
+		res = append(res, bands[uint16(id)])
+	}
+	var resModes []string
+	for idx, name := range wirelessModeNames {
+		if name != "" && modes[uint16(idx)] {
+			resModes = append(resModes, name)
+		}
+	}
+	return res, resModes
+}
+
+// parseNl80211Frequency parses the nested attributes describing a channel,
+// returning nil if they lack the frequency
+func parseNl80211Frequency(buf []byte) *WirelessFrequency {
+	freq := &WirelessFrequency{}
+	for _, attr := range parseNlAttrs(buf) {
+		switch attr.typ {
+		case nl80211FrequencyAttrFreq:
+			if len(attr.data) >= 4 {
+				freq.MHz = int(nativeEndian.Uint32(attr.data))
+			}
+		case nl80211FrequencyAttrDisabled:
+			freq.Disabled = true
+		case nl80211FrequencyAttrNoIR:
+			freq.NoIR = true
+		case nl80211FrequencyAttrRadar:
+			freq.Radar = true
+		case nl80211FrequencyAttrMaxTxPow:
+			// in mBm, i.e. hundredths of dBm
+			if len(attr.data) >= 4 {
+				freq.MaxTxPowerDBm = float64(nativeEndian.Uint32(attr.data)) / 100
+			}
+		}
+	}
+	if freq.MHz == 0 {
+		return nil
+	}
+	freq.Channel = frequencyToChannel(freq.MHz)
+	return freq
+}
+
+// parseNl80211Reg parses the reply to NL80211_CMD_GET_REG
+func parseNl80211Reg(replies [][]byte) string {
+	for _, reply := range replies {
+		for _, attr := range parseNlAttrs(reply) {
+			if attr.typ == nl80211AttrRegAlpha2 {
+				return cString(attr.data)
+			}
+		}
+	}
+	return ""
+}
+
+// frequencyToChannel returns the IEEE 802.11 channel number of the supplied
+// center frequency, following ieee80211_frequency_to_channel() in
+// net/wireless/util.c, or 0 if it is not a known channel
+func frequencyToChannel(mhz int) int {
+	switch {
+	case mhz == 2484:
+		return 14
+	case mhz < 2412:
+		return 0
+	case mhz < 2484:
+		return (mhz - 2407) / 5
+	case mhz >= 4910 && mhz <= 4980:
+		return (mhz - 4000) / 5
+	case mhz < 5925:
+		return (mhz - 5000) / 5
+	case mhz == 5935:
+		return 2
+	case mhz <= 45000:
+		return (mhz - 5950) / 5
+	case mhz >= 58320 && mhz <= 70200:
+		return (mhz - 56160) / 2160
+	}
+	return 0
+}
@@ -0,0 +1,131 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+//go:build linux
+// +build linux
+
+package net
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	ghwcontext "github.com/go-hardware/ghw/pkg/context"
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+)
+
+func TestNetDeviceWireless(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+	baseDir, _ := os.MkdirTemp("", "test")
+	defer os.RemoveAll(baseDir)
+	ctx := ghwcontext.New(ghwcontext.WithRootMountpoint(baseDir))
+	paths := ghwpath.New(ctx)
+
+	// wlp2s0 is backed by phy0, wlan0 is backed by a driver not using
+	// cfg80211 and eth0 is not a wireless NIC
+	pciDir := filepath.Join(baseDir, "sys", "devices", "pci0000:00", "0000:00:1c.0", "0000:02:00.0")
+	_ = os.MkdirAll(filepath.Join(pciDir, "ieee80211", "phy0"), 0755)
+	_ = os.WriteFile(filepath.Join(pciDir, "ieee80211", "phy0", "index"), []byte("0\n"), 0644)
+	for _, name := range []string{"wlp2s0", "wlan0", "eth0"} {
+		_ = os.MkdirAll(filepath.Join(paths.SysClassNet, name), 0755)
+	}
+	_ = os.Symlink(filepath.Join(pciDir, "ieee80211", "phy0"), filepath.Join(paths.SysClassNet, "wlp2s0", "phy80211"))
+	_ = os.WriteFile(filepath.Join(paths.SysClassNet, "wlan0", "uevent"), []byte("DEVTYPE=wlan\nINTERFACE=wlan0\n"), 0644)
+	_ = os.WriteFile(filepath.Join(paths.SysClassNet, "eth0", "uevent"), []byte("INTERFACE=eth0\n"), 0644)
+
+	expected := map[string]*WirelessInfo{
+		"wlp2s0": {PhyName: "phy0", PhyIndex: 0},
+		"wlan0":  {PhyIndex: -1},
+		"eth0":   nil,
+	}
+	for name, exp := range expected {
+		actual := netDeviceWireless(paths, name)
+		if !reflect.DeepEqual(exp, actual) {
+			t.Fatalf("Expected for %s:\n%+v\nActual:\n%+v\n", name, exp, actual)
+		}
+	}
+}
+
+func TestParseNl80211Wiphy(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_NET"); ok {
+		t.Skip("Skipping network tests.")
+	}
+
+	u32 := func(val uint32) []byte {
+		buf := make([]byte, 4)
+		nativeEndian.PutUint32(buf, val)
+		return buf
+	}
+	freq := func(mhz uint32, flags ...uint16) []byte {
+		attrs := appendNlAttr(nil, nl80211FrequencyAttrFreq, u32(mhz))
+		for _, flag := range flags {
+			attrs = appendNlAttr(attrs, flag, nil)
+		}
+		return appendNlAttr(attrs, nl80211FrequencyAttrMaxTxPow, u32(2000))
+	}
+	band := func(id uint16, freqs ...[]byte) []byte {
+		var freqAttrs []byte
+		for idx, f := range freqs {
+			freqAttrs = appendNlAttr(freqAttrs, uint16(idx), f)
+		}
+		return appendNlAttr(nil, id, appendNlAttr(nil, nl80211BandAttrFreqs, freqAttrs))
+	}
+
+	// The split dump describes the interface modes in a message of its own
+	// and spreads the channels of the 5GHz band over two messages
+	var iftypes []byte
+	for _, iftype := range []uint16{2, 3, 6, 10} {
+		iftypes = appendNlAttr(iftypes, iftype, nil)
+	}
+	replies := [][]byte{
+		appendNlAttr(appendNlAttr(nil, nl80211AttrWiphy, u32(0)), nl80211AttrSupportedIftypes, iftypes),
+		appendNlAttr(nil, nl80211AttrWiphyBands, band(0, freq(2412), freq(2484, nl80211FrequencyAttrDisabled))),
+		appendNlAttr(nil, nl80211AttrWiphyBands, band(1, freq(5180, nl80211FrequencyAttrNoIR))),
+		appendNlAttr(nil, nl80211AttrWiphyBands, band(1, freq(5500, nl80211FrequencyAttrNoIR, nl80211FrequencyAttrRadar))),
+		appendNlAttr(nil, nl80211AttrWiphyBands, band(3, freq(5955))),
+	}
+
+	expectedBands := []*WirelessBand{
+		{
+			Name: "2.4GHz",
+			Frequencies: []*WirelessFrequency{
+				{MHz: 2412, Channel: 1, MaxTxPowerDBm: 20},
+				{MHz: 2484, Channel: 14, Disabled: true, MaxTxPowerDBm: 20},
+			},
+		},
+		{
+			Name: "5GHz",
+			Frequencies: []*WirelessFrequency{
+				{MHz: 5180, Channel: 36, NoIR: true, MaxTxPowerDBm: 20},
+				{MHz: 5500, Channel: 100, NoIR: true, Radar: true, MaxTxPowerDBm: 20},
+			},
+		},
+		{
+			Name: "6GHz",
+			Frequencies: []*WirelessFrequency{
+				{MHz: 5955, Channel: 1, MaxTxPowerDBm: 20},
+			},
+		},
+	}
+	expectedModes := []string{"station", "ap", "monitor", "p2p-device"}
+
+	bands, modes := parseNl80211Wiphy(replies)
+	if !reflect.DeepEqual(expectedBands, bands) {
+		t.Fatalf("Expected:\n%+v\nActual:\n%+v\n", expectedBands, bands)
+	}
+	if !reflect.DeepEqual(expectedModes, modes) {
+		t.Fatalf("Expected %v but got %v", expectedModes, modes)
+	}
+
+	reg := [][]byte{appendNlAttr(nil, nl80211AttrRegAlpha2, []byte("DE\x00"))}
+	if regdom := parseNl80211Reg(reg); regdom != "DE" {
+		t.Fatalf("Expected regulatory domain DE but got %q", regdom)
+	}
+}
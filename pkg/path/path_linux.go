@@ -16,6 +16,7 @@ import (
 // PathRoots holds the roots of all the filesystem subtrees
 // ghw wants to access.
 type PathRoots struct {
+	Dev  string
 	Etc  string
 	Proc string
 	Run  string
@@ -26,6 +27,7 @@ type PathRoots struct {
 // DefaultPathRoots return the canonical default value for PathRoots
 func DefaultPathRoots() PathRoots {
 	return PathRoots{
+		Dev:  "/dev",
 		Etc:  "/etc",
 		Proc: "/proc",
 		Run:  "/run",
@@ -43,6 +45,9 @@ func PathRootsFromContext(ctx context.Context) PathRoots {
 		return roots
 	}
 	overrides := *opts.PathOverrides
+	if p, ok := overrides["/dev"]; ok {
+		roots.Dev = p
+	}
 	if p, ok := overrides["/etc"]; ok {
 		roots.Etc = p
 	}
@@ -62,19 +67,34 @@ func PathRootsFromContext(ctx context.Context) PathRoots {
 }
 
 type Paths struct {
+	Dev                    string
 	VarLog                 string
 	ProcMeminfo            string
 	ProcCpuinfo            string
 	ProcMounts             string
+	ProcSwaps              string
+	ProcSelfCgroup         string
+	ProcNetVLANConfig      string
+	ProcInterrupts         string
+	ProcIRQ                string
 	SysKernelMMHugepages   string
 	SysBlock               string
+	SysDevices             string
 	SysDevicesSystemNode   string
 	SysDevicesSystemMemory string
 	SysDevicesSystemCPU    string
 	SysBusPciDevices       string
+	SysBusRBDDevices       string
 	SysClassDRM            string
 	SysClassDMI            string
+	SysClassHwmon          string
 	SysClassNet            string
+	SysClassInfiniband     string
+	SysClassISCSISession   string
+	SysClassISCSIConn      string
+	SysClassPowercap       string
+	SysClassThermal        string
+	SysFsCgroup            string
 	RunUdevData            string
 }
 
@@ -85,19 +105,34 @@ func New(ctx context.Context) *Paths {
 	root := *opts.RootMountpoint
 	roots := PathRootsFromContext(ctx)
 	return &Paths{
+		Dev:                    filepath.Join(root, roots.Dev),
 		VarLog:                 filepath.Join(root, roots.Var, "log"),
 		ProcMeminfo:            filepath.Join(root, roots.Proc, "meminfo"),
 		ProcCpuinfo:            filepath.Join(root, roots.Proc, "cpuinfo"),
 		ProcMounts:             filepath.Join(root, roots.Proc, "self", "mounts"),
+		ProcSwaps:              filepath.Join(root, roots.Proc, "swaps"),
+		ProcSelfCgroup:         filepath.Join(root, roots.Proc, "self", "cgroup"),
+		ProcNetVLANConfig:      filepath.Join(root, roots.Proc, "net", "vlan", "config"),
+		ProcInterrupts:         filepath.Join(root, roots.Proc, "interrupts"),
+		ProcIRQ:                filepath.Join(root, roots.Proc, "irq"),
 		SysKernelMMHugepages:   filepath.Join(root, roots.Sys, "kernel", "mm", "hugepages"),
 		SysBlock:               filepath.Join(root, roots.Sys, "block"),
+		SysDevices:             filepath.Join(root, roots.Sys, "devices"),
 		SysDevicesSystemNode:   filepath.Join(root, roots.Sys, "devices", "system", "node"),
 		SysDevicesSystemMemory: filepath.Join(root, roots.Sys, "devices", "system", "memory"),
 		SysDevicesSystemCPU:    filepath.Join(root, roots.Sys, "devices", "system", "cpu"),
 		SysBusPciDevices:       filepath.Join(root, roots.Sys, "bus", "pci", "devices"),
+		SysBusRBDDevices:       filepath.Join(root, roots.Sys, "bus", "rbd", "devices"),
 		SysClassDRM:            filepath.Join(root, roots.Sys, "class", "drm"),
 		SysClassDMI:            filepath.Join(root, roots.Sys, "class", "dmi"),
+		SysClassHwmon:          filepath.Join(root, roots.Sys, "class", "hwmon"),
 		SysClassNet:            filepath.Join(root, roots.Sys, "class", "net"),
+		SysClassInfiniband:     filepath.Join(root, roots.Sys, "class", "infiniband"),
+		SysClassISCSISession:   filepath.Join(root, roots.Sys, "class", "iscsi_session"),
+		SysClassISCSIConn:      filepath.Join(root, roots.Sys, "class", "iscsi_connection"),
+		SysClassPowercap:       filepath.Join(root, roots.Sys, "class", "powercap"),
+		SysClassThermal:        filepath.Join(root, roots.Sys, "class", "thermal"),
+		SysFsCgroup:            filepath.Join(root, roots.Sys, "fs", "cgroup"),
 		RunUdevData:            filepath.Join(root, roots.Run, "udev", "data"),
 	}
 }
@@ -41,6 +41,42 @@ type Device struct {
 	Node *topology.Node `json:"node,omitempty"`
 	// Driver is a string containing driver information, if any, for the device
 	Driver string `json:"driver"`
+	// SRIOV is a pointer to an `SRIOVInfo` struct describing the SR-IOV
+	// physical or virtual function role of the device. Will be nil if the
+	// device is not SR-IOV capable.
+	SRIOV *SRIOVInfo `json:"sriov,omitempty"`
+}
+
+// SRIOVInfo describes the part a PCI device takes in SR-IOV (Single Root I/O
+// Virtualization), where a physical function (PF) exposes a number of
+// lightweight virtual functions (VFs), each of them a PCI device of its own.
+type SRIOVInfo struct {
+	// TotalVFs is the maximum number of virtual functions the physical
+	// function supports. Zero for virtual functions.
+	TotalVFs int `json:"total_vfs,omitempty"`
+	// NumVFs is the number of virtual functions currently enabled on the
+	// physical function. Zero for virtual functions.
+	NumVFs int `json:"num_vfs,omitempty"`
+	// VirtualFunctions contains the PCI addresses of the enabled virtual
+	// functions of the physical function, ordered by VF index.
+	VirtualFunctions []string `json:"virtual_functions,omitempty"`
+	// PhysicalFunction is the PCI address of the parent physical function of
+	// a virtual function. Empty for physical functions.
+	PhysicalFunction string `json:"physical_function,omitempty"`
+	// VFIndex is the index of a virtual function on its physical function, or
+	// -1 for physical functions.
+	VFIndex int `json:"vf_index"`
+}
+
+// IsPhysicalFunction returns true if the device is an SR-IOV physical
+// function
+func (s *SRIOVInfo) IsPhysicalFunction() bool {
+	return s.PhysicalFunction == ""
+}
+
+// IsVirtualFunction returns true if the device is an SR-IOV virtual function
+func (s *SRIOVInfo) IsVirtualFunction() bool {
+	return s.PhysicalFunction != ""
 }
 
 type devIdent struct {
@@ -49,15 +85,16 @@ type devIdent struct {
 }
 
 type devMarshallable struct {
-	Driver    string   `json:"driver"`
-	Address   string   `json:"address"`
-	Vendor    devIdent `json:"vendor"`
-	Product   devIdent `json:"product"`
-	Revision  string   `json:"revision"`
-	Subsystem devIdent `json:"subsystem"`
-	Class     devIdent `json:"class"`
-	Subclass  devIdent `json:"subclass"`
-	Interface devIdent `json:"programming_interface"`
+	Driver    string     `json:"driver"`
+	Address   string     `json:"address"`
+	Vendor    devIdent   `json:"vendor"`
+	Product   devIdent   `json:"product"`
+	Revision  string     `json:"revision"`
+	Subsystem devIdent   `json:"subsystem"`
+	Class     devIdent   `json:"class"`
+	Subclass  devIdent   `json:"subclass"`
+	Interface devIdent   `json:"programming_interface"`
+	SRIOV     *SRIOVInfo `json:"sriov,omitempty"`
 }
 
 // NOTE(jaypipes) Device has a custom JSON marshaller because we don't want to
@@ -93,6 +130,7 @@ func (d *Device) MarshalJSON() ([]byte, error) {
 			ID:   d.ProgrammingInterface.ID,
 			Name: d.ProgrammingInterface.Name,
 		},
+		SRIOV: d.SRIOV,
 	}
 	return json.Marshal(dm)
 }
@@ -316,6 +316,7 @@ func (info *Info) GetDevice(ctx context.Context, address string) *Device {
 		device.Node = getDeviceNUMANode(ctx, pciAddr)
 	}
 	device.Driver = getDeviceDriver(ctx, pciAddr)
+	device.SRIOV = getDeviceSRIOV(ctx, pciAddr)
 	return device
 }
 
@@ -25,3 +25,9 @@ func (i *Info) load(_ context.Context) error {
 func (info *Info) GetDevice(_ context.Context, _ string) *Device {
 	return nil
 }
+
+// DeviceSRIOV returns the SR-IOV role of the PCI device at the supplied
+// address, or nil if the device is not SR-IOV capable
+func DeviceSRIOV(_ context.Context, _ string) *SRIOVInfo {
+	return nil
+}
@@ -0,0 +1,92 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+package pci
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+	pciaddr "github.com/go-hardware/ghw/pkg/pci/address"
+	"github.com/go-hardware/ghw/pkg/util"
+)
+
+// DeviceSRIOV returns the SR-IOV role of the PCI device at the supplied
+// address, or nil if the device is not SR-IOV capable. Unlike GetDevice, it
+// does not need the PCI IDs database.
+func DeviceSRIOV(ctx context.Context, address string) *SRIOVInfo {
+	pciAddr := pciaddr.FromString(address)
+	if pciAddr == nil {
+		return nil
+	}
+	return getDeviceSRIOV(ctx, pciAddr)
+}
+
+func getDeviceSRIOV(ctx context.Context, pciAddr *pciaddr.Address) *SRIOVInfo {
+	paths := ghwpath.New(ctx)
+	devPath := filepath.Join(paths.SysBusPciDevices, pciAddr.String())
+
+	// Virtual functions link back to their physical function, which in turn
+	// links to each of its virtual functions as virtfn<index>
+	if dest, err := os.Readlink(filepath.Join(devPath, "physfn")); err == nil {
+		pfAddr := filepath.Base(dest)
+		info := &SRIOVInfo{
+			PhysicalFunction: pfAddr,
+			VFIndex:          -1,
+		}
+		pfPath := filepath.Join(paths.SysBusPciDevices, pfAddr)
+		for idx, vfAddr := range getVirtualFunctions(pfPath) {
+			if vfAddr == pciAddr.String() {
+				info.VFIndex = idx
+			}
+		}
+		return info
+	}
+
+	totalVFsPath := filepath.Join(devPath, "sriov_totalvfs")
+	if _, err := os.Stat(totalVFsPath); err != nil {
+		return nil
+	}
+	return &SRIOVInfo{
+		TotalVFs:         util.SafeIntFromFile(ctx, totalVFsPath),
+		NumVFs:           util.SafeIntFromFile(ctx, filepath.Join(devPath, "sriov_numvfs")),
+		VirtualFunctions: getVirtualFunctions(devPath),
+		VFIndex:          -1,
+	}
+}
+
+// getVirtualFunctions returns the PCI addresses of the virtual functions
+// linked from the supplied physical function sysfs directory, ordered by VF
+// index
+func getVirtualFunctions(pfPath string) []string {
+	links, err := filepath.Glob(filepath.Join(pfPath, "virtfn*"))
+	if err != nil || len(links) == 0 {
+		return nil
+	}
+	vfs := make([]string, len(links))
+	for _, link := range links {
+		idx, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(link), "virtfn"))
+		if err != nil || idx < 0 || idx >= len(vfs) {
+			continue
+		}
+		dest, err := os.Readlink(link)
+		if err != nil {
+			continue
+		}
+		vfs[idx] = filepath.Base(dest)
+	}
+	res := make([]string, 0, len(vfs))
+	for _, vf := range vfs {
+		if vf != "" {
+			res = append(res, vf)
+		}
+	}
+	return res
+}
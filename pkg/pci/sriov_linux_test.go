@@ -0,0 +1,81 @@
+//
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+//
+
+//go:build linux
+// +build linux
+
+package pci
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	ghwcontext "github.com/go-hardware/ghw/pkg/context"
+	ghwpath "github.com/go-hardware/ghw/pkg/path"
+	pciaddr "github.com/go-hardware/ghw/pkg/pci/address"
+)
+
+func TestGetDeviceSRIOV(t *testing.T) {
+	if _, ok := os.LookupEnv("GHW_TESTING_SKIP_PCI"); ok {
+		t.Skip("Skipping PCI tests.")
+	}
+
+	baseDir, _ := os.MkdirTemp("", "test")
+	defer os.RemoveAll(baseDir)
+	ctx := ghwcontext.New(ghwcontext.WithRootMountpoint(baseDir))
+	paths := ghwpath.New(ctx)
+
+	// A physical function with two of its eight virtual functions enabled,
+	// next to a device which is not SR-IOV capable
+	devicesDir := filepath.Join(baseDir, "sys", "devices", "pci0000:00", "0000:00:03.0")
+	pfAddr := "0000:3b:00.0"
+	vfAddrs := []string{"0000:3b:02.0", "0000:3b:02.1"}
+	plainAddr := "0000:00:1f.2"
+	_ = os.MkdirAll(paths.SysBusPciDevices, 0755)
+	for _, addr := range append([]string{pfAddr, plainAddr}, vfAddrs...) {
+		_ = os.MkdirAll(filepath.Join(devicesDir, addr), 0755)
+		_ = os.Symlink(filepath.Join(devicesDir, addr), filepath.Join(paths.SysBusPciDevices, addr))
+	}
+	_ = os.WriteFile(filepath.Join(devicesDir, pfAddr, "sriov_totalvfs"), []byte("8\n"), 0644)
+	_ = os.WriteFile(filepath.Join(devicesDir, pfAddr, "sriov_numvfs"), []byte("2\n"), 0644)
+	for idx, vfAddr := range vfAddrs {
+		_ = os.Symlink(filepath.Join("..", vfAddr), filepath.Join(devicesDir, pfAddr, "virtfn"+strconv.Itoa(idx)))
+		_ = os.Symlink(filepath.Join("..", pfAddr), filepath.Join(devicesDir, vfAddr, "physfn"))
+	}
+
+	pf := getDeviceSRIOV(ctx, pciaddr.FromString(pfAddr))
+	expectedPF := &SRIOVInfo{
+		TotalVFs:         8,
+		NumVFs:           2,
+		VirtualFunctions: vfAddrs,
+		VFIndex:          -1,
+	}
+	if !reflect.DeepEqual(expectedPF, pf) {
+		t.Fatalf("Expected %+v but got %+v", expectedPF, pf)
+	}
+	if !pf.IsPhysicalFunction() || pf.IsVirtualFunction() {
+		t.Fatalf("Expected %s to be a physical function", pfAddr)
+	}
+
+	vf := getDeviceSRIOV(ctx, pciaddr.FromString(vfAddrs[1]))
+	expectedVF := &SRIOVInfo{
+		PhysicalFunction: pfAddr,
+		VFIndex:          1,
+	}
+	if !reflect.DeepEqual(expectedVF, vf) {
+		t.Fatalf("Expected %+v but got %+v", expectedVF, vf)
+	}
+	if !vf.IsVirtualFunction() || vf.IsPhysicalFunction() {
+		t.Fatalf("Expected %s to be a virtual function", vfAddrs[1])
+	}
+
+	if info := getDeviceSRIOV(ctx, pciaddr.FromString(plainAddr)); info != nil {
+		t.Fatalf("Expected nil SR-IOV info for %s but got %+v", plainAddr, info)
+	}
+}
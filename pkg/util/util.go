@@ -51,6 +51,27 @@ func SafeIntFromFile(ctx context.Context, path string) int {
 	return res
 }
 
+// ReadTrimmedFile returns the content of the supplied file with leading and
+// trailing whitespace removed, or an empty string if the file cannot be read,
+// e.g. because the kernel does not expose that sysfs attribute.
+func ReadTrimmedFile(path string) string {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(contents))
+}
+
+// ReadUint64File returns the unsigned integer held by the supplied file, or 0
+// if the file cannot be read or does not hold an unsigned integer.
+func ReadUint64File(path string) uint64 {
+	val, err := strconv.ParseUint(ReadTrimmedFile(path), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return val
+}
+
 // ConcatStrings concatenate strings in a larger one. This function
 // addresses a very specific ghw use case. For a more general approach,
 // just use strings.Join()
@@ -7,6 +7,8 @@
 package util_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/go-hardware/ghw/pkg/util"
@@ -109,3 +111,32 @@ func TestParseBool(t *testing.T) {
 		})
 	}
 }
+
+func TestReadFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("Expected nil err writing %s, but got %v", path, err)
+		}
+		return path
+	}
+	number := write("number", " 18446744073709551615\n")
+	text := write("text", "performance\n")
+	missing := filepath.Join(dir, "missing")
+
+	if got := util.ReadTrimmedFile(text); got != "performance" {
+		t.Errorf("expected %q got %q", "performance", got)
+	}
+	if got := util.ReadTrimmedFile(missing); got != "" {
+		t.Errorf("expected an empty string for a missing file, got %q", got)
+	}
+	if got := util.ReadUint64File(number); got != 18446744073709551615 {
+		t.Errorf("expected %d got %d", uint64(18446744073709551615), got)
+	}
+	for _, path := range []string{text, missing} {
+		if got := util.ReadUint64File(path); got != 0 {
+			t.Errorf("expected 0 for %s, got %d", path, got)
+		}
+	}
+}